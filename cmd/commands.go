@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cli"
+	configs "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/db_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/log_config"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	userservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/user_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/migrations"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+)
+
+// command is a subcommand of the binary. Every command also takes the
+// configuration flags, e.g. -config FILE or -database.host HOST, and reads
+// the same files and environment variables as the server.
+type command struct {
+	name    string
+	usage   string // what follows the name
+	summary string
+	run     func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "serve", usage: "[flags]", summary: "Run the HTTP and gRPC servers (the default)", run: serve},
+		{name: "migrate", usage: "[up | down | version | force VERSION] [flags]", summary: "Apply, revert or inspect the database migrations", run: runMigrate},
+		{name: "seed", usage: "[flags]", summary: "Add sample books to an empty catalogue", run: runSeed},
+		{name: "import", usage: "[flags] FILE", summary: "Create the books listed in a JSON, XML, CSV or YAML file (- for stdin)", run: runImport},
+		{name: "export", usage: "[flags]", summary: "Write every book as JSON, XML, CSV or YAML", run: runExport},
+		{name: "user", usage: "create -username NAME [flags] < PASSWORD", summary: "Create a user, reading the password from stdin", run: runUser},
+		{name: "apikey", usage: "issue [flags]", summary: "Issue an API key and print it once", run: runAPIKey},
+		{name: "help", usage: "[command]", summary: "Show this help", run: runHelp},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "usage: bookstore [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Every command takes the configuration flags, e.g. -config FILE. Run "bookstore <command> -h" for its own flags.`)
+}
+
+func runHelp(args []string) error {
+	if len(args) > 0 {
+		if _, ok := findCommand(args[0]); ok {
+			return dispatch(args[0], []string{"-h"})
+		}
+	}
+	printCommands(os.Stdout)
+	return nil
+}
+
+// dispatch runs the named command. -h prints its usage and succeeds.
+func dispatch(name string, args []string) error {
+	cmd, ok := findCommand(name)
+	if !ok {
+		printCommands(os.Stderr)
+		return fmt.Errorf("unknown command %q", name)
+	}
+	err := cmd.run(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+// newFlagSet returns the flag set of a command, for its own flags.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// load parses the command's own flags in fs together with the
+// configuration and sets up logging to logs.
+func load(fs *flag.FlagSet, args []string, logs io.Writer) (configs.Config, error) {
+	var own []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) { own = append(own, f) })
+
+	cfg, err := configs.LoadFlags(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage(fs.Name(), own)
+		return cfg, err
+	}
+	if err != nil {
+		return cfg, err
+	}
+	_, err = log_config.Setup(cfg.Log, logs)
+	return cfg, err
+}
+
+func printUsage(name string, own []*flag.Flag) {
+	cmd, _ := findCommand(name)
+	fmt.Printf("usage: bookstore %s %s\n\n%s\n", cmd.name, cmd.usage, cmd.summary)
+	if len(own) > 0 {
+		fmt.Println("\nflags:")
+		for _, f := range own {
+			fmt.Printf("  -%s\n    \t%s", f.Name, f.Usage)
+			if f.DefValue != "" && f.DefValue != "0s" && f.DefValue != "false" {
+				fmt.Printf(" (default %s)", f.DefValue)
+			}
+			fmt.Println()
+		}
+	}
+	fmt.Println("\nThe configuration flags, e.g. -config FILE or -database.host HOST, are accepted too.")
+}
+
+// connect opens the configured database, giving up with ctx.
+func connect(ctx context.Context, cfg configs.Config) (*sql.DB, error) {
+	db, err := db_config.ConnectDatabase(ctx, cfg.Database, sql.Open)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// subcommand splits a leading action, e.g. "up" in "migrate up -steps 2",
+// from the flags.
+func subcommand(args []string, fallback string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return fallback, args
+}
+
+func runMigrate(args []string) error {
+	action, args := subcommand(args, "up")
+	fs := newFlagSet("migrate")
+	steps := fs.Int("steps", 1, "migrations to revert with down")
+	cfg, err := load(fs, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	db, err := connect(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	defer migrator.Close()
+
+	switch action {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down(*steps)
+	case "force":
+		if fs.NArg() != 1 {
+			return errors.New("usage: bookstore migrate force VERSION")
+		}
+		var version int
+		if version, err = parseVersion(fs.Arg(0)); err != nil {
+			return err
+		}
+		err = migrator.Force(version)
+	case "version":
+	default:
+		return fmt.Errorf("unknown migrate action %q, use up, down, version or force", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		return err
+	}
+	state := "clean"
+	if dirty {
+		state = "dirty"
+	}
+	fmt.Printf("schema at version %d (%s), latest is %d\n", version, state, latest)
+	return nil
+}
+
+func parseVersion(s string) (int, error) {
+	version, err := strconv.Atoi(s)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid migration version %q", s)
+	}
+	return version, nil
+}
+
+// bookService opens the database and returns the same book services the
+// server uses, so changes go through the outbox to webhooks and feeds.
+func bookService(ctx context.Context, cfg configs.Config) (bookservices.BookServicesInterface, func() error, error) {
+	db, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bookservices.NewBookServicesPostgres(db), db.Close, nil
+}
+
+func runSeed(args []string) error {
+	fs := newFlagSet("seed")
+	force := fs.Bool("force", false, "add the sample books even if there are books already")
+	cfg, err := load(fs, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	books, closeDB, err := bookService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	created, err := cli.Seed(ctx, books, *force)
+	if errors.Is(err, cli.ErrCatalogueNotEmpty) {
+		return fmt.Errorf("%w, use -force to add the sample books anyway", err)
+	}
+	fmt.Fprintf(os.Stderr, "created %d books\n", created)
+	return err
+}
+
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	formatName := fs.String("format", "", "json, xml, csv or yaml (default from the file extension, json for stdin)")
+	cfg, err := load(fs, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: bookstore import [flags] FILE")
+	}
+	path := fs.Arg(0)
+	format, err := fileFormat(*formatName, path)
+	if err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	books, closeDB, err := bookService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	created, err := cli.Import(ctx, books, input, format)
+	fmt.Fprintf(os.Stderr, "created %d books\n", created)
+	return err
+}
+
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	formatName := fs.String("format", "", "json, xml, csv or yaml (default from the output extension, json for stdout)")
+	output := fs.String("o", "-", "output file, - for stdout")
+	cfg, err := load(fs, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	format, err := fileFormat(*formatName, *output)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	books, closeDB, err := bookService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if *output == "-" {
+		return cli.Export(ctx, books, os.Stdout, format)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := cli.Export(ctx, books, file, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// fileFormat resolves -format, or the format of path's extension; stdin and
+// stdout ("-") default to JSON.
+func fileFormat(name, path string) (negotiation.Format, error) {
+	if name != "" {
+		if f, ok := negotiation.ByName(name); ok {
+			return f, nil
+		}
+		return negotiation.Format{}, fmt.Errorf("unknown format %q, use json, xml, csv or yaml", name)
+	}
+	if path == "-" {
+		return negotiation.JSON, nil
+	}
+	if f, ok := cli.FormatOfFile(path); ok {
+		return f, nil
+	}
+	return negotiation.Format{}, fmt.Errorf("cannot tell the format of %s, use -format", path)
+}
+
+// runUser reads the password from stdin rather than a flag, so it stays out
+// of the shell history and the process list.
+func runUser(args []string) error {
+	action, args := subcommand(args, "")
+	fs := newFlagSet("user")
+	username := fs.String("username", "", "login name of the user (required)")
+	cfg, err := load(fs, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if action != "create" {
+		return errors.New("usage: bookstore user create -username NAME [flags] < PASSWORD")
+	}
+
+	request := userservices.UserRequest{Username: strings.TrimSpace(*username)}
+	if request.Username == "" {
+		return errors.New("-username is required")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read password: %w", err)
+	}
+	request.Password = strings.TrimRight(line, "\r\n")
+	if err := userservices.ValidateUser(request); err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	db, err := connect(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := userservices.NewUserServicesPostgres(db).CreateUser(ctx, request)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(user)
+}
+
+func runAPIKey(args []string) error {
+	action, args := subcommand(args, "")
+	fs := newFlagSet("apikey")
+	name := fs.String("name", "", "what the key is for (required)")
+	scopes := fs.String("scopes", apikeyservices.ScopeBooksRead, "comma-separated scopes: "+strings.Join(apikeyservices.AvailableScopes, ", "))
+	expiresIn := fs.Duration("expires-in", 0, "lifetime of the key, e.g. 720h; 0 never expires")
+	cfg, err := load(fs, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if action != "issue" {
+		return errors.New("usage: bookstore apikey issue [flags]")
+	}
+
+	request := apikeyservices.APIKeyRequest{Name: strings.TrimSpace(*name)}
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			request.Scopes = append(request.Scopes, scope)
+		}
+	}
+	if request.Name == "" {
+		return errors.New("-name is required")
+	}
+	if err := apikeyservices.ValidateScopes(request.Scopes); err != nil {
+		return err
+	}
+	if *expiresIn < 0 {
+		return errors.New("-expires-in must not be negative")
+	}
+	if *expiresIn > 0 {
+		expiresAt := time.Now().Add(*expiresIn)
+		request.ExpiresAt = &expiresAt
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	db, err := connect(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	issued, err := apikeyservices.NewAPIKeyServicesPostgres(db).IssueAPIKey(ctx, request)
+	if err != nil {
+		return err
+	}
+	// The key is stored hashed; this is the only time it is shown.
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issued)
+}
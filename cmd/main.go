@@ -1,44 +1,374 @@
 package main
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata" // DB_TIMEZONE must resolve in images without zoneinfo
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	configs "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config"
-	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/app_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cache"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/cache_config"
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/cors_config"
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/db_config"
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
 	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	idempotencyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/idempotency_services"
+	jobservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/job_services"
+	webhookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/webhook_services"
+	graphqlapi "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/graphql_api"
+	grpcapi "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/grpc_api"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/health"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/jobs"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/migrations"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/outbox"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/pgnotify"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/routes"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/server"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/stream"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/tracing"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/webhooks"
 )
 
 func main() {
+	// A .env file is optional; containers inject the environment directly
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("failed to load .env file", "error", err)
+	}
+
+	// Without a command, e.g. "bookstore -config app.yaml", run the server
+	name, args := subcommand(os.Args[1:], "serve")
+	if err := dispatch(name, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// signalContext is cancelled on SIGINT/SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+func serve(args []string) error {
 	// Initialize all configurations
-	err := godotenv.Load()
+	cfg, err := load(newFlagSet("serve"), args, os.Stdout)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		return err
 	}
-	configs.InitConfig()
 
-	// Create Gin router
-	router := gin.Default()
+	// Stop on SIGINT/SIGTERM, including while waiting for the database
+	ctx, stop := signalContext()
+	defer stop()
+
+	db, err := db_config.ConnectDatabase(ctx, cfg.Database, sql.Open)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+
+	// Each background worker replaces its no-op stop function when it
+	// starts. shutdown stops them in this order: from server.Run once the
+	// server drains, or from the deferred call when serve returns first.
+	noop := func(context.Context) error { return nil }
+	stopGRPC, stopListener, stopOutbox, stopWebhooks := noop, noop, noop, noop
+	stopIdempotencyPruning, stopJobs, shutdownTracing := noop, noop, noop
+	closeCache := func() error { return nil }
+	stopped := false
+	shutdown := func(ctx context.Context) error {
+		stopped = true
+		return errors.Join(
+			stopGRPC(ctx),
+			stopListener(ctx),
+			stopOutbox(ctx),
+			stopWebhooks(ctx),
+			stopIdempotencyPruning(ctx),
+			stopJobs(ctx),
+			closeCache(),
+			db.Close(),
+			shutdownTracing(ctx),
+		)
+	}
+	defer func() {
+		if stopped {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Error("failed to stop cleanly", "error", err)
+		}
+	}()
+
+	// Initialize tracing
+	shutdownTracing, err = tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.ServiceName)
+	if err != nil {
+		return fmt.Errorf("initialize tracing: %w", err)
+	}
+
+	// Create Gin router with structured request logging
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(problem.NotFound())
+	router.NoMethod(problem.MethodNotAllowed())
+	// Client IPs key the rate limits and idempotency scopes, so forwarded
+	// headers only count from configured proxies.
+	if err := router.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		return fmt.Errorf("set trusted proxies: %w", err)
+	}
+	router.Use(middlewares.RequestID(slog.Default()), problem.Recovery(), middlewares.Tracing(), middlewares.RequestLogger(), middlewares.Metrics())
 
 	// Apply CORS configuration
-	router.Use(cors_config.CorsConfig())
+	router.Use(cors_config.CorsConfig(cfg.CORS))
 
 	// Initialize services and controllers
-	bookService := bookservices.NewBookServicesPostgres(db_config.GetDB())
+	if err := metrics.RegisterDBStats(db, cfg.Database.Name); err != nil {
+		slog.Error("failed to register database metrics", "error", err)
+	}
+	var bookService bookservices.BookServicesInterface = bookservices.NewBookServicesPostgres(db)
+
+	// Book changes are recorded in the outbox in the same transaction; the
+	// relay forwards them to webhooks, and to the live feed when there is no
+	// notification listener to do so.
+	var bookEventPublishers []bookservices.EventPublisher
+
+	// Postgres notifications tell every replica about every change, for its
+	// cache and live feed
+	var bookChangePublishers []bookservices.EventPublisher
+
+	// Live feed: SSE and WebSocket subscribers receive book events
+	var streamController *controllers.StreamController
+	closeStream := func() {}
+	if cfg.Stream.Enabled {
+		broker := stream.NewBroker(stream.Options{History: cfg.Stream.History, SubscriberBuffer: cfg.Stream.SubscriberBuffer})
+		if cfg.Notify.Enabled {
+			bookChangePublishers = append(bookChangePublishers, broker)
+		} else {
+			bookEventPublishers = append(bookEventPublishers, broker)
+		}
+		streamController = controllers.NewStreamController(broker, cfg.Stream.HeartbeatInterval, cfg.Stream.WriteTimeout, cfg.CORS.AllowOrigins)
+		closeStream = broker.Close
+	}
+
+	// Webhooks: committed book changes queue signed deliveries for subscribers
+	var webhookController *controllers.WebhookController
+	if cfg.Webhooks.Enabled {
+		webhookService := webhookservices.NewWebhookServicesPostgres(db)
+		dispatcher := webhooks.NewDispatcher(webhookService, nil, webhooks.Options{
+			MaxAttempts:    cfg.Webhooks.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			Timeout:        cfg.Webhooks.Timeout,
+			PollInterval:   cfg.Webhooks.PollInterval,
+			BatchSize:      cfg.Webhooks.BatchSize,
+			Workers:        cfg.Webhooks.Workers,
+		})
+		bookEventPublishers = append(bookEventPublishers, dispatcher)
+		webhookController = controllers.NewWebhookController(webhookService, dispatcher.Notify)
+		stopWebhooks = dispatcher.Start()
+	}
+	relay := outbox.NewRelay(db, bookservices.OutboxPublisher(bookEventPublishers...), outbox.Options{
+		PollInterval:   cfg.Outbox.PollInterval,
+		BatchSize:      cfg.Outbox.BatchSize,
+		InitialBackoff: cfg.Outbox.InitialBackoff,
+		MaxBackoff:     cfg.Outbox.MaxBackoff,
+		Retention:      cfg.Outbox.Retention,
+	})
+	stopOutbox = relay.Start()
+
+	if cfg.Cache.Enabled {
+		var cacheStore cache.Store
+		cacheStore, closeCache = cache_config.NewStore(cfg.Cache)
+		bookCache := bookservices.NewBookServicesCache(bookService, cacheStore, cfg.Cache.TTL)
+		bookChangePublishers = append(bookChangePublishers, bookCache)
+		bookService = bookCache
+	}
+
+	if cfg.Notify.Enabled {
+		dsn, err := db_config.DataSourceName(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("build database DSN: %w", err)
+		}
+		listener := pgnotify.NewListener(dsn, bookservices.BookChangesChannel, pgnotify.Options{
+			MinReconnect: cfg.Notify.MinReconnect,
+			MaxReconnect: cfg.Notify.MaxReconnect,
+			PingInterval: cfg.Notify.PingInterval,
+		})
+		listener.Subscribe(bookservices.NewBookChangeHandler(bookChangePublishers...))
+		// A change on any replica has an outbox message ready to relay
+		listener.Subscribe(pgnotify.HandlerFunc(func(context.Context, string) error {
+			relay.Notify()
+			return nil
+		}))
+		stopListener = listener.Start()
+	}
+	bookService = bookservices.NewBookServicesMetrics(bookService)
 	bookController := controllers.NewBookController(bookService)
+	apiKeyService := apikeyservices.NewAPIKeyServicesPostgres(db)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(apiKeyService, cfg.App.APIKeyRequired, cfg.App.AdminAPIKey)
+
+	bookMiddlewares := []gin.HandlerFunc{
+		apiKeyMiddleware.Authenticate(),
+		apiKeyMiddleware.Authorize(apikeyservices.ScopeBooksRead, apikeyservices.ScopeBooksWrite),
+	}
+	graphqlMiddlewares := []gin.HandlerFunc{apiKeyMiddleware.Authenticate()}
+	streamMiddlewares := []gin.HandlerFunc{
+		apiKeyMiddleware.Authenticate(),
+		apiKeyMiddleware.Authorize(apikeyservices.ScopeBooksRead, apikeyservices.ScopeBooksWrite),
+	}
+	// One store backs the REST and gRPC limits, so a client cannot double
+	// its allowance by switching protocol.
+	var grpcRateLimit *grpcapi.RateLimit
+	if cfg.RateLimit.Enabled {
+		rateLimitStore := ratelimit.NewMemoryStore()
+		grpcRateLimit = &grpcapi.RateLimit{Store: rateLimitStore, DefaultLimit: cfg.RateLimit.Default, RouteLimits: cfg.RateLimit.Routes}
+		rateLimitMiddleware := middlewares.NewRateLimitMiddleware(rateLimitStore, cfg.RateLimit.Default, cfg.RateLimit.Routes)
+		bookMiddlewares = append(bookMiddlewares, rateLimitMiddleware.Handler())
+		graphqlMiddlewares = append(graphqlMiddlewares, rateLimitMiddleware.Handler())
+		streamMiddlewares = append(streamMiddlewares, rateLimitMiddleware.Handler())
+	}
+	bookMiddlewares = append(bookMiddlewares, middlewares.Negotiate())
+
+	// Idempotency-Key: retried POSTs replay the first response, on any
+	// replica
+	var adminMiddlewares []gin.HandlerFunc
+	if cfg.Idempotency.Enabled {
+		idempotencyService := idempotencyservices.NewIdempotencyServicesPostgres(db)
+		idempotencyMiddleware := middlewares.NewIdempotencyMiddleware(idempotencyService, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout)
+		bookMiddlewares = append(bookMiddlewares, idempotencyMiddleware.Handler())
+		adminMiddlewares = append(adminMiddlewares, idempotencyMiddleware.Handler())
+		stopIdempotencyPruning = idempotencyservices.StartPruning(idempotencyService, cfg.Idempotency.PruneInterval)
+	}
+
+	// Background jobs: workers on every replica share the jobs table
+	var jobController *controllers.JobController
+	if cfg.Jobs.Enabled {
+		jobService := jobservices.NewJobServicesPostgres(db)
+		runner := jobs.NewRunner(jobService, jobs.Options{
+			Workers:        cfg.Jobs.Workers,
+			PollInterval:   cfg.Jobs.PollInterval,
+			Timeout:        cfg.Jobs.Timeout,
+			MaxAttempts:    cfg.Jobs.MaxAttempts,
+			InitialBackoff: cfg.Jobs.InitialBackoff,
+			MaxBackoff:     cfg.Jobs.MaxBackoff,
+		})
+		runner.Register(jobs.TypePruneJobs, jobs.PruneJobs(jobService, cfg.Jobs.Retention))
+		if err := runner.Schedule("prune-jobs", cfg.Jobs.PruneSchedule, jobservices.JobRequest{Type: jobs.TypePruneJobs}); err != nil {
+			return fmt.Errorf("schedule job pruning: %w", err)
+		}
+		jobController = controllers.NewJobController(jobService, runner.Notify)
+		stopJobs = runner.Start()
+	}
+
+	// Register routes under /api/v1, plus the deprecated unprefixed aliases
+	v1 := routes.V1{
+		BookController:    bookController,
+		BookMiddlewares:   bookMiddlewares,
+		APIKeyController:  apiKeyController,
+		APIKeyMiddleware:  apiKeyMiddleware,
+		AdminMiddlewares:  adminMiddlewares,
+		WebhookController: webhookController,
+		JobController:     jobController,
+		StreamController:  streamController,
+		StreamMiddlewares: streamMiddlewares,
+	}
+	routes.MountVersion(router, routes.V1Prefix, v1)
+	if cfg.App.LegacyRoutesEnabled {
+		routes.MountLegacy(router, v1, middlewares.Deprecation{
+			Since:           routes.LegacyDeprecatedSince,
+			Sunset:          cfg.App.LegacyRoutesSunset,
+			SuccessorPrefix: routes.V1Prefix,
+		})
+	}
+
+	// GraphQL shares the book services and API keys with the REST routes
+	if cfg.GraphQL.Enabled {
+		graphqlHandler, err := graphqlapi.NewHandler(graphqlapi.Services{Books: bookService}, graphqlapi.Limits{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+			ListFactor:    cfg.GraphQL.ListFactor,
+		}, graphqlapi.APIKeyAuthorizer(apiKeyMiddleware))
+		if err != nil {
+			return fmt.Errorf("build GraphQL schema: %w", err)
+		}
+		routes.RegisterGraphQLRoutes(router, graphqlHandler, cfg.GraphQL.GraphiQL, graphqlMiddlewares...)
+	}
+
+	// Health checks
+	latestMigration, err := migrations.LatestVersion()
+	if err != nil {
+		return fmt.Errorf("read embedded migrations: %w", err)
+	}
+	healthChecks := health.New(cfg.App.HealthCheckTimeout,
+		health.DBChecker{DB: db},
+		health.MigrationChecker{DB: db, ExpectedVersion: latestMigration},
+	)
+	routes.RegisterHealthRoutes(router, controllers.NewHealthController(healthChecks))
+
+	// gRPC on its own port, sharing the services, API keys and readiness checks
+	if cfg.GRPC.Enabled {
+		grpcServer := grpcapi.NewServer(grpcapi.Options{
+			Books:      grpcapi.NewBookServer(bookService),
+			Auth:       grpcapi.APIKeyAuth{APIKeyService: apiKeyService, Required: cfg.App.APIKeyRequired, AdminKey: cfg.App.AdminAPIKey},
+			Health:     healthChecks,
+			RateLimit:  grpcRateLimit,
+			Reflection: cfg.GRPC.Reflection,
+		})
+		grpcListener, err := net.Listen("tcp", cfg.GRPC.Port)
+		if err != nil {
+			return fmt.Errorf("listen for grpc: %w", err)
+		}
+		go func() {
+			slog.Info("grpc server listening", "addr", grpcListener.Addr().String())
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				slog.Error("grpc server stopped with error", "error", err)
+				stop()
+			}
+		}()
+		stopGRPC = func(ctx context.Context) error { return grpcapi.Shutdown(ctx, grpcServer) }
+	}
 
-	// Register routes
-	routes.RegisterBookRoutes(router, bookController)
+	routes.RegisterMetricsRoutes(router)
+	routes.RegisterDocsRoutes(router)
 
 	// Serve static files
-	router.Static(app_config.PUBLIC_ROUTE, app_config.PUBLIC_ASSETS_DIR)
+	router.Static(cfg.App.PublicRoute, cfg.App.PublicAssetsDir)
 	router.StaticFile("/", "./public/index.html")
 
-	// Start server
-	router.Run(app_config.PORT)
+	// Start server and shut down gracefully on SIGINT/SIGTERM
+	serverConfig := server.Config{
+		Addr:              cfg.App.Port,
+		ReadTimeout:       cfg.App.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.App.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.App.HTTPWriteTimeout,
+		IdleTimeout:       cfg.App.HTTPIdleTimeout,
+		MaxHeaderBytes:    cfg.App.HTTPMaxHeaderBytes,
+		DrainDelay:        cfg.App.ShutdownDrainDelay,
+		ShutdownTimeout:   cfg.App.ShutdownTimeout,
+	}
+	err = server.Run(ctx, server.New(serverConfig, router), serverConfig,
+		// Ending the feeds lets their clients reconnect to another replica
+		// and the server finish in-flight requests.
+		func() {
+			healthChecks.SetDraining(true)
+			closeStream()
+		},
+		shutdown,
+	)
+	if err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
 }
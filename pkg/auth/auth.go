@@ -0,0 +1,71 @@
+// Package auth resolves API keys independently of the transport. The HTTP
+// middleware and the gRPC interceptors read the key from their own headers
+// or metadata and map the outcome to their own status codes.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+)
+
+// BootstrapAdmin is the key granted to callers presenting the admin key.
+var BootstrapAdmin = apikeyservices.APIKeyResponse{Name: "bootstrap-admin", Scopes: []string{apikeyservices.ScopeAdmin}}
+
+type Authenticator struct {
+	APIKeyService apikeyservices.APIKeyServicesInterface
+	// AdminKey is a bootstrap key that is granted the admin scope so the
+	// first real keys can be issued.
+	AdminKey string
+}
+
+// Authenticate resolves rawKey to the key it belongs to. A key that must be
+// refused fails with an error for which IsRejected is true; any other error
+// means the lookup itself failed.
+func (a Authenticator) Authenticate(ctx context.Context, rawKey string) (apikeyservices.APIKeyResponse, error) {
+	if a.AdminKey != "" && subtle.ConstantTimeCompare([]byte(rawKey), []byte(a.AdminKey)) == 1 {
+		return BootstrapAdmin, nil
+	}
+	return a.APIKeyService.AuthenticateAPIKey(ctx, rawKey)
+}
+
+// IsRejected reports whether err refuses the key as invalid, expired or
+// revoked.
+func IsRejected(err error) bool {
+	return errors.Is(err, apikeyservices.ErrAPIKeyInvalid) ||
+		errors.Is(err, apikeyservices.ErrAPIKeyExpired) ||
+		errors.Is(err, apikeyservices.ErrAPIKeyRevoked)
+}
+
+// KeyFromHeaders picks the key from an "X-API-Key" value, or else from an
+// "Authorization: Bearer|ApiKey <key>" value.
+func KeyFromHeaders(apiKey, authorization string) string {
+	if key := strings.TrimSpace(apiKey); key != "" {
+		return key
+	}
+	scheme, key, found := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !found {
+		return ""
+	}
+	if strings.EqualFold(scheme, "Bearer") || strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+// ClientKey names the caller for per-client rate limits: the API key when
+// one was authenticated, otherwise the client IP. HTTP and gRPC share it so
+// a client draws from the same buckets over both.
+func ClientKey(apiKey *apikeyservices.APIKeyResponse, clientIP string) string {
+	if apiKey == nil {
+		return "ip:" + clientIP
+	}
+	if apiKey.ID != 0 {
+		return fmt.Sprintf("apikey:%d", apiKey.ID)
+	}
+	return "apikey:" + apiKey.Name
+}
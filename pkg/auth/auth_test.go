@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) IssueAPIKey(ctx context.Context, apiKey apikeyservices.APIKeyRequest) (apikeyservices.IssuedAPIKeyResponse, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(apikeyservices.IssuedAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAllAPIKeys(ctx context.Context) ([]apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error {
+	args := m.Called(ctx, apiKeyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func TestAuthenticate(t *testing.T) {
+	mockService := new(MockAPIKeyService)
+	mockService.On("AuthenticateAPIKey", mock.Anything, "bk_reader").Return(apikeyservices.APIKeyResponse{ID: 1, Scopes: []string{apikeyservices.ScopeBooksRead}}, nil)
+	mockService.On("AuthenticateAPIKey", mock.Anything, "bk_revoked").Return(apikeyservices.APIKeyResponse{}, apikeyservices.ErrAPIKeyRevoked)
+	mockService.On("AuthenticateAPIKey", mock.Anything, "bk_any").Return(apikeyservices.APIKeyResponse{}, errors.New("connection refused"))
+	authenticator := Authenticator{APIKeyService: mockService, AdminKey: "admin-secret"}
+
+	apiKey, err := authenticator.Authenticate(context.Background(), "admin-secret")
+	assert.NoError(t, err)
+	assert.True(t, apiKey.HasScope(apikeyservices.ScopeAdmin))
+
+	apiKey, err = authenticator.Authenticate(context.Background(), "bk_reader")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), apiKey.ID)
+
+	_, err = authenticator.Authenticate(context.Background(), "bk_revoked")
+	assert.True(t, IsRejected(err))
+
+	_, err = authenticator.Authenticate(context.Background(), "bk_any")
+	assert.Error(t, err)
+	assert.False(t, IsRejected(err))
+}
+
+func TestKeyFromHeaders(t *testing.T) {
+	tests := []struct {
+		apiKey        string
+		authorization string
+		want          string
+	}{
+		{apiKey: " bk_header ", authorization: "Bearer bk_bearer", want: "bk_header"},
+		{authorization: "Bearer bk_bearer", want: "bk_bearer"},
+		{authorization: "apikey bk_scheme", want: "bk_scheme"},
+		{authorization: "Basic dXNlcjpwYXNz", want: ""},
+		{authorization: "bk_bare", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, KeyFromHeaders(tt.apiKey, tt.authorization), "%q %q", tt.apiKey, tt.authorization)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	assert.Equal(t, "apikey:7", ClientKey(&apikeyservices.APIKeyResponse{ID: 7, Name: "scanner"}, "192.0.2.1"))
+	assert.Equal(t, "apikey:bootstrap-admin", ClientKey(&BootstrapAdmin, "192.0.2.1"))
+	assert.Equal(t, "ip:192.0.2.1", ClientKey(nil, "192.0.2.1"))
+}
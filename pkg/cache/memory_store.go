@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process LRU cache. Entries expire after their TTL and
+// the least recently used entry is evicted once MaxEntries is reached.
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List // front is most recently used
+	MaxEntries int
+	Now        func() time.Time
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		MaxEntries: maxEntries,
+		Now:        time.Now,
+	}
+}
+
+func (ms *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	element, ok := ms.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := element.Value.(*entry)
+	if !ms.Now().Before(e.expiresAt) {
+		ms.remove(element)
+		return nil, false, nil
+	}
+	ms.order.MoveToFront(element)
+	return e.value, true, nil
+}
+
+func (ms *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	expiresAt := ms.Now().Add(ttl)
+	if element, ok := ms.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value = value
+		e.expiresAt = expiresAt
+		ms.order.MoveToFront(element)
+		return nil
+	}
+
+	ms.entries[key] = ms.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+	for ms.MaxEntries > 0 && ms.order.Len() > ms.MaxEntries {
+		ms.remove(ms.order.Back())
+	}
+	return nil
+}
+
+func (ms *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := ms.entries[key]; ok {
+			ms.remove(element)
+		}
+	}
+	return nil
+}
+
+func (ms *MemoryStore) DeletePrefix(_ context.Context, prefix string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for key, element := range ms.entries {
+		if strings.HasPrefix(key, prefix) {
+			ms.remove(element)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.order.Len()
+}
+
+func (ms *MemoryStore) remove(element *list.Element) {
+	ms.order.Remove(element)
+	delete(ms.entries, element.Value.(*entry).key)
+}
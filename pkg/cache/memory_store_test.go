@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(10)
+	store.Now = func() time.Time { return now }
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+
+	value, found, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+
+	now = now.Add(time.Minute)
+	_, found, err = store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryStoreLRU(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	store.Set(ctx, "a", []byte("1"), time.Minute)
+	store.Set(ctx, "b", []byte("2"), time.Minute)
+	// Touch "a" so "b" becomes the least recently used entry.
+	store.Get(ctx, "a")
+	store.Set(ctx, "c", []byte("3"), time.Minute)
+
+	_, found, _ := store.Get(ctx, "b")
+	assert.False(t, found)
+	_, found, _ = store.Get(ctx, "a")
+	assert.True(t, found)
+	_, found, _ = store.Get(ctx, "c")
+	assert.True(t, found)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestMemoryStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+
+	store.Set(ctx, "a", []byte("1"), time.Minute)
+	store.Set(ctx, "b", []byte("2"), time.Minute)
+	assert.NoError(t, store.Delete(ctx, "a", "b", "missing"))
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryStoreDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+
+	store.Set(ctx, "books:all", []byte("1"), time.Minute)
+	store.Set(ctx, "books:id:1", []byte("2"), time.Minute)
+	store.Set(ctx, "authors:all", []byte("3"), time.Minute)
+	assert.NoError(t, store.DeletePrefix(ctx, "books:"))
+	assert.Equal(t, 1, store.Len())
+	_, found, _ := store.Get(ctx, "authors:all")
+	assert.True(t, found)
+}
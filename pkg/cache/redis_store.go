@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const scanBatchSize = 100
+
+// globEscaper quotes the characters that are special in a SCAN MATCH pattern.
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// RedisStore keeps entries in Redis, or any server speaking its protocol,
+// so every replica shares one cache.
+type RedisStore struct {
+	Client redis.UniversalClient
+	// Prefix namespaces the keys, e.g. "bookstore:".
+	Prefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{
+		Client: client,
+		Prefix: prefix,
+	}
+}
+
+func (rs *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := rs.Client.Get(ctx, rs.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (rs *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return rs.Client.Set(ctx, rs.Prefix+key, value, ttl).Err()
+}
+
+func (rs *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = rs.Prefix + key
+	}
+	return rs.Client.Del(ctx, prefixed...).Err()
+}
+
+// DeletePrefix scans for the matching keys rather than using KEYS, so a
+// large keyspace does not block the server, and deletes them once the scan
+// is complete.
+func (rs *RedisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	match := globEscaper.Replace(rs.Prefix+prefix) + "*"
+	var keys []string
+	iter := rs.Client.Scan(ctx, 0, match, scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), scanBatchSize)]
+		if err := rs.Client.Del(ctx, batch...).Err(); err != nil {
+			return err
+		}
+		keys = keys[len(batch):]
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	store := NewRedisStore(client, "bookstore:")
+
+	_, found, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.True(t, server.Exists("bookstore:a"))
+
+	value, found, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+
+	server.FastForward(time.Minute)
+	_, found, err = store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	store.Set(ctx, "b", []byte("2"), time.Minute)
+	assert.NoError(t, store.Delete(ctx, "b"))
+	assert.False(t, server.Exists("bookstore:b"))
+
+	for i := 0; i < 250; i++ {
+		store.Set(ctx, "books:id:"+strconv.Itoa(i), []byte("1"), time.Minute)
+	}
+	store.Set(ctx, "books*", []byte("1"), time.Minute)
+	server.Set("other:books:id:1", "1")
+	assert.NoError(t, store.DeletePrefix(ctx, "books:"))
+	assert.Equal(t, []string{"bookstore:books*", "other:books:id:1"}, server.Keys())
+}
+
+func TestRedisStoreUnavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+	store := NewRedisStore(client, "")
+	server.Close()
+
+	_, _, err := store.Get(context.Background(), "a")
+	assert.Error(t, err)
+}
@@ -0,0 +1,18 @@
+// Package cache provides byte-oriented key/value stores with per-entry TTLs
+// for the service-layer caches.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a cache backend. A missing or expired key is reported by
+// found == false, not by an error.
+type Store interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix deletes every key starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
@@ -0,0 +1,119 @@
+// Package cli holds the administrative commands of the bookstore binary
+// that work on the catalogue directly, without a running server.
+package cli
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+)
+
+// ErrCatalogueNotEmpty is returned by Seed when there are books already.
+var ErrCatalogueNotEmpty = errors.New("the catalogue already has books")
+
+// SampleBooks are the books created by Seed.
+var SampleBooks = []bookservices.BookRequest{
+	{Name: "The Go Programming Language", Author: "Alan A. A. Donovan and Brian W. Kernighan", Publication: "Addison-Wesley"},
+	{Name: "Designing Data-Intensive Applications", Author: "Martin Kleppmann", Publication: "O'Reilly Media"},
+	{Name: "The Pragmatic Programmer", Author: "David Thomas and Andrew Hunt", Publication: "Addison-Wesley"},
+	{Name: "Clean Architecture", Author: "Robert C. Martin", Publication: "Prentice Hall"},
+	{Name: "Database Internals", Author: "Alex Petrov", Publication: "O'Reilly Media"},
+}
+
+// Seed creates SampleBooks in an empty catalogue, or next to the existing
+// books when force is set. It returns how many books were created.
+func Seed(ctx context.Context, books bookservices.BookServicesInterface, force bool) (int, error) {
+	if !force {
+		existing, err := books.GetAllBooks(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if len(existing) > 0 {
+			return 0, ErrCatalogueNotEmpty
+		}
+	}
+	return create(ctx, books, SampleBooks)
+}
+
+// importedBook accepts the output of Export as well as bare book requests.
+// The ID and timestamps are ignored; the database assigns new ones.
+type importedBook struct {
+	ID          uint      `json:"id" xml:"id" yaml:"id"`
+	Name        string    `json:"name" xml:"name" yaml:"name" mod:"trim" validate:"required,max=255"`
+	Author      string    `json:"author" xml:"author" yaml:"author" mod:"trim" validate:"required,max=255"`
+	Publication string    `json:"publication" xml:"publication" yaml:"publication" mod:"trim" validate:"required,max=255"`
+	CreatedAt   time.Time `json:"created_at" xml:"created_at" yaml:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+}
+
+// Import reads a list of books in format f and creates them in order. The
+// whole list is validated first, so an invalid book creates nothing. It
+// returns how many books were created, also when creating one fails.
+func Import(ctx context.Context, books bookservices.BookServicesInterface, r io.Reader, f negotiation.Format) (int, error) {
+	var imported []importedBook
+	if f.Name == negotiation.XML.Name {
+		// A list is a <books> element of <book> items, as Export writes it.
+		var list struct {
+			Books []importedBook `xml:"book"`
+		}
+		if err := xml.NewDecoder(r).Decode(&list); err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, validation.ErrEmptyBody
+			}
+			return 0, err
+		}
+		imported = list.Books
+		if err := validation.Struct(&imported); err != nil {
+			return 0, err
+		}
+	} else if err := negotiation.Decode(r, f, &imported); err != nil {
+		return 0, err
+	}
+
+	requests := make([]bookservices.BookRequest, len(imported))
+	for i, book := range imported {
+		requests[i] = bookservices.BookRequest{Name: book.Name, Author: book.Author, Publication: book.Publication}
+	}
+	return create(ctx, books, requests)
+}
+
+func create(ctx context.Context, books bookservices.BookServicesInterface, requests []bookservices.BookRequest) (int, error) {
+	for i, request := range requests {
+		if _, err := books.CreateBook(ctx, request); err != nil {
+			return i, fmt.Errorf("create book %d (%q): %w", i+1, request.Name, err)
+		}
+	}
+	return len(requests), nil
+}
+
+// Export writes every book in format f, in the same shape as the list
+// endpoint returns them.
+func Export(ctx context.Context, books bookservices.BookServicesInterface, w io.Writer, f negotiation.Format) error {
+	all, err := books.GetAllBooks(ctx)
+	if err != nil {
+		return err
+	}
+	if all == nil {
+		all = []bookservices.BookResponse{}
+	}
+	return negotiation.Encode(w, f, "book", all)
+}
+
+// FormatOfFile picks the format from a file extension such as ".csv" or
+// ".yml".
+func FormatOfFile(path string) (negotiation.Format, bool) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if ext == "yml" {
+		ext = "yaml"
+	}
+	return negotiation.ByName(ext)
+}
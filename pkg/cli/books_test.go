@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+)
+
+type MockBookService struct {
+	mock.Mock
+}
+
+func (m *MockBookService) CreateBook(ctx context.Context, book bookservices.BookRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, book)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetAllBooks(ctx context.Context) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBookByID(ctx context.Context, bookID string) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookIDs)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) UpdateBookByID(ctx context.Context, bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID, book)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) DeleteBookByID(ctx context.Context, bookID string) error {
+	args := m.Called(ctx, bookID)
+	return args.Error(0)
+}
+
+func TestSeed(t *testing.T) {
+	mockService := new(MockBookService)
+	mockService.On("GetAllBooks", mock.Anything).Return([]bookservices.BookResponse{{ID: 1}}, nil)
+
+	created, err := Seed(context.Background(), mockService, false)
+	assert.ErrorIs(t, err, ErrCatalogueNotEmpty)
+	assert.Zero(t, created)
+
+	mockService.On("CreateBook", mock.Anything, mock.Anything).Return(bookservices.BookResponse{}, nil)
+	created, err = Seed(context.Background(), mockService, true)
+	assert.NoError(t, err)
+	assert.Equal(t, len(SampleBooks), created)
+	mockService.AssertNumberOfCalls(t, "CreateBook", len(SampleBooks))
+}
+
+func TestImport(t *testing.T) {
+	books := []bookservices.BookRequest{
+		{Name: "One", Author: "Ann", Publication: "Pub"},
+		{Name: "Two", Author: "Bob", Publication: "Pub"},
+	}
+	tests := []struct {
+		name   string
+		format negotiation.Format
+		body   string
+	}{
+		{name: "json", format: negotiation.JSON, body: `[{"name":" One ","author":"Ann","publication":"Pub"},{"name":"Two","author":"Bob","publication":"Pub"}]`},
+		{name: "csv", format: negotiation.CSV, body: "id,name,author,publication,created_at,updated_at\n7,One,Ann,Pub,2026-10-19T08:30:00Z,2026-10-19T08:30:00Z\n8,Two,Bob,Pub,,\n"},
+		{name: "yaml", format: negotiation.YAML, body: "- name: One\n  author: Ann\n  publication: Pub\n- name: Two\n  author: Bob\n  publication: Pub\n"},
+		{name: "xml", format: negotiation.XML, body: `<books><book><id>7</id><name>One</name><author>Ann</author><publication>Pub</publication></book><book><name>Two</name><author>Bob</author><publication>Pub</publication></book></books>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBookService)
+			for _, book := range books {
+				mockService.On("CreateBook", mock.Anything, book).Return(bookservices.BookResponse{}, nil).Once()
+			}
+
+			created, err := Import(context.Background(), mockService, strings.NewReader(tt.body), tt.format)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, created)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	// An invalid book anywhere in the list creates nothing.
+	mockService := new(MockBookService)
+	_, err := Import(context.Background(), mockService, strings.NewReader(`[{"name":"One","author":"Ann","publication":"Pub"},{"name":"Two"}]`), negotiation.JSON)
+	var validationErr *validation.Error
+	assert.ErrorAs(t, err, &validationErr)
+	mockService.AssertNotCalled(t, "CreateBook", mock.Anything, mock.Anything)
+
+	// A failed insert reports how many books were created before it.
+	mockService.On("CreateBook", mock.Anything, mock.MatchedBy(func(book bookservices.BookRequest) bool { return book.Name == "One" })).
+		Return(bookservices.BookResponse{}, nil)
+	mockService.On("CreateBook", mock.Anything, mock.Anything).Return(bookservices.BookResponse{}, errors.New("insert error"))
+	created, err := Import(context.Background(), mockService, strings.NewReader("<books><book><name>One</name><author>Ann</author><publication>Pub</publication></book><book><name>Two</name><author>Bob</author><publication>Pub</publication></book></books>"), negotiation.XML)
+	assert.EqualError(t, err, `create book 2 ("Two"): insert error`)
+	assert.Equal(t, 1, created)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	exported := []bookservices.BookResponse{
+		{ID: 1, Name: "One", Author: "Ann", Publication: "Pub", CreatedAt: now, UpdatedAt: now},
+		{ID: 2, Name: "Two, Vol. 2", Author: "Bob", Publication: "Pub", CreatedAt: now, UpdatedAt: now},
+	}
+
+	for _, format := range negotiation.Formats {
+		t.Run(format.Name, func(t *testing.T) {
+			source := new(MockBookService)
+			source.On("GetAllBooks", mock.Anything).Return(exported, nil)
+			var buf bytes.Buffer
+			assert.NoError(t, Export(context.Background(), source, &buf, format))
+
+			target := new(MockBookService)
+			for _, book := range exported {
+				request := bookservices.BookRequest{Name: book.Name, Author: book.Author, Publication: book.Publication}
+				target.On("CreateBook", mock.Anything, request).Return(bookservices.BookResponse{}, nil).Once()
+			}
+			created, err := Import(context.Background(), target, &buf, format)
+			assert.NoError(t, err)
+			assert.Equal(t, len(exported), created)
+			target.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFormatOfFile(t *testing.T) {
+	for path, want := range map[string]string{"books.csv": "csv", "books.YML": "yaml", "/tmp/books.json": "json", "books.xml": "xml"} {
+		f, ok := FormatOfFile(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, f.Name, path)
+	}
+	_, ok := FormatOfFile("books.txt")
+	assert.False(t, ok)
+}
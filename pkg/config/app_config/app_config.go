@@ -1,20 +1,119 @@
 package app_config
 
 import (
-	"log"
-	"os"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var PORT = ":8000" //string
+type Config struct {
+	Port string `config:"port" env:"APP_PORT"`
 
-// for public asset access
-var PUBLIC_ROUTE = "/public"
-var PUBLIC_ASSETS_DIR = "./public"
+	// for public asset access
+	PublicRoute     string `config:"public_route" env:"PUBLIC_ROUTE"`
+	PublicAssetsDir string `config:"public_assets_dir" env:"PUBLIC_ASSETS_DIR"`
 
-func InitAppConfig() {
-	env_APP_PORT := os.Getenv("APP_PORT")
-	if env_APP_PORT != "" {
-		log.Println("APP_PORT => ", env_APP_PORT)
-		PORT = env_APP_PORT
+	// API key authentication
+	APIKeyRequired bool   `config:"api_key_required" env:"API_KEY_REQUIRED"`
+	AdminAPIKey    string `config:"admin_api_key" env:"ADMIN_API_KEY"`
+
+	// Unprefixed routes kept as deprecated aliases of /api/v1
+	LegacyRoutesEnabled bool      `config:"legacy_routes_enabled" env:"LEGACY_ROUTES_ENABLED"`
+	LegacyRoutesSunset  time.Time `config:"legacy_routes_sunset" env:"LEGACY_ROUTES_SUNSET"` // RFC 3339
+
+	// Proxies, as IPs or CIDRs, whose X-Forwarded-For header is believed
+	// when working out the client IP. Empty trusts none.
+	TrustedProxies []string `config:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+	// Readiness probe timeout per dependency check
+	HealthCheckTimeout time.Duration `config:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+
+	// HTTP server limits and graceful shutdown
+	HTTPReadTimeout       time.Duration `config:"http_read_timeout" env:"HTTP_READ_TIMEOUT"`
+	HTTPReadHeaderTimeout time.Duration `config:"http_read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	HTTPWriteTimeout      time.Duration `config:"http_write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout       time.Duration `config:"http_idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	HTTPMaxHeaderBytes    int           `config:"http_max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	ShutdownDrainDelay    time.Duration `config:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+	ShutdownTimeout       time.Duration `config:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+func Default() Config {
+	return Config{
+		Port:                  ":8000",
+		PublicRoute:           "/public",
+		PublicAssetsDir:       "./public",
+		LegacyRoutesEnabled:   true,
+		LegacyRoutesSunset:    time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		HealthCheckTimeout:    2 * time.Second,
+		HTTPReadTimeout:       15 * time.Second,
+		HTTPReadHeaderTimeout: 5 * time.Second,
+		HTTPWriteTimeout:      30 * time.Second,
+		HTTPIdleTimeout:       60 * time.Second,
+		HTTPMaxHeaderBytes:    1 << 20,
+		ShutdownDrainDelay:    5 * time.Second,
+		ShutdownTimeout:       20 * time.Second,
+	}
+}
+
+// Validate returns one error per invalid field, keyed by its config name.
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if err := validateAddr(c.Port); err != nil {
+		errs["port"] = err
+	}
+	if !strings.HasPrefix(c.PublicRoute, "/") {
+		errs["public_route"] = errors.New("must start with /")
+	}
+	if c.PublicAssetsDir == "" {
+		errs["public_assets_dir"] = errors.New("must not be empty")
+	}
+	for _, proxy := range c.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			errs["trusted_proxies"] = fmt.Errorf("%q is not an IP address or CIDR", proxy)
+			break
+		}
+	}
+	positive := map[string]time.Duration{
+		"health_check_timeout":     c.HealthCheckTimeout,
+		"http_read_timeout":        c.HTTPReadTimeout,
+		"http_read_header_timeout": c.HTTPReadHeaderTimeout,
+		"http_write_timeout":       c.HTTPWriteTimeout,
+		"http_idle_timeout":        c.HTTPIdleTimeout,
+		"shutdown_timeout":         c.ShutdownTimeout,
+	}
+	for name, d := range positive {
+		if d <= 0 {
+			errs[name] = errors.New("must be greater than zero")
+		}
+	}
+	if c.ShutdownDrainDelay < 0 {
+		errs["shutdown_drain_delay"] = errors.New("must not be negative")
+	}
+	if c.HTTPMaxHeaderBytes <= 0 {
+		errs["http_max_header_bytes"] = errors.New("must be greater than zero")
+	}
+	return errs
+}
+
+func isIPOrCIDR(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
+
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.New("must be in the form [host]:port")
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 0 || n > 65535 {
+		return errors.New("port must be a number between 0 and 65535")
 	}
+	return nil
 }
@@ -1,55 +1,69 @@
 package app_config
 
 import (
-	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestInitAppConfig(t *testing.T) {
+func TestValidate(t *testing.T) {
 	tests := []struct {
-		name     string
-		envValue string
-		wantPort string
+		name       string
+		modify     func(c *Config)
+		wantFields []string
 	}{
 		{
-			name:     "Environment variable not set",
-			envValue: "",
-			wantPort: ":8000",
+			name:   "Defaults are valid",
+			modify: func(c *Config) {},
 		},
 		{
-			name:     "Environment variable set",
-			envValue: ":9000",
-			wantPort: ":9000",
+			name: "Port with host",
+			modify: func(c *Config) {
+				c.Port = "0.0.0.0:9000"
+			},
+		},
+		{
+			name: "Invalid port and timeouts",
+			modify: func(c *Config) {
+				c.Port = "9000"
+				c.HTTPReadTimeout = 0
+				c.ShutdownDrainDelay = -time.Second
+			},
+			wantFields: []string{"port", "http_read_timeout", "shutdown_drain_delay"},
+		},
+		{
+			name: "Trusted proxies",
+			modify: func(c *Config) {
+				c.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.1", "::1"}
+			},
+		},
+		{
+			name: "Invalid trusted proxy",
+			modify: func(c *Config) {
+				c.TrustedProxies = []string{"10.0.0.0/8", "proxy.internal"}
+			},
+			wantFields: []string{"trusted_proxies"},
+		},
+		{
+			name: "Empty public assets dir",
+			modify: func(c *Config) {
+				c.PublicAssetsDir = ""
+			},
+			wantFields: []string{"public_assets_dir"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Backup original PORT value
-			originalPort := PORT
-
-			// Restore original PORT value after test
-			defer func() {
-				PORT = originalPort
-			}()
+			cfg := Default()
+			tt.modify(&cfg)
 
-			// Set or unset the environment variable
-			if tt.envValue != "" {
-				os.Setenv("APP_PORT", tt.envValue)
-			} else {
-				os.Unsetenv("APP_PORT")
+			errs := cfg.Validate()
+			assert.Len(t, errs, len(tt.wantFields))
+			for _, field := range tt.wantFields {
+				assert.Contains(t, errs, field)
 			}
-
-			// Initialize app config
-			InitAppConfig()
-
-			// Check the result
-			assert.Equal(t, tt.wantPort, PORT)
-
-			// Clean up environment variable
-			os.Unsetenv("APP_PORT")
 		})
 	}
 }
@@ -0,0 +1,72 @@
+package cache_config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cache"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+type Config struct {
+	Enabled    bool          `config:"enabled" env:"CACHE_ENABLED"`
+	Backend    string        `config:"backend" env:"CACHE_BACKEND"` // memory or redis
+	TTL        time.Duration `config:"ttl" env:"CACHE_TTL"`
+	MaxEntries int           `config:"max_entries" env:"CACHE_MAX_ENTRIES"` // memory backend only
+
+	// Redis backend
+	RedisAddr      string `config:"redis_addr" env:"CACHE_REDIS_ADDR"`
+	RedisPassword  string `config:"redis_password" env:"CACHE_REDIS_PASSWORD"`
+	RedisDB        int    `config:"redis_db" env:"CACHE_REDIS_DB"`
+	RedisKeyPrefix string `config:"redis_key_prefix" env:"CACHE_REDIS_KEY_PREFIX"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:        true,
+		Backend:        BackendMemory,
+		TTL:            30 * time.Second,
+		MaxEntries:     10000,
+		RedisAddr:      "127.0.0.1:6379",
+		RedisKeyPrefix: "bookstore:",
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.Backend != BackendMemory && c.Backend != BackendRedis {
+		errs["backend"] = errors.New("must be memory or redis")
+	}
+	if c.TTL <= 0 {
+		errs["ttl"] = errors.New("must be greater than zero")
+	}
+	if c.MaxEntries < 0 {
+		errs["max_entries"] = errors.New("must not be negative (0 means unbounded)")
+	}
+	if c.Backend == BackendRedis && c.RedisAddr == "" {
+		errs["redis_addr"] = errors.New("must not be empty when backend is redis")
+	}
+	if c.RedisDB < 0 {
+		errs["redis_db"] = errors.New("must not be negative")
+	}
+	return errs
+}
+
+// NewStore builds the configured backend. The returned close function
+// releases its connections.
+func NewStore(cfg Config) (cache.Store, func() error) {
+	if cfg.Backend == BackendRedis {
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		return cache.NewRedisStore(client, cfg.RedisKeyPrefix), client.Close
+	}
+	return cache.NewMemoryStore(cfg.MaxEntries), func() error { return nil }
+}
@@ -0,0 +1,33 @@
+package cache_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cache"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	errs := Config{Backend: BackendRedis, MaxEntries: -1}.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "ttl")
+	assert.Contains(t, errs, "max_entries")
+	assert.Contains(t, errs, "redis_addr")
+
+	errs = Config{Backend: "memcached"}.Validate()
+	assert.Contains(t, errs, "backend")
+}
+
+func TestNewStore(t *testing.T) {
+	store, closeStore := NewStore(Default())
+	assert.IsType(t, &cache.MemoryStore{}, store)
+	assert.NoError(t, closeStore())
+
+	cfg := Default()
+	cfg.Backend = BackendRedis
+	store, closeStore = NewStore(cfg)
+	assert.IsType(t, &cache.RedisStore{}, store)
+	assert.NoError(t, closeStore())
+}
@@ -1,27 +1,32 @@
 package cors_config
 
 import (
-	"os"
-	"strings"
+	"errors"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-var defaultOrigins = []string{"*"}
+type Config struct {
+	AllowOrigins []string `config:"allow_origins" env:"CORS_ALLOW_ORIGINS"`
+}
 
-func CorsConfig() gin.HandlerFunc {
-	origins := getEnvAsSlice("CORS_ALLOW_ORIGINS", defaultOrigins)
-	config := cors.DefaultConfig()
-	config.AllowOrigins = origins
-	return cors.New(config)
+func Default() Config {
+	return Config{
+		AllowOrigins: []string{"*"},
+	}
 }
 
-// Helper function to get environment variable as a slice
-func getEnvAsSlice(name string, defaultVal []string) []string {
-	valStr := os.Getenv(name)
-	if valStr == "" {
-		return defaultVal
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if len(c.AllowOrigins) == 0 {
+		errs["allow_origins"] = errors.New("must list at least one origin")
 	}
-	return strings.Split(valStr, ",")
+	return errs
+}
+
+func CorsConfig(cfg Config) gin.HandlerFunc {
+	config := cors.DefaultConfig()
+	config.AllowOrigins = cfg.AllowOrigins
+	return cors.New(config)
 }
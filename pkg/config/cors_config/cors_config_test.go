@@ -3,7 +3,7 @@ package cors_config
 import (
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -43,16 +43,15 @@ func TestCorsConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set or unset the environment variable
+			// Build the configuration the loader would produce
+			cfg := Default()
 			if tt.envValue != "" {
-				os.Setenv("CORS_ALLOW_ORIGINS", tt.envValue)
-			} else {
-				os.Unsetenv("CORS_ALLOW_ORIGINS")
+				cfg.AllowOrigins = strings.Split(tt.envValue, ",")
 			}
 
 			// Setup Gin router with CORS middleware
 			router := gin.Default()
-			router.Use(CorsConfig())
+			router.Use(CorsConfig(cfg))
 
 			// Define a simple handler for testing
 			router.GET("/test", func(c *gin.Context) {
@@ -72,9 +71,6 @@ func TestCorsConfig(t *testing.T) {
 
 			// Check the CORS headers
 			assert.Equal(t, tt.wantAllowOrigin, w.Header().Get("Access-Control-Allow-Origin"))
-
-			// Clean up environment variable
-			os.Unsetenv("CORS_ALLOW_ORIGINS")
 		})
 	}
 }
@@ -1,74 +1,220 @@
 package db_config
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
+	"strconv"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql" // MySQL driver
 	_ "github.com/lib/pq"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/retry"
 )
 
-var DB_HOST = "127.0.0.1"
-var DB_PORT = "3306"
-var DB_NAME = "gin_gonic"
-var DB_USER = "root"
-var DB_PASSWORD = ""
-var DB_DRIVER = "mysql"
+type Config struct {
+	Host     string `config:"host" env:"DB_HOST"`
+	Port     string `config:"port" env:"DB_PORT"`
+	Name     string `config:"name" env:"DB_NAME"`
+	User     string `config:"user" env:"DB_USER"`
+	Password string `config:"password" env:"DB_PASSWORD"`
+	Driver   string `config:"driver" env:"DB_DRIVER"`
 
-// Define a type for the Open function
-type OpenFunc func(driverName, dataSourceName string) (*sql.DB, error)
+	// DSN, when set, is passed to the driver as-is and overrides the
+	// connection options above and below.
+	DSN string `config:"dsn" env:"DB_DSN"`
+
+	// TLS and session options
+	SSLMode         string `config:"sslmode" env:"DB_SSLMODE"`
+	SSLRootCert     string `config:"sslrootcert" env:"DB_SSLROOTCERT"`
+	SSLCert         string `config:"sslcert" env:"DB_SSLCERT"`
+	SSLKey          string `config:"sslkey" env:"DB_SSLKEY"`
+	TimeZone        string `config:"timezone" env:"DB_TIMEZONE"`
+	ApplicationName string `config:"application_name" env:"DB_APPLICATION_NAME"`
+
+	// Connection pool limits
+	MaxOpenConns    int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// Startup connection retries
+	ConnectMaxWait        time.Duration `config:"connect_max_wait" env:"DB_CONNECT_MAX_WAIT"`
+	ConnectInitialBackoff time.Duration `config:"connect_initial_backoff" env:"DB_CONNECT_INITIAL_BACKOFF"`
+	ConnectMaxBackoff     time.Duration `config:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF"`
+	ConnectPingTimeout    time.Duration `config:"connect_ping_timeout" env:"DB_CONNECT_PING_TIMEOUT"`
+}
+
+func Default() Config {
+	return Config{
+		Host:            "127.0.0.1",
+		Port:            "5432",
+		Name:            "gin_gonic",
+		User:            "postgres",
+		Driver:          "postgres",
+		SSLMode:         "disable",
+		TimeZone:        "Asia/Jakarta",
+		ApplicationName: "bookstore-api",
+		MaxOpenConns:    25,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
 
-func InitDatabaseConfig() {
-	env_DB_HOST := os.Getenv("DB_HOST")
-	if env_DB_HOST != "" {
-		DB_HOST = env_DB_HOST
+		ConnectMaxWait:        time.Minute,
+		ConnectInitialBackoff: 500 * time.Millisecond,
+		ConnectMaxBackoff:     10 * time.Second,
+		ConnectPingTimeout:    5 * time.Second,
 	}
-	env_DB_PORT := os.Getenv("DB_PORT")
-	if env_DB_PORT != "" {
-		DB_PORT = env_DB_PORT
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	// The migrations, queries and change notifications are written for postgres.
+	if c.Driver != "postgres" {
+		errs["driver"] = errors.New("must be postgres")
+	}
+
+	// The individual connection options are ignored when a DSN is given.
+	if c.DSN == "" {
+		if c.Host == "" {
+			errs["host"] = errors.New("must not be empty")
+		}
+		if port, err := strconv.Atoi(c.Port); err != nil || port <= 0 || port > 65535 {
+			errs["port"] = errors.New("must be a number between 1 and 65535")
+		}
+		if c.Name == "" {
+			errs["name"] = errors.New("must not be empty")
+		}
+		if c.User == "" {
+			errs["user"] = errors.New("must not be empty")
+		}
+		if c.SSLMode != "" && !sslModes[c.SSLMode] {
+			errs["sslmode"] = errors.New("must be one of disable, allow, prefer, require, verify-ca, verify-full")
+		}
+		files := map[string]string{
+			"sslrootcert": c.SSLRootCert,
+			"sslcert":     c.SSLCert,
+			"sslkey":      c.SSLKey,
+		}
+		for name, path := range files {
+			if path == "" {
+				continue
+			}
+			if err := validateFile(path); err != nil {
+				errs[name] = err
+			}
+		}
+		if (c.SSLCert == "") != (c.SSLKey == "") {
+			errs["sslkey"] = errors.New("sslcert and sslkey must be set together")
+		}
+		if c.TimeZone != "" {
+			if _, err := time.LoadLocation(c.TimeZone); err != nil {
+				errs["timezone"] = errors.New("must be an IANA time zone name")
+			}
+		}
+	}
+
+	if c.MaxOpenConns < 0 {
+		errs["max_open_conns"] = errors.New("must not be negative (0 means unlimited)")
 	}
-	env_DB_NAME := os.Getenv("DB_NAME")
-	if env_DB_NAME != "" {
-		DB_NAME = env_DB_NAME
+	if c.MaxIdleConns < 0 {
+		errs["max_idle_conns"] = errors.New("must not be negative")
 	}
-	env_DB_USER := os.Getenv("DB_USER")
-	if env_DB_USER != "" {
-		DB_USER = env_DB_USER
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs["max_idle_conns"] = errors.New("must not exceed max_open_conns")
 	}
-	env_DB_PASSWORD := os.Getenv("DB_PASSWORD")
-	if env_DB_PASSWORD != "" {
-		DB_PASSWORD = env_DB_PASSWORD
+	if c.ConnMaxLifetime < 0 {
+		errs["conn_max_lifetime"] = errors.New("must not be negative (0 means unlimited)")
 	}
-	env_DB_DRIVER := os.Getenv("DB_DRIVER")
-	if env_DB_DRIVER != "" {
-		DB_DRIVER = env_DB_DRIVER
+	if c.ConnMaxIdleTime < 0 {
+		errs["conn_max_idle_time"] = errors.New("must not be negative (0 means unlimited)")
 	}
+	if c.ConnectMaxWait < 0 {
+		errs["connect_max_wait"] = errors.New("must not be negative (0 means a single attempt)")
+	}
+	if c.ConnectInitialBackoff <= 0 {
+		errs["connect_initial_backoff"] = errors.New("must be greater than zero")
+	}
+	if c.ConnectMaxBackoff < c.ConnectInitialBackoff {
+		errs["connect_max_backoff"] = errors.New("must not be less than connect_initial_backoff")
+	}
+	if c.ConnectPingTimeout <= 0 {
+		errs["connect_ping_timeout"] = errors.New("must be greater than zero")
+	}
+	return errs
 }
 
-var DB *sql.DB
-
-func ConnectDatabase(openFunc OpenFunc) {
-	var errConnection error
+// Define a type for the Open function
+type OpenFunc func(driverName, dataSourceName string) (*sql.DB, error)
 
-	if DB_DRIVER == "mysql" {
-		dsnMysql := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", DB_USER, DB_PASSWORD, DB_HOST, DB_PORT, DB_NAME)
-		DB, errConnection = openFunc(DB_DRIVER, dsnMysql)
-	} else if DB_DRIVER == "postgres" {
-		fmt.Printf("postgres://%s:%s@%s:%s/%s?sslmode=disable&TimeZone=Asia/Jakarta", DB_USER, DB_PASSWORD, DB_HOST, DB_PORT, DB_NAME)
-		dsnPgSql := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&TimeZone=Asia/Jakarta", DB_USER, DB_PASSWORD, DB_HOST, DB_PORT, DB_NAME)
-		DB, errConnection = openFunc(DB_DRIVER, dsnPgSql)
-	} else {
-		panic("Invalid database driver")
+// ConnectDatabase opens the pool and pings it until the database answers,
+// retrying with exponential backoff and jitter for up to cfg.ConnectMaxWait.
+// It gives up early when ctx is cancelled.
+func ConnectDatabase(ctx context.Context, cfg Config, openFunc OpenFunc) (*sql.DB, error) {
+	dsn, err := DataSourceName(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build database connection string: %w", err)
 	}
+	logger := slog.Default().With("driver", cfg.Driver, "dsn", RedactDSN(dsn))
+
+	var db *sql.DB
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		if db == nil {
+			db, err = openFunc(cfg.Driver, dsn)
+			if err == nil {
+				configurePool(db, cfg)
+			} else {
+				db = nil
+			}
+		}
+		if db != nil {
+			pingCtx, cancel := context.WithTimeout(ctx, cfg.ConnectPingTimeout)
+			err = db.PingContext(pingCtx)
+			cancel()
+		}
+		if err == nil {
+			logger.Info("database connected",
+				"attempt", attempt,
+				"elapsed", time.Since(start),
+				"max_open_conns", cfg.MaxOpenConns,
+				"max_idle_conns", cfg.MaxIdleConns,
+				"conn_max_lifetime", cfg.ConnMaxLifetime,
+				"conn_max_idle_time", cfg.ConnMaxIdleTime,
+			)
+			return db, nil
+		}
+
+		delay := retry.Backoff{Initial: cfg.ConnectInitialBackoff, Max: cfg.ConnectMaxBackoff, Jitter: true}.Delay(attempt)
+		elapsed := time.Since(start)
+		if elapsed+delay > cfg.ConnectMaxWait {
+			logger.Error("database connection attempt failed, giving up", "attempt", attempt, "elapsed", elapsed, "error", err)
+			closeDB(db)
+			return nil, fmt.Errorf("database not reachable after %d attempts in %s: %w", attempt, elapsed.Round(time.Millisecond), err)
+		}
+		logger.Warn("database connection attempt failed, retrying", "attempt", attempt, "elapsed", elapsed, "retry_in", delay, "error", err)
 
-	if errConnection != nil {
-		panic(fmt.Sprintf("Failed to connect to database: %v", errConnection))
-	} else {
-		fmt.Println("Database connected")
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			closeDB(db)
+			return nil, fmt.Errorf("database connection aborted after %d attempts: %w", attempt, ctx.Err())
+		case <-timer.C:
+		}
 	}
 }
 
-func GetDB() *sql.DB {
-	return DB
+func configurePool(db *sql.DB, cfg Config) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+func closeDB(db *sql.DB) {
+	if db != nil {
+		db.Close()
+	}
 }
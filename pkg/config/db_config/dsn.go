@@ -0,0 +1,88 @@
+package db_config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+const redacted = "xxxxx"
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// DataSourceName builds the driver DSN from cfg. A non-empty cfg.DSN is used
+// verbatim and overrides every other connection option.
+func DataSourceName(cfg Config) (string, error) {
+	if cfg.DSN != "" {
+		return cfg.DSN, nil
+	}
+	if cfg.Driver != "postgres" {
+		return "", fmt.Errorf("invalid database driver %q", cfg.Driver)
+	}
+	return postgresDSN(cfg), nil
+}
+
+func postgresDSN(cfg Config) string {
+	query := url.Values{}
+	setIfNotEmpty := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	setIfNotEmpty("sslmode", cfg.SSLMode)
+	setIfNotEmpty("sslrootcert", cfg.SSLRootCert)
+	setIfNotEmpty("sslcert", cfg.SSLCert)
+	setIfNotEmpty("sslkey", cfg.SSLKey)
+	setIfNotEmpty("TimeZone", cfg.TimeZone)
+	setIfNotEmpty("application_name", cfg.ApplicationName)
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.Name,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+var (
+	keywordPassword  = regexp.MustCompile(`(?i)(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+	userinfoPassword = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://[^:/@]*:)[^@]*@`)
+)
+
+// RedactDSN replaces the password in a Postgres URL or keyword/value string
+// so it can be logged safely.
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		return u.String()
+	}
+	if userinfoPassword.MatchString(dsn) {
+		return userinfoPassword.ReplaceAllString(dsn, "${1}"+redacted+"@")
+	}
+	return keywordPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+func validateFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.New("file does not exist or is not readable")
+	}
+	if info.IsDir() {
+		return errors.New("must be a file, not a directory")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package graphql_config
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Config struct {
+	Enabled bool `config:"enabled" env:"GRAPHQL_ENABLED"`
+	// GraphiQL serves the in-browser IDE at /graphiql. It defaults to on in
+	// development (gin debug mode) and off when GIN_MODE=release.
+	GraphiQL bool `config:"graphiql" env:"GRAPHQL_GRAPHIQL"`
+
+	// Query limits; 0 disables a limit
+	MaxDepth      int `config:"max_depth" env:"GRAPHQL_MAX_DEPTH"`
+	MaxComplexity int `config:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY"`
+	ListFactor    int `config:"list_factor" env:"GRAPHQL_LIST_FACTOR"` // assumed size of list fields
+}
+
+func Default() Config {
+	return Config{
+		Enabled:       true,
+		GraphiQL:      gin.Mode() == gin.DebugMode,
+		MaxDepth:      8,
+		MaxComplexity: 1000,
+		ListFactor:    10,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.MaxDepth < 0 {
+		errs["max_depth"] = errors.New("must not be negative (0 means unlimited)")
+	}
+	if c.MaxComplexity < 0 {
+		errs["max_complexity"] = errors.New("must not be negative (0 means unlimited)")
+	}
+	if c.ListFactor < 1 {
+		errs["list_factor"] = errors.New("must be at least 1")
+	}
+	return errs
+}
@@ -0,0 +1,17 @@
+package graphql_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	errs := Config{MaxDepth: -1, MaxComplexity: -1}.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "max_depth")
+	assert.Contains(t, errs, "max_complexity")
+	assert.Contains(t, errs, "list_factor")
+}
@@ -0,0 +1,33 @@
+package grpc_config
+
+import (
+	"errors"
+	"net"
+	"strconv"
+)
+
+type Config struct {
+	Enabled bool `config:"enabled" env:"GRPC_ENABLED"`
+	// Port is the gRPC listen address, separate from the HTTP port.
+	Port       string `config:"port" env:"GRPC_PORT"`
+	Reflection bool   `config:"reflection" env:"GRPC_REFLECTION"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:    true,
+		Port:       ":9000",
+		Reflection: true,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	_, port, err := net.SplitHostPort(c.Port)
+	if err != nil {
+		errs["port"] = errors.New("must be in the form [host]:port")
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		errs["port"] = errors.New("port must be a number between 0 and 65535")
+	}
+	return errs
+}
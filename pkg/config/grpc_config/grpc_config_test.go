@@ -0,0 +1,16 @@
+package grpc_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	for _, port := range []string{"9000", ":abc", ":70000"} {
+		errs := Config{Port: port}.Validate()
+		assert.Contains(t, errs, "port", port)
+	}
+}
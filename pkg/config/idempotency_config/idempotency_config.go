@@ -0,0 +1,45 @@
+package idempotency_config
+
+import (
+	"errors"
+	"time"
+)
+
+// Config controls Idempotency-Key support on book creation.
+type Config struct {
+	Enabled bool `config:"enabled" env:"IDEMPOTENCY_ENABLED"`
+
+	// TTL is how long a key replays its response. A request whose key is
+	// still held after LockTimeout, e.g. because the replica crashed, may be
+	// retried.
+	TTL         time.Duration `config:"ttl" env:"IDEMPOTENCY_TTL"`
+	LockTimeout time.Duration `config:"lock_timeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+
+	// Expired keys are deleted every PruneInterval.
+	PruneInterval time.Duration `config:"prune_interval" env:"IDEMPOTENCY_PRUNE_INTERVAL"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:       true,
+		TTL:           24 * time.Hour,
+		LockTimeout:   time.Minute,
+		PruneInterval: time.Hour,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.TTL <= 0 {
+		errs["ttl"] = errors.New("must be positive")
+	}
+	if c.LockTimeout <= 0 {
+		errs["lock_timeout"] = errors.New("must be positive")
+	} else if c.LockTimeout > c.TTL {
+		errs["lock_timeout"] = errors.New("must not be longer than ttl")
+	}
+	if c.PruneInterval <= 0 {
+		errs["prune_interval"] = errors.New("must be positive")
+	}
+	return errs
+}
@@ -0,0 +1,20 @@
+package idempotency_config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.TTL = time.Second
+	cfg.PruneInterval = 0
+	errs := cfg.Validate()
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs, "lock_timeout")
+	assert.Contains(t, errs, "prune_interval")
+}
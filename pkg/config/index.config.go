@@ -1,14 +1,122 @@
 package configs
 
 import (
-	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/app_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/cache_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/cors_config"
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/db_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/graphql_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/grpc_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/idempotency_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/job_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/log_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/notify_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/outbox_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/ratelimit_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/stream_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/tracing_config"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/config/webhook_config"
 )
 
-func InitConfig() {
-	app_config.InitAppConfig()
-	db_config.InitDatabaseConfig()
-	db_config.ConnectDatabase(sql.Open)
+// Config is the complete application configuration. Each section is
+// addressed as "<section>.<key>" in config files and flags.
+type Config struct {
+	App         app_config.Config         `config:"app"`
+	Database    db_config.Config          `config:"database"`
+	CORS        cors_config.Config        `config:"cors"`
+	Log         log_config.Config         `config:"log"`
+	RateLimit   ratelimit_config.Config   `config:"rate_limit"`
+	Tracing     tracing_config.Config     `config:"tracing"`
+	Cache       cache_config.Config       `config:"cache"`
+	GraphQL     graphql_config.Config     `config:"graphql"`
+	GRPC        grpc_config.Config        `config:"grpc"`
+	Webhooks    webhook_config.Config     `config:"webhooks"`
+	Outbox      outbox_config.Config      `config:"outbox"`
+	Stream      stream_config.Config      `config:"stream"`
+	Notify      notify_config.Config      `config:"notify"`
+	Idempotency idempotency_config.Config `config:"idempotency"`
+	Jobs        job_config.Config         `config:"jobs"`
+}
+
+func Default() Config {
+	return Config{
+		App:         app_config.Default(),
+		Database:    db_config.Default(),
+		CORS:        cors_config.Default(),
+		Log:         log_config.Default(),
+		RateLimit:   ratelimit_config.Default(),
+		Tracing:     tracing_config.Default(),
+		Cache:       cache_config.Default(),
+		GraphQL:     graphql_config.Default(),
+		GRPC:        grpc_config.Default(),
+		Webhooks:    webhook_config.Default(),
+		Outbox:      outbox_config.Default(),
+		Stream:      stream_config.Default(),
+		Notify:      notify_config.Default(),
+		Idempotency: idempotency_config.Default(),
+		Jobs:        job_config.Default(),
+	}
+}
+
+// ValidationError lists every invalid field, one per line.
+type ValidationError struct {
+	Fields map[string]error
+}
+
+func (ve *ValidationError) Error() string {
+	names := make([]string, 0, len(ve.Fields))
+	for name := range ve.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	envs := map[string]string{}
+	cfg := Default()
+	for _, f := range collectFields(&cfg) {
+		envs[f.name] = f.env
+	}
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		if env := envs[name]; env != "" {
+			lines = append(lines, fmt.Sprintf("  %s (%s): %v", name, env, ve.Fields[name]))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s: %v", name, ve.Fields[name]))
+		}
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+func (c Config) Validate() error {
+	fields := map[string]error{}
+	sections := map[string]map[string]error{
+		"app":         c.App.Validate(),
+		"database":    c.Database.Validate(),
+		"cors":        c.CORS.Validate(),
+		"log":         c.Log.Validate(),
+		"rate_limit":  c.RateLimit.Validate(),
+		"tracing":     c.Tracing.Validate(),
+		"cache":       c.Cache.Validate(),
+		"graphql":     c.GraphQL.Validate(),
+		"grpc":        c.GRPC.Validate(),
+		"webhooks":    c.Webhooks.Validate(),
+		"outbox":      c.Outbox.Validate(),
+		"stream":      c.Stream.Validate(),
+		"notify":      c.Notify.Validate(),
+		"idempotency": c.Idempotency.Validate(),
+		"jobs":        c.Jobs.Validate(),
+	}
+	for section, errs := range sections {
+		for key, err := range errs {
+			fields[section+"."+key] = err
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
 }
@@ -0,0 +1,72 @@
+package job_config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Config controls the background job workers.
+type Config struct {
+	Enabled bool `config:"enabled" env:"JOBS_ENABLED"`
+
+	// Workers is how many jobs run at once on this replica. The table is
+	// polled every PollInterval and whenever a job is enqueued here.
+	Workers      int           `config:"workers" env:"JOBS_WORKERS"`
+	PollInterval time.Duration `config:"poll_interval" env:"JOBS_POLL_INTERVAL"`
+	Timeout      time.Duration `config:"timeout" env:"JOBS_TIMEOUT"`
+
+	// A failed run is retried after InitialBackoff, doubling up to
+	// MaxBackoff, until MaxAttempts runs failed.
+	MaxAttempts    int           `config:"max_attempts" env:"JOBS_MAX_ATTEMPTS"`
+	InitialBackoff time.Duration `config:"initial_backoff" env:"JOBS_INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `config:"max_backoff" env:"JOBS_MAX_BACKOFF"`
+
+	// Finished jobs older than Retention are deleted on the PruneSchedule
+	// cron spec.
+	Retention     time.Duration `config:"retention" env:"JOBS_RETENTION"`
+	PruneSchedule string        `config:"prune_schedule" env:"JOBS_PRUNE_SCHEDULE"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:        true,
+		Workers:        4,
+		PollInterval:   5 * time.Second,
+		Timeout:        5 * time.Minute,
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Hour,
+		Retention:      7 * 24 * time.Hour,
+		PruneSchedule:  "@hourly",
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.Workers < 1 {
+		errs["workers"] = errors.New("must be at least 1")
+	}
+	if c.PollInterval <= 0 {
+		errs["poll_interval"] = errors.New("must be positive")
+	}
+	if c.Timeout <= 0 {
+		errs["timeout"] = errors.New("must be positive")
+	}
+	if c.MaxAttempts < 1 {
+		errs["max_attempts"] = errors.New("must be at least 1")
+	}
+	if c.InitialBackoff <= 0 {
+		errs["initial_backoff"] = errors.New("must be positive")
+	} else if c.MaxBackoff < c.InitialBackoff {
+		errs["max_backoff"] = errors.New("must not be shorter than initial_backoff")
+	}
+	if c.Retention <= 0 {
+		errs["retention"] = errors.New("must be positive")
+	}
+	if _, err := cron.ParseStandard(c.PruneSchedule); err != nil {
+		errs["prune_schedule"] = err
+	}
+	return errs
+}
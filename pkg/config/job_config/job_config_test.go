@@ -0,0 +1,22 @@
+package job_config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.Workers = 0
+	cfg.MaxBackoff = time.Second
+	cfg.PruneSchedule = "every hour"
+	errs := cfg.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "workers")
+	assert.Contains(t, errs, "max_backoff")
+	assert.Contains(t, errs, "prune_schedule")
+}
@@ -0,0 +1,229 @@
+package configs
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const ConfigFileEnv = "CONFIG_FILE"
+
+type field struct {
+	name  string // "<section>.<key>"
+	env   string
+	value reflect.Value
+}
+
+// Load builds the configuration from, in increasing precedence: defaults,
+// an optional YAML or TOML file (-config or CONFIG_FILE), environment
+// variables and command-line flags. Every invalid value is reported at once.
+func Load(args []string) (Config, error) {
+	fs := flag.NewFlagSet("bookstore", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return LoadFlags(fs, args)
+}
+
+// LoadFlags is Load with the configuration flags added to fs, so a command
+// can parse its own flags from the same arguments.
+func LoadFlags(fs *flag.FlagSet, args []string) (Config, error) {
+	cfg := Default()
+	fields := collectFields(&cfg)
+	invalid := map[string]error{}
+
+	configFile := fs.String("config", os.Getenv(ConfigFileEnv), "path to a YAML or TOML config file")
+	flagValues := map[string]string{}
+	for _, f := range fields {
+		name := f.name
+		fs.Func(name, "overrides "+f.env, func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configFile != "" {
+		fileValues, err := readFile(*configFile)
+		if err != nil {
+			return cfg, err
+		}
+		known := map[string]bool{}
+		for _, f := range fields {
+			known[f.name] = true
+		}
+		for name := range fileValues {
+			if !known[name] {
+				invalid[name] = fmt.Errorf("unknown setting in %s", *configFile)
+			}
+		}
+		apply(fields, fileValues, invalid)
+	}
+
+	envValues := map[string]string{}
+	for _, f := range fields {
+		// A variable set to "" applies too, e.g. to clear a file value;
+		// Validate rejects it where a value is required.
+		if value, ok := os.LookupEnv(f.env); ok {
+			envValues[f.name] = value
+		}
+	}
+	apply(fields, envValues, invalid)
+	apply(fields, flagValues, invalid)
+
+	if len(invalid) > 0 {
+		// Values that failed to parse are reported together with the
+		// semantic checks below.
+		if err := cfg.Validate(); err != nil {
+			for name, fieldErr := range err.(*ValidationError).Fields {
+				if _, exists := invalid[name]; !exists {
+					invalid[name] = fieldErr
+				}
+			}
+		}
+		return cfg, &ValidationError{Fields: invalid}
+	}
+	return cfg, cfg.Validate()
+}
+
+func collectFields(cfg *Config) []field {
+	var fields []field
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i).Tag.Get("config")
+		sectionValue := root.Field(i)
+		for j := 0; j < sectionValue.NumField(); j++ {
+			sf := sectionValue.Type().Field(j)
+			fields = append(fields, field{
+				name:  section + "." + sf.Tag.Get("config"),
+				env:   sf.Tag.Get("env"),
+				value: sectionValue.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+func apply(fields []field, values map[string]string, invalid map[string]error) {
+	for _, f := range fields {
+		raw, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			invalid[f.name] = fmt.Errorf("invalid value %q: %v", raw, err)
+		}
+	}
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// readFile flattens a YAML or TOML file into "<section>.<key>" strings so
+// file values go through the same parsing as environment variables.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	for section, entries := range doc {
+		for key, value := range entries {
+			values[section+"."+key] = flatten(value)
+		}
+	}
+	return values, nil
+}
+
+func flatten(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, len(v))
+		for _, key := range keys {
+			items = append(items, key+"="+fmt.Sprint(v[key]))
+		}
+		return strings.Join(items, ",")
+	case time.Time:
+		// YAML and TOML decode timestamps natively.
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,121 @@
+package configs
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+app:
+  port: ":7000"
+  shutdown_timeout: 45s
+  legacy_routes_sunset: 2027-01-01T00:00:00Z
+database:
+  driver: postgres
+  host: db.internal
+cors:
+  allow_origins: [https://a.example, https://b.example]
+rate_limit:
+  routes:
+    GET /books/: 300/1m
+`)
+	t.Setenv(ConfigFileEnv, path)
+	t.Setenv("DB_HOST", "env.internal")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	cfg, err := Load([]string{"-log.level=warn"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":7000", cfg.App.Port)
+	assert.Equal(t, 45*time.Second, cfg.App.ShutdownTimeout)
+	assert.True(t, cfg.App.LegacyRoutesSunset.Equal(time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "postgres", cfg.Database.Driver)
+	assert.Equal(t, "env.internal", cfg.Database.Host)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.CORS.AllowOrigins)
+	assert.Equal(t, ratelimit.Limit{Requests: 300, Period: time.Minute}, cfg.RateLimit.Routes["GET /books/"])
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[tracing]
+exporter = "stdout"
+`)
+
+	cfg, err := Load([]string{"-config", path})
+	assert.NoError(t, err)
+	assert.Equal(t, "stdout", cfg.Tracing.Exporter)
+}
+
+func TestLoadListsEveryInvalidField(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+app:
+  colour: blue
+`)
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+	t.Setenv("DB_DRIVER", "sqlite")
+
+	_, err := Load([]string{"-config", path, "-log.format=xml"})
+	assert.Error(t, err)
+
+	ve, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, ve.Fields, 4)
+	for _, name := range []string{"app.colour", "app.http_read_timeout", "database.driver", "log.format"} {
+		assert.Contains(t, ve.Fields, name)
+	}
+	assert.Contains(t, err.Error(), "database.driver (DB_DRIVER)")
+}
+
+func TestLoadEmptyEnv(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+app:
+  admin_api_key: from-file
+`)
+	t.Setenv(ConfigFileEnv, path)
+	t.Setenv("ADMIN_API_KEY", "")
+
+	cfg, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.App.AdminAPIKey)
+
+	// An empty value is checked like any other.
+	t.Setenv("DB_HOST", "")
+	_, err = Load(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.(*ValidationError).Fields, "database.host")
+}
+
+func TestLoadUnknownFlag(t *testing.T) {
+	_, err := Load([]string{"-nope=1"})
+	assert.Error(t, err)
+}
+
+func TestLoadFlags(t *testing.T) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "")
+	cfg, err := LoadFlags(fs, []string{"-format=csv", "-log.level=warn", "books.csv"})
+	assert.NoError(t, err)
+	assert.Equal(t, "csv", *format)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, []string{"books.csv"}, fs.Args())
+}
@@ -0,0 +1,42 @@
+package log_config
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+type Config struct {
+	Level  string `config:"level" env:"LOG_LEVEL"`
+	Format string `config:"format" env:"LOG_FORMAT"` // json or text
+}
+
+func Default() Config {
+	return Config{
+		Level:  "info",
+		Format: "json",
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if _, err := logger.ParseLevel(c.Level); err != nil {
+		errs["level"] = err
+	}
+	if c.Format != "json" && c.Format != "text" {
+		errs["format"] = errors.New("must be json or text")
+	}
+	return errs
+}
+
+// Setup installs the configured logger as the slog default.
+func Setup(cfg Config, w io.Writer) (*slog.Logger, error) {
+	l, err := logger.New(w, cfg.Level, cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(l)
+	return l, nil
+}
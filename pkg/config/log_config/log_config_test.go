@@ -0,0 +1,29 @@
+package log_config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	errs := Config{Level: "loud", Format: "xml"}.Validate()
+	assert.Contains(t, errs, "level")
+	assert.Contains(t, errs, "format")
+}
+
+func TestSetup(t *testing.T) {
+	originalLogger := slog.Default()
+	defer slog.SetDefault(originalLogger)
+
+	var buf bytes.Buffer
+	l, err := Setup(Config{Level: "debug", Format: "text"}, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, l, slog.Default())
+	assert.True(t, slog.Default().Enabled(context.Background(), slog.LevelDebug))
+}
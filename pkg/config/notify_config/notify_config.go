@@ -0,0 +1,41 @@
+package notify_config
+
+import (
+	"errors"
+	"time"
+)
+
+// Config controls the Postgres LISTEN connection that tells each replica
+// about changes made by the others.
+type Config struct {
+	Enabled bool `config:"enabled" env:"NOTIFY_ENABLED"`
+
+	// A lost connection is retried after MinReconnect, doubling up to
+	// MaxReconnect.
+	MinReconnect time.Duration `config:"min_reconnect" env:"NOTIFY_MIN_RECONNECT"`
+	MaxReconnect time.Duration `config:"max_reconnect" env:"NOTIFY_MAX_RECONNECT"`
+	PingInterval time.Duration `config:"ping_interval" env:"NOTIFY_PING_INTERVAL"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:      true,
+		MinReconnect: time.Second,
+		MaxReconnect: time.Minute,
+		PingInterval: 90 * time.Second,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.MinReconnect <= 0 {
+		errs["min_reconnect"] = errors.New("must be positive")
+	}
+	if c.MaxReconnect < c.MinReconnect {
+		errs["max_reconnect"] = errors.New("must not be shorter than min_reconnect")
+	}
+	if c.PingInterval <= 0 {
+		errs["ping_interval"] = errors.New("must be positive")
+	}
+	return errs
+}
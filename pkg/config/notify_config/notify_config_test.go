@@ -0,0 +1,20 @@
+package notify_config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.MaxReconnect = time.Millisecond
+	cfg.PingInterval = 0
+	errs := cfg.Validate()
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs, "max_reconnect")
+	assert.Contains(t, errs, "ping_interval")
+}
@@ -0,0 +1,49 @@
+package outbox_config
+
+import (
+	"errors"
+	"time"
+)
+
+type Config struct {
+	PollInterval time.Duration `config:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `config:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+
+	// A message that fails to publish is retried after InitialBackoff,
+	// doubling up to MaxBackoff.
+	InitialBackoff time.Duration `config:"initial_backoff" env:"OUTBOX_INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `config:"max_backoff" env:"OUTBOX_MAX_BACKOFF"`
+
+	// Published messages are kept for Retention, 0 keeps them forever.
+	Retention time.Duration `config:"retention" env:"OUTBOX_RETENTION"`
+}
+
+func Default() Config {
+	return Config{
+		PollInterval:   time.Second,
+		BatchSize:      100,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Retention:      7 * 24 * time.Hour,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.PollInterval <= 0 {
+		errs["poll_interval"] = errors.New("must be positive")
+	}
+	if c.BatchSize < 1 {
+		errs["batch_size"] = errors.New("must be at least 1")
+	}
+	if c.InitialBackoff <= 0 {
+		errs["initial_backoff"] = errors.New("must be positive")
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		errs["max_backoff"] = errors.New("must not be shorter than initial_backoff")
+	}
+	if c.Retention < 0 {
+		errs["retention"] = errors.New("must not be negative")
+	}
+	return errs
+}
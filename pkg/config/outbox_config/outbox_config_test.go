@@ -0,0 +1,22 @@
+package outbox_config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.BatchSize = 0
+	cfg.MaxBackoff = time.Millisecond
+	cfg.Retention = -time.Hour
+	errs := cfg.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "batch_size")
+	assert.Contains(t, errs, "max_backoff")
+	assert.Contains(t, errs, "retention")
+}
@@ -0,0 +1,32 @@
+package ratelimit_config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+)
+
+type Config struct {
+	Enabled bool            `config:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Default ratelimit.Limit `config:"default" env:"RATE_LIMIT_DEFAULT"`
+	// Routes is keyed by "<METHOD> <route>" without the version prefix, e.g.
+	// "GET /books/". gRPC methods count as their REST route.
+	Routes ratelimit.RouteLimits `config:"routes" env:"RATE_LIMIT_ROUTES"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled: true,
+		Default: ratelimit.Limit{Requests: 100, Period: time.Minute},
+		Routes:  ratelimit.RouteLimits{},
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.Default.Requests <= 0 || c.Default.Period <= 0 {
+		errs["default"] = errors.New("must be <requests>/<period> with positive values")
+	}
+	return errs
+}
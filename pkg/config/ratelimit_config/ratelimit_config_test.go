@@ -0,0 +1,16 @@
+package ratelimit_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.Default = ratelimit.Limit{}
+	assert.Contains(t, cfg.Validate(), "default")
+}
@@ -0,0 +1,46 @@
+package stream_config
+
+import (
+	"errors"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `config:"enabled" env:"STREAM_ENABLED"`
+
+	// History is how many recent events a reconnecting client can resume
+	// from. A client more than SubscriberBuffer events behind is
+	// disconnected and has to resume.
+	History          int `config:"history" env:"STREAM_HISTORY"`
+	SubscriberBuffer int `config:"subscriber_buffer" env:"STREAM_SUBSCRIBER_BUFFER"`
+
+	HeartbeatInterval time.Duration `config:"heartbeat_interval" env:"STREAM_HEARTBEAT_INTERVAL"`
+	WriteTimeout      time.Duration `config:"write_timeout" env:"STREAM_WRITE_TIMEOUT"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:           true,
+		History:           1000,
+		SubscriberBuffer:  64,
+		HeartbeatInterval: 15 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.History < 0 {
+		errs["history"] = errors.New("must not be negative")
+	}
+	if c.SubscriberBuffer < 1 {
+		errs["subscriber_buffer"] = errors.New("must be at least 1")
+	}
+	if c.HeartbeatInterval <= 0 {
+		errs["heartbeat_interval"] = errors.New("must be positive")
+	}
+	if c.WriteTimeout <= 0 {
+		errs["write_timeout"] = errors.New("must be positive")
+	}
+	return errs
+}
@@ -0,0 +1,21 @@
+package stream_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.History = -1
+	cfg.SubscriberBuffer = 0
+	cfg.HeartbeatInterval = 0
+	errs := cfg.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "history")
+	assert.Contains(t, errs, "subscriber_buffer")
+	assert.Contains(t, errs, "heartbeat_interval")
+}
@@ -0,0 +1,32 @@
+package tracing_config
+
+import (
+	"errors"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/tracing"
+)
+
+type Config struct {
+	Exporter    string `config:"exporter" env:"TRACING_EXPORTER"` // none, stdout or otlp
+	ServiceName string `config:"service_name" env:"TRACING_SERVICE_NAME"`
+}
+
+func Default() Config {
+	return Config{
+		Exporter:    tracing.ExporterNone,
+		ServiceName: "bookstore-api",
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	switch c.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		errs["exporter"] = errors.New("must be none, stdout or otlp")
+	}
+	if c.ServiceName == "" {
+		errs["service_name"] = errors.New("must not be empty")
+	}
+	return errs
+}
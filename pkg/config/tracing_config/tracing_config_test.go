@@ -0,0 +1,15 @@
+package tracing_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	errs := Config{Exporter: "jaeger"}.Validate()
+	assert.Contains(t, errs, "exporter")
+	assert.Contains(t, errs, "service_name")
+}
@@ -0,0 +1,62 @@
+package webhook_config
+
+import (
+	"errors"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `config:"enabled" env:"WEBHOOKS_ENABLED"`
+
+	// A delivery is dead-lettered after MaxAttempts failures. The wait between
+	// attempts starts at InitialBackoff and doubles up to MaxBackoff.
+	MaxAttempts    int           `config:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS"`
+	InitialBackoff time.Duration `config:"initial_backoff" env:"WEBHOOKS_INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `config:"max_backoff" env:"WEBHOOKS_MAX_BACKOFF"`
+	Timeout        time.Duration `config:"timeout" env:"WEBHOOKS_TIMEOUT"` // per attempt
+
+	// One claim takes at most BatchSize deliveries, and no more than there
+	// are idle Workers.
+	PollInterval time.Duration `config:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL"`
+	BatchSize    int           `config:"batch_size" env:"WEBHOOKS_BATCH_SIZE"`
+	Workers      int           `config:"workers" env:"WEBHOOKS_WORKERS"`
+}
+
+func Default() Config {
+	return Config{
+		Enabled:        true,
+		MaxAttempts:    8,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Hour,
+		Timeout:        10 * time.Second,
+		PollInterval:   5 * time.Second,
+		BatchSize:      50,
+		Workers:        4,
+	}
+}
+
+func (c Config) Validate() map[string]error {
+	errs := map[string]error{}
+	if c.MaxAttempts < 1 {
+		errs["max_attempts"] = errors.New("must be at least 1")
+	}
+	if c.InitialBackoff <= 0 {
+		errs["initial_backoff"] = errors.New("must be positive")
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		errs["max_backoff"] = errors.New("must not be shorter than initial_backoff")
+	}
+	if c.Timeout <= 0 {
+		errs["timeout"] = errors.New("must be positive")
+	}
+	if c.PollInterval <= 0 {
+		errs["poll_interval"] = errors.New("must be positive")
+	}
+	if c.BatchSize < 1 {
+		errs["batch_size"] = errors.New("must be at least 1")
+	}
+	if c.Workers < 1 {
+		errs["workers"] = errors.New("must be at least 1")
+	}
+	return errs
+}
@@ -0,0 +1,22 @@
+package webhook_config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Empty(t, Default().Validate())
+
+	cfg := Default()
+	cfg.MaxAttempts = 0
+	cfg.MaxBackoff = time.Second
+	cfg.Workers = 0
+	errs := cfg.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "max_attempts")
+	assert.Contains(t, errs, "max_backoff")
+	assert.Contains(t, errs, "workers")
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+type APIKeyController struct {
+	APIKeyService apikeyservices.APIKeyServicesInterface
+}
+
+func NewAPIKeyController(apiKeyService apikeyservices.APIKeyServicesInterface) *APIKeyController {
+	return &APIKeyController{
+		APIKeyService: apiKeyService,
+	}
+}
+
+func (ac *APIKeyController) IssueAPIKey(c *gin.Context) {
+	var apiKeyRequest apikeyservices.APIKeyRequest
+	if err := c.ShouldBindJSON(&apiKeyRequest); err != nil {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
+		return
+	}
+	var fieldErrors []problem.FieldError
+	if apiKeyRequest.Name == "" {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "name", Rule: "required", Message: "name is required"})
+	}
+	if err := apikeyservices.ValidateScopes(apiKeyRequest.Scopes); err != nil {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "scopes", Rule: "oneof", Message: err.Error()})
+	}
+	if len(fieldErrors) > 0 {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "The request body is invalid.").WithErrors(fieldErrors...))
+		return
+	}
+	apiKey, err := ac.APIKeyService.IssueAPIKey(c.Request.Context(), apiKeyRequest)
+	if err != nil {
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, apiKey)
+}
+
+func (ac *APIKeyController) GetAllAPIKeys(c *gin.Context) {
+	apiKeys, err := ac.APIKeyService.GetAllAPIKeys(c.Request.Context())
+	if err != nil {
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+func (ac *APIKeyController) RevokeAPIKeyByID(c *gin.Context) {
+	apiKeyID := c.Param("apiKeyID")
+	err := ac.APIKeyService.RevokeAPIKeyByID(c.Request.Context(), apiKeyID)
+	if err != nil {
+		if errors.Is(err, apikeyservices.ErrAPIKeyNotFound) {
+			problem.Abort(c, problem.New(http.StatusNotFound, problem.CodeAPIKeyNotFound, err.Error()))
+			return
+		}
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) IssueAPIKey(ctx context.Context, apiKey apikeyservices.APIKeyRequest) (apikeyservices.IssuedAPIKeyResponse, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(apikeyservices.IssuedAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAllAPIKeys(ctx context.Context) ([]apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error {
+	args := m.Called(ctx, apiKeyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func TestIssueAPIKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        apikeyservices.APIKeyRequest
+		mockReturn     apikeyservices.IssuedAPIKeyResponse
+		mockError      error
+		callsService   bool
+		expectedStatus int
+	}{
+		{
+			name:           "Success",
+			request:        apikeyservices.APIKeyRequest{Name: "scanner", Scopes: []string{apikeyservices.ScopeBooksRead}},
+			mockReturn:     apikeyservices.IssuedAPIKeyResponse{Key: "bks_secret"},
+			callsService:   true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Unknown Scope",
+			request:        apikeyservices.APIKeyRequest{Name: "scanner", Scopes: []string{"everything"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Server Error",
+			request:        apikeyservices.APIKeyRequest{Name: "scanner", Scopes: []string{apikeyservices.ScopeOrdersWrite}},
+			mockError:      errors.New("database error"),
+			callsService:   true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			controller := NewAPIKeyController(mockService)
+			if tt.callsService {
+				mockService.On("IssueAPIKey", mock.Anything, tt.request).Return(tt.mockReturn, tt.mockError)
+			}
+
+			jsonData, _ := json.Marshal(tt.request)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(jsonData))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			controller.IssueAPIKey(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeAPIKeyByID(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "Success", expectedStatus: http.StatusOK},
+		{name: "Not Found", mockError: apikeyservices.ErrAPIKeyNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			controller := NewAPIKeyController(mockService)
+			mockService.On("RevokeAPIKeyByID", mock.Anything, "1").Return(tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/", nil)
+			c.Params = []gin.Param{{Key: "apiKeyID", Value: "1"}}
+
+			controller.RevokeAPIKeyByID(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,12 +1,20 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
 )
 
+type messageResponse struct {
+	Message string `json:"message" xml:"message" yaml:"message"`
+}
+
 type BookController struct {
 	BookService bookservices.BookServicesInterface
 }
@@ -18,59 +26,109 @@ func NewBookController(bookService bookservices.BookServicesInterface) *BookCont
 }
 
 func (bc *BookController) GetAllBooks(c *gin.Context) {
-	books, err := bc.BookService.GetAllBooks()
+	span := startSpan(c, "BookController.GetAllBooks")
+	defer span.End()
+
+	books, err := bc.BookService.GetAllBooks(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Internal(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, books)
+	negotiation.Render(c, http.StatusOK, "book", books)
 }
 
 func (bc *BookController) GetBookByID(c *gin.Context) {
-	bookID := c.Param("bookID")
-	book, err := bc.BookService.GetBookByID(bookID)
+	span := startSpan(c, "BookController.GetBookByID")
+	defer span.End()
+
+	bookID, ok := bookIDParam(c)
+	if !ok {
+		return
+	}
+	book, err := bc.BookService.GetBookByID(c.Request.Context(), bookID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		abortBookError(c, bookID, err)
 		return
 	}
-	c.JSON(http.StatusOK, book)
+	negotiation.Render(c, http.StatusOK, "book", book)
 }
 
 func (bc *BookController) CreateBook(c *gin.Context) {
+	span := startSpan(c, "BookController.CreateBook")
+	defer span.End()
+
 	var bookRequest bookservices.BookRequest
-	if err := c.ShouldBindJSON(&bookRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindBody(c, &bookRequest) {
 		return
 	}
-	book, err := bc.BookService.CreateBook(bookRequest)
+	book, err := bc.BookService.CreateBook(c.Request.Context(), bookRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Internal(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, book)
+	logger.FromContext(c.Request.Context()).Info("book created", "book_id", book.ID)
+	negotiation.Render(c, http.StatusOK, "book", book)
 }
 
 func (bc *BookController) UpdateBookByID(c *gin.Context) {
-	bookID := c.Param("bookID")
+	span := startSpan(c, "BookController.UpdateBookByID")
+	defer span.End()
+
+	bookID, ok := bookIDParam(c)
+	if !ok {
+		return
+	}
 	var bookUpdateRequest bookservices.BookUpdateRequest
-	if err := c.ShouldBindJSON(&bookUpdateRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindBody(c, &bookUpdateRequest) {
 		return
 	}
-	book, err := bc.BookService.UpdateBookByID(bookID, bookUpdateRequest)
+	book, err := bc.BookService.UpdateBookByID(c.Request.Context(), bookID, bookUpdateRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortBookError(c, bookID, err)
 		return
 	}
-	c.JSON(http.StatusOK, book)
+	logger.FromContext(c.Request.Context()).Info("book updated", "book_id", book.ID)
+	negotiation.Render(c, http.StatusOK, "book", book)
 }
 
 func (bc *BookController) DeleteBookByID(c *gin.Context) {
-	bookID := c.Param("bookID")
-	err := bc.BookService.DeleteBookByID(bookID)
+	span := startSpan(c, "BookController.DeleteBookByID")
+	defer span.End()
+
+	bookID, ok := bookIDParam(c)
+	if !ok {
+		return
+	}
+	err := bc.BookService.DeleteBookByID(c.Request.Context(), bookID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Internal(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Book deleted successfully"})
+	logger.FromContext(c.Request.Context()).Info("book deleted", "book_id", bookID)
+	negotiation.Render(c, http.StatusOK, "result", messageResponse{Message: "Book deleted successfully"})
+}
+
+// bookIDParam reads the bookID path parameter and answers 404 for anything
+// that cannot be a book ID, so it never reaches the database.
+func bookIDParam(c *gin.Context) (string, bool) {
+	bookID := c.Param("bookID")
+	if !isID(bookID) {
+		abortBookNotFound(c, bookID)
+		return "", false
+	}
+	return bookID, true
+}
+
+// abortBookError answers 404 for a missing book and 500 for anything else,
+// so a failing database is not mistaken for missing books.
+func abortBookError(c *gin.Context, bookID string, err error) {
+	if errors.Is(err, bookservices.ErrBookNotFound) {
+		abortBookNotFound(c, bookID)
+		return
+	}
+	problem.Internal(c, err)
+}
+
+func abortBookNotFound(c *gin.Context, bookID string) {
+	problem.Abort(c, problem.New(http.StatusNotFound, problem.CodeBookNotFound, "Book "+bookID+" was not found."))
 }
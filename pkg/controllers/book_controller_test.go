@@ -2,10 +2,12 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,34 +15,40 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
 )
 
 type MockBookService struct {
 	mock.Mock
 }
 
-func (m *MockBookService) CreateBook(book bookservices.BookRequest) (bookservices.BookResponse, error) {
-	args := m.Called(book)
+func (m *MockBookService) CreateBook(ctx context.Context, book bookservices.BookRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, book)
 	return args.Get(0).(bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) GetAllBooks() ([]bookservices.BookResponse, error) {
-	args := m.Called()
+func (m *MockBookService) GetAllBooks(ctx context.Context) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) GetBookByID(bookID string) (bookservices.BookResponse, error) {
-	args := m.Called(bookID)
+func (m *MockBookService) GetBookByID(ctx context.Context, bookID string) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID)
 	return args.Get(0).(bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) UpdateBookByID(bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
-	args := m.Called(bookID, book)
+func (m *MockBookService) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookIDs)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) UpdateBookByID(ctx context.Context, bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID, book)
 	return args.Get(0).(bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) DeleteBookByID(bookID string) error {
-	args := m.Called(bookID)
+func (m *MockBookService) DeleteBookByID(ctx context.Context, bookID string) error {
+	args := m.Called(ctx, bookID)
 	return args.Error(0)
 }
 
@@ -87,10 +95,11 @@ func TestGetAllBooks(t *testing.T) {
 			mockService := new(MockBookService)
 			controller := NewBookController(mockService)
 
-			mockService.On("GetAllBooks").Return(tt.mockReturn, tt.mockError)
+			mockService.On("GetAllBooks", mock.Anything).Return(tt.mockReturn, tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
 
 			controller.GetAllBooks(c)
 
@@ -115,6 +124,7 @@ func TestGetBookByID(t *testing.T) {
 		mockReturn     bookservices.BookResponse
 		mockError      error
 		expectedStatus int
+		expectedCode   string
 	}{
 		{
 			name:   "Success",
@@ -132,8 +142,17 @@ func TestGetBookByID(t *testing.T) {
 			name:           "Not Found",
 			bookID:         "999",
 			mockReturn:     bookservices.BookResponse{},
-			mockError:      errors.New("book not found"),
+			mockError:      bookservices.ErrBookNotFound,
 			expectedStatus: http.StatusNotFound,
+			expectedCode:   problem.CodeBookNotFound,
+		},
+		{
+			name:           "Store Failure",
+			bookID:         "1",
+			mockReturn:     bookservices.BookResponse{},
+			mockError:      context.DeadlineExceeded,
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   problem.CodeInternal,
 		},
 	}
 
@@ -142,10 +161,11 @@ func TestGetBookByID(t *testing.T) {
 			mockService := new(MockBookService)
 			controller := NewBookController(mockService)
 
-			mockService.On("GetBookByID", tt.bookID).Return(tt.mockReturn, tt.mockError)
+			mockService.On("GetBookByID", mock.Anything, tt.bookID).Return(tt.mockReturn, tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
 			c.Params = []gin.Param{{Key: "bookID", Value: tt.bookID}}
 
 			controller.GetBookByID(c)
@@ -157,6 +177,13 @@ func TestGetBookByID(t *testing.T) {
 				err := json.Unmarshal(w.Body.Bytes(), &actualBook)
 				assert.NoError(t, err)
 				assert.Equal(t, tt.mockReturn, actualBook)
+			} else {
+				var actualProblem problem.Problem
+				err := json.Unmarshal(w.Body.Bytes(), &actualProblem)
+				assert.NoError(t, err)
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedCode, actualProblem.Code)
+				assert.Equal(t, tt.expectedStatus, actualProblem.Status)
 			}
 
 			mockService.AssertExpectations(t)
@@ -223,7 +250,7 @@ func TestCreateBook(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			mockService.On("CreateBook", tt.bookRequest).Return(tt.mockReturn, tt.mockError)
+			mockService.On("CreateBook", mock.Anything, tt.bookRequest).Return(tt.mockReturn, tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -255,6 +282,7 @@ func TestUpdateBookByID(t *testing.T) {
 		mockReturn     bookservices.BookResponse
 		mockError      error
 		expectedStatus int
+		expectedCode   string
 	}{
 		{
 			name:   "Success",
@@ -274,12 +302,30 @@ func TestUpdateBookByID(t *testing.T) {
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "Error",
-			bookID:         "1",
-			updateRequest:  bookservices.BookUpdateRequest{},
+			name:   "Not Found",
+			bookID: "999",
+			updateRequest: bookservices.BookUpdateRequest{
+				Name:        "Updated Book",
+				Author:      "Updated Author",
+				Publication: "Updated Publication",
+			},
+			mockReturn:     bookservices.BookResponse{},
+			mockError:      bookservices.ErrBookNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   problem.CodeBookNotFound,
+		},
+		{
+			name:   "Error",
+			bookID: "1",
+			updateRequest: bookservices.BookUpdateRequest{
+				Name:        "Updated Book",
+				Author:      "Updated Author",
+				Publication: "Updated Publication",
+			},
 			mockReturn:     bookservices.BookResponse{},
 			mockError:      errors.New("update error"),
 			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   problem.CodeInternal,
 		},
 	}
 
@@ -288,7 +334,7 @@ func TestUpdateBookByID(t *testing.T) {
 			mockService := new(MockBookService)
 			controller := NewBookController(mockService)
 
-			mockService.On("UpdateBookByID", tt.bookID, tt.updateRequest).Return(tt.mockReturn, tt.mockError)
+			mockService.On("UpdateBookByID", mock.Anything, tt.bookID, tt.updateRequest).Return(tt.mockReturn, tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -307,6 +353,10 @@ func TestUpdateBookByID(t *testing.T) {
 				err := json.Unmarshal(w.Body.Bytes(), &actualBook)
 				assert.NoError(t, err)
 				assert.Equal(t, tt.mockReturn, actualBook)
+			} else {
+				var actualProblem problem.Problem
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualProblem))
+				assert.Equal(t, tt.expectedCode, actualProblem.Code)
 			}
 
 			mockService.AssertExpectations(t)
@@ -340,10 +390,11 @@ func TestDeleteBookByID(t *testing.T) {
 			mockService := new(MockBookService)
 			controller := NewBookController(mockService)
 
-			mockService.On("DeleteBookByID", tt.bookID).Return(tt.mockError)
+			mockService.On("DeleteBookByID", mock.Anything, tt.bookID).Return(tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/", nil)
 			c.Params = []gin.Param{{Key: "bookID", Value: tt.bookID}}
 
 			controller.DeleteBookByID(c)
@@ -354,3 +405,181 @@ func TestDeleteBookByID(t *testing.T) {
 		})
 	}
 }
+
+func TestBookIDValidation(t *testing.T) {
+	controller := NewBookController(new(MockBookService))
+	handlers := map[string]gin.HandlerFunc{
+		http.MethodGet:    controller.GetBookByID,
+		http.MethodPut:    controller.UpdateBookByID,
+		http.MethodDelete: controller.DeleteBookByID,
+	}
+	for method, handler := range handlers {
+		for _, bookID := range []string{"abc", "0", "-1", "1.5", "99999999999999999999"} {
+			t.Run(method+" "+bookID, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest(method, "/", strings.NewReader(`{}`))
+				c.Params = []gin.Param{{Key: "bookID", Value: bookID}}
+
+				handler(c)
+
+				assert.Equal(t, http.StatusNotFound, w.Code)
+				var actualProblem problem.Problem
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualProblem))
+				assert.Equal(t, problem.CodeBookNotFound, actualProblem.Code)
+			})
+		}
+	}
+}
+
+func TestBookRequestValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedCode   string
+		expectedFields []string
+	}{
+		{
+			name:           "Create with empty object",
+			method:         http.MethodPost,
+			body:           `{}`,
+			expectedCode:   problem.CodeValidationFailed,
+			expectedFields: []string{"name", "author", "publication"},
+		},
+		{
+			name:           "Create with blank and oversized fields",
+			method:         http.MethodPost,
+			body:           `{"name":"   ","author":"` + strings.Repeat("a", 256) + `","publication":"P"}`,
+			expectedCode:   problem.CodeValidationFailed,
+			expectedFields: []string{"name", "author"},
+		},
+		{
+			name:           "Create with unknown field",
+			method:         http.MethodPost,
+			body:           `{"name":"N","author":"A","publication":"P","isbn":"123"}`,
+			expectedCode:   problem.CodeValidationFailed,
+			expectedFields: []string{"isbn"},
+		},
+		{
+			name:           "Update with missing fields",
+			method:         http.MethodPut,
+			body:           `{"name":"N"}`,
+			expectedCode:   problem.CodeValidationFailed,
+			expectedFields: []string{"author", "publication"},
+		},
+		{
+			name:         "Malformed JSON",
+			method:       http.MethodPut,
+			body:         `{"name":`,
+			expectedCode: problem.CodeInvalidBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBookService)
+			controller := NewBookController(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "bookID", Value: "1"}}
+			c.Request = httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			if tt.method == http.MethodPost {
+				controller.CreateBook(c)
+			} else {
+				controller.UpdateBookByID(c)
+			}
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var actualProblem problem.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualProblem))
+			assert.Equal(t, tt.expectedCode, actualProblem.Code)
+
+			fields := []string{}
+			for _, fieldError := range actualProblem.Errors {
+				fields = append(fields, fieldError.Field)
+				assert.NotEmpty(t, fieldError.Rule)
+				assert.NotEmpty(t, fieldError.Message)
+			}
+			assert.ElementsMatch(t, tt.expectedFields, fields)
+
+			mockService.AssertNotCalled(t, "CreateBook", mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "UpdateBookByID", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateBookTrimsFields(t *testing.T) {
+	mockService := new(MockBookService)
+	controller := NewBookController(mockService)
+
+	trimmed := bookservices.BookRequest{Name: "New Book", Author: "New Author", Publication: "New Publication"}
+	mockService.On("CreateBook", mock.Anything, trimmed).Return(bookservices.BookResponse{ID: 1}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"  New Book ","author":"New Author\n","publication":"\tNew Publication"}`))
+
+	controller.CreateBook(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateBookNegotiatesFormats(t *testing.T) {
+	request := bookservices.BookRequest{Name: "New Book", Author: "New Author", Publication: "New Publication"}
+	response := bookservices.BookResponse{ID: 1, Name: "New Book", Author: "New Author", Publication: "New Publication"}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		accept      string
+		expected    string
+	}{
+		{
+			name:        "XML in, XML out",
+			contentType: "application/xml",
+			body:        `<book><name>New Book</name><author>New Author</author><publication>New Publication</publication></book>`,
+			accept:      "application/xml",
+			expected:    "<book><id>1</id><name>New Book</name>",
+		},
+		{
+			name:        "YAML in, CSV out",
+			contentType: "application/yaml",
+			body:        "name: New Book\nauthor: New Author\npublication: New Publication\n",
+			accept:      "text/csv",
+			expected:    "id,name,author,publication,created_at,updated_at\n1,New Book,New Author,New Publication,,\n",
+		},
+		{
+			name:        "CSV in, YAML out",
+			contentType: "text/csv; charset=utf-8",
+			body:        "name,author,publication\nNew Book,New Author,New Publication\n",
+			accept:      "application/yaml",
+			expected:    "name: New Book\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBookService)
+			mockService.On("CreateBook", mock.Anything, request).Return(response, nil)
+			controller := NewBookController(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+			c.Request.Header.Set("Accept", tt.accept)
+
+			controller.CreateBook(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expected)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
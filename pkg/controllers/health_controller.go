@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/health"
+)
+
+type HealthController struct {
+	Health *health.Health
+}
+
+func NewHealthController(h *health.Health) *HealthController {
+	return &HealthController{
+		Health: h,
+	}
+}
+
+// Liveness only reports that the process is serving requests.
+func (hc *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+func (hc *HealthController) Readiness(c *gin.Context) {
+	report := hc.Health.Check(c.Request.Context())
+	if report.Status != health.StatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/health"
+)
+
+type stubChecker struct {
+	err error
+}
+
+func (sc stubChecker) Name() string {
+	return "database"
+}
+
+func (sc stubChecker) Check(ctx context.Context) error {
+	return sc.err
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name           string
+		checkErr       error
+		expectedStatus int
+	}{
+		{name: "Ready", expectedStatus: http.StatusOK},
+		{name: "Database down", checkErr: errors.New("connection refused"), expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewHealthController(health.New(time.Second, stubChecker{err: tt.checkErr}))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/readyz", nil)
+
+			controller.Readiness(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var report health.Report
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Contains(t, report.Checks, "database")
+		})
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	controller := NewHealthController(health.New(time.Second, stubChecker{err: errors.New("down")}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/healthz", nil)
+
+	controller.Liveness(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
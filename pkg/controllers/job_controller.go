@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	jobservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/job_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+const (
+	defaultJobLimit = 100
+	maxJobLimit     = 500
+)
+
+type JobController struct {
+	JobService jobservices.JobServicesInterface
+	// Notify, if set, wakes the workers after a job is retried.
+	Notify func()
+}
+
+func NewJobController(jobService jobservices.JobServicesInterface, notify func()) *JobController {
+	return &JobController{
+		JobService: jobService,
+		Notify:     notify,
+	}
+}
+
+// GetJobs lists jobs, newest first, optionally filtered by status and type.
+func (jc *JobController) GetJobs(c *gin.Context) {
+	filter := jobservices.JobFilter{
+		Status: c.Query("status"),
+		Type:   c.Query("type"),
+		Limit:  defaultJobLimit,
+	}
+	var fieldErrors []problem.FieldError
+	if filter.Status != "" && !isJobStatus(filter.Status) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "status", Rule: "oneof", Message: "status must be one of pending running succeeded failed cancelled"})
+	}
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > maxJobLimit {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: "limit", Rule: "range", Message: "limit must be between 1 and " + strconv.Itoa(maxJobLimit)})
+		}
+		filter.Limit = limit
+	}
+	if len(fieldErrors) > 0 {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "The query parameters are invalid.").WithErrors(fieldErrors...))
+		return
+	}
+
+	jobs, err := jc.JobService.GetJobs(c.Request.Context(), filter)
+	if err != nil {
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+func (jc *JobController) GetJobByID(c *gin.Context) {
+	jobID := c.Param("jobID")
+	if !isID(jobID) {
+		abortJobNotFound(c, jobID)
+		return
+	}
+	job, err := jc.JobService.GetJobByID(c.Request.Context(), jobID)
+	if err != nil {
+		jc.abortJobError(c, jobID, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJobByID queues a failed or cancelled job for a fresh round of
+// attempts.
+func (jc *JobController) RetryJobByID(c *gin.Context) {
+	jobID := c.Param("jobID")
+	if !isID(jobID) {
+		abortJobNotFound(c, jobID)
+		return
+	}
+	job, err := jc.JobService.RetryJobByID(c.Request.Context(), jobID)
+	if err != nil {
+		jc.abortJobError(c, jobID, err)
+		return
+	}
+	if jc.Notify != nil {
+		jc.Notify()
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// CancelJobByID cancels a job that has not started yet.
+func (jc *JobController) CancelJobByID(c *gin.Context) {
+	jobID := c.Param("jobID")
+	if !isID(jobID) {
+		abortJobNotFound(c, jobID)
+		return
+	}
+	job, err := jc.JobService.CancelJobByID(c.Request.Context(), jobID)
+	if err != nil {
+		jc.abortJobError(c, jobID, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func (jc *JobController) abortJobError(c *gin.Context, jobID string, err error) {
+	switch {
+	case errors.Is(err, jobservices.ErrJobNotFound):
+		abortJobNotFound(c, jobID)
+	case errors.Is(err, jobservices.ErrJobNotRetryable):
+		problem.Abort(c, problem.New(http.StatusConflict, problem.CodeJobNotRetryable, "Only failed or cancelled jobs can be retried."))
+	case errors.Is(err, jobservices.ErrJobNotCancellable):
+		problem.Abort(c, problem.New(http.StatusConflict, problem.CodeJobNotCancellable, "Only pending jobs can be cancelled."))
+	default:
+		problem.Internal(c, err)
+	}
+}
+
+func abortJobNotFound(c *gin.Context, jobID string) {
+	problem.Abort(c, problem.New(http.StatusNotFound, problem.CodeJobNotFound, "Job "+jobID+" was not found."))
+}
+
+func isJobStatus(status string) bool {
+	for _, s := range jobservices.JobStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
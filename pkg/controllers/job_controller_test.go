@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	jobservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/job_services"
+)
+
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) Enqueue(ctx context.Context, job jobservices.JobRequest) (jobservices.Job, error) {
+	args := m.Called(ctx, job)
+	return args.Get(0).(jobservices.Job), args.Error(1)
+}
+
+func (m *MockJobService) ClaimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]jobservices.Job, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]jobservices.Job), args.Error(1)
+}
+
+func (m *MockJobService) RecordResult(ctx context.Context, result jobservices.JobResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *MockJobService) GetJobs(ctx context.Context, filter jobservices.JobFilter) ([]jobservices.Job, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]jobservices.Job), args.Error(1)
+}
+
+func (m *MockJobService) GetJobByID(ctx context.Context, jobID string) (jobservices.Job, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(jobservices.Job), args.Error(1)
+}
+
+func (m *MockJobService) RetryJobByID(ctx context.Context, jobID string) (jobservices.Job, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(jobservices.Job), args.Error(1)
+}
+
+func (m *MockJobService) CancelJobByID(ctx context.Context, jobID string) (jobservices.Job, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(jobservices.Job), args.Error(1)
+}
+
+func (m *MockJobService) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newJobRouter(controller *JobController) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jobs", controller.GetJobs)
+	router.GET("/jobs/:jobID", controller.GetJobByID)
+	router.POST("/jobs/:jobID/retry", controller.RetryJobByID)
+	router.POST("/jobs/:jobID/cancel", controller.CancelJobByID)
+	return router
+}
+
+func TestGetJobs(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		filter         *jobservices.JobFilter
+		expectedStatus int
+	}{
+		{
+			name:           "Defaults",
+			filter:         &jobservices.JobFilter{Limit: defaultJobLimit},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Filtered",
+			query:          "?status=failed&type=jobs.prune&limit=20",
+			filter:         &jobservices.JobFilter{Status: jobservices.JobFailed, Type: "jobs.prune", Limit: 20},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Filters",
+			query:          "?status=lost&limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockJobService)
+			if tt.filter != nil {
+				mockService.On("GetJobs", mock.Anything, *tt.filter).Return([]jobservices.Job{{ID: 1}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			newJobRouter(NewJobController(mockService, nil)).ServeHTTP(w, httptest.NewRequest("GET", "/jobs"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				for _, field := range []string{"status", "limit"} {
+					assert.Contains(t, w.Body.String(), `"field":"`+field+`"`)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetJobByID(t *testing.T) {
+	mockService := new(MockJobService)
+	mockService.On("GetJobByID", mock.Anything, "9").Return(jobservices.Job{}, jobservices.ErrJobNotFound)
+
+	router := newJobRouter(NewJobController(mockService, nil))
+	for _, path := range []string{"/jobs/9", "/jobs/abc"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"job_not_found"`)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRetryAndCancelJobByID(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         string
+		mockError      error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Retry", action: "retry", expectedStatus: http.StatusAccepted},
+		{name: "Retry Not Failed", action: "retry", mockError: jobservices.ErrJobNotRetryable, expectedStatus: http.StatusConflict, expectedCode: "job_not_retryable"},
+		{name: "Retry Not Found", action: "retry", mockError: jobservices.ErrJobNotFound, expectedStatus: http.StatusNotFound, expectedCode: "job_not_found"},
+		{name: "Cancel", action: "cancel", expectedStatus: http.StatusOK},
+		{name: "Cancel Not Pending", action: "cancel", mockError: jobservices.ErrJobNotCancellable, expectedStatus: http.StatusConflict, expectedCode: "job_not_cancellable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockJobService)
+			method := map[string]string{"retry": "RetryJobByID", "cancel": "CancelJobByID"}[tt.action]
+			mockService.On(method, mock.Anything, "7").Return(jobservices.Job{ID: 7}, tt.mockError)
+			notified := false
+
+			w := httptest.NewRecorder()
+			router := newJobRouter(NewJobController(mockService, func() { notified = true }))
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/jobs/7/"+tt.action, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.action == "retry" && tt.mockError == nil, notified)
+			if tt.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+tt.expectedCode+`"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
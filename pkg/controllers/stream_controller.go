@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/stream"
+)
+
+// sseRetry is the reconnection delay suggested to EventSource clients.
+const sseRetry = 3 * time.Second
+
+// StreamController serves the live book feed. Heartbeat is how often an
+// idle connection is kept alive; a write that takes longer than
+// WriteTimeout drops the connection.
+type StreamController struct {
+	Broker       *stream.Broker
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+
+	upgrader websocket.Upgrader
+}
+
+// NewStreamController builds the controller. WebSocket handshakes are
+// accepted from allowedOrigins ("*" for any) and from the API's own origin.
+func NewStreamController(broker *stream.Broker, heartbeat, writeTimeout time.Duration, allowedOrigins []string) *StreamController {
+	return &StreamController{
+		Broker:       broker,
+		Heartbeat:    heartbeat,
+		WriteTimeout: writeTimeout,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: writeTimeout,
+			CheckOrigin:      checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// StreamBooks serves the feed as Server-Sent Events. EventSource sends the
+// Last-Event-ID header when it reconnects; clients that cannot set it may
+// pass ?last_event_id= instead.
+func (sc *StreamController) StreamBooks(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	sub := sc.Broker.Subscribe(lastEventID)
+	defer sub.Close()
+	metrics.StreamSubscribers.WithLabelValues("sse").Inc()
+	defer metrics.StreamSubscribers.WithLabelValues("sse").Dec()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	write := func(format string, args ...interface{}) error {
+		// Replaces the server's WriteTimeout, which would end the stream.
+		if err := rc.SetWriteDeadline(time.Now().Add(sc.WriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := write("retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return
+	}
+	heartbeat := time.NewTicker(sc.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case event := <-sub.Events():
+			err = write("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+		case <-heartbeat.C:
+			err = write(": keepalive\n\n")
+		case <-sub.Done():
+			sc.logEnd(c, "sse", sub.Err())
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wsMessage is one WebSocket text message of the feed.
+type wsMessage struct {
+	ID    string          `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// StreamBooksWebSocket serves the feed over a WebSocket. Each event is a
+// text message {"id", "event", "data"}; resume with ?last_event_id=. The
+// server pings every Heartbeat and drops peers that stop answering.
+func (sc *StreamController) StreamBooksWebSocket(c *gin.Context) {
+	conn, err := sc.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already answered with an HTTP error.
+		return
+	}
+	defer conn.Close()
+
+	sub := sc.Broker.Subscribe(c.Query("last_event_id"))
+	defer sub.Close()
+	metrics.StreamSubscribers.WithLabelValues("websocket").Inc()
+	defer metrics.StreamSubscribers.WithLabelValues("websocket").Dec()
+
+	// The feed is one-way; reading only processes pongs and the close
+	// handshake, and notices peers that went away.
+	pongWait := 2 * sc.Heartbeat
+	gone := make(chan struct{})
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	closeWith := func(code int, text string) {
+		msg := websocket.FormatCloseMessage(code, text)
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(sc.WriteTimeout))
+	}
+
+	heartbeat := time.NewTicker(sc.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case event := <-sub.Events():
+			_ = conn.SetWriteDeadline(time.Now().Add(sc.WriteTimeout))
+			err = conn.WriteJSON(wsMessage{ID: event.ID, Event: event.Type, Data: event.Data})
+		case <-heartbeat.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(sc.WriteTimeout))
+		case <-sub.Done():
+			sc.logEnd(c, "websocket", sub.Err())
+			if errors.Is(sub.Err(), stream.ErrSlowConsumer) {
+				closeWith(websocket.CloseTryAgainLater, "consumer too slow, resume with last_event_id")
+			} else {
+				closeWith(websocket.CloseGoingAway, "server shutting down")
+			}
+			return
+		case <-gone:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (sc *StreamController) logEnd(c *gin.Context, transport string, err error) {
+	if errors.Is(err, stream.ErrSlowConsumer) {
+		metrics.StreamSlowConsumersTotal.WithLabelValues(transport).Inc()
+		logger.FromContext(c.Request.Context()).Warn("stream subscriber fell behind, disconnecting", "transport", transport)
+	}
+}
+
+// checkOrigin allows the listed origins and same-origin requests. Clients
+// that send no Origin, i.e. non-browsers, are allowed.
+func checkOrigin(allowed []string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, o := range allowed {
+			if o == "*" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
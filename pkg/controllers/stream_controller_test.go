@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/stream"
+)
+
+func newStreamServer(t *testing.T, heartbeat time.Duration) (*stream.Broker, *httptest.Server) {
+	gin.SetMode(gin.TestMode)
+	broker := stream.NewBroker(stream.Options{History: 10, SubscriberBuffer: 10})
+	sc := NewStreamController(broker, heartbeat, time.Second, []string{"https://console.example"})
+
+	router := gin.New()
+	router.GET("/books/stream", sc.StreamBooks)
+	router.GET("/books/ws", sc.StreamBooksWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return broker, server
+}
+
+// readSSE returns the next event or comment block of an SSE stream.
+func readSSE(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return strings.Join(lines, "\n")
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func openSSE(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, "retry: 3000", readSSE(t, r))
+	return resp, r
+}
+
+func TestStreamBooks(t *testing.T) {
+	broker, server := newStreamServer(t, time.Hour)
+	_, r := openSSE(t, server.URL+"/books/stream", "")
+	assert.Eventually(t, func() bool { return broker.Subscribers() == 1 }, time.Second, time.Millisecond)
+
+	broker.Publish("evt_1", "book.created", []byte(`{"id":"evt_1"}`))
+	broker.Publish("evt_2", "book.deleted", []byte(`{"id":"evt_2"}`))
+
+	first := readSSE(t, r)
+	assert.Regexp(t, `^id: \S+-1\nevent: book.created\ndata: \{"id":"evt_1"\}$`, first)
+	second := readSSE(t, r)
+	assert.Contains(t, second, "event: book.deleted")
+
+	// A reconnecting client gets what it missed.
+	firstID := strings.TrimPrefix(strings.SplitN(first, "\n", 2)[0], "id: ")
+	_, resumed := openSSE(t, server.URL+"/books/stream", firstID)
+	assert.Equal(t, second, readSSE(t, resumed))
+
+	// Closing the broker ends the streams.
+	broker.Close()
+	_, err := io.ReadAll(r)
+	assert.NoError(t, err)
+}
+
+func TestStreamBooksHeartbeat(t *testing.T) {
+	_, server := newStreamServer(t, 10*time.Millisecond)
+	_, r := openSSE(t, server.URL+"/books/stream?last_event_id=unknown-1", "")
+
+	assert.Regexp(t, `^id: \S+-0\nevent: reset\ndata: \{\}$`, readSSE(t, r))
+	assert.Equal(t, ": keepalive", readSSE(t, r))
+}
+
+func TestStreamBooksWebSocket(t *testing.T) {
+	broker, server := newStreamServer(t, time.Hour)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/books/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://console.example"}})
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Eventually(t, func() bool { return broker.Subscribers() == 1 }, time.Second, time.Millisecond)
+
+	broker.Publish("evt_1", "book.updated", []byte(`{"id":"evt_1"}`))
+	var msg wsMessage
+	assert.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "book.updated", msg.Event)
+	assert.JSONEq(t, `{"id":"evt_1"}`, string(msg.Data))
+	assert.NotEmpty(t, msg.ID)
+
+	broker.Close()
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+}
+
+func TestStreamBooksWebSocketOrigin(t *testing.T) {
+	_, server := newStreamServer(t, time.Hour)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/books/ws"
+
+	_, resp, err := websocket.DefaultDialer.DialContext(context.Background(), wsURL, http.Header{"Origin": {"https://evil.example"}})
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	}
+
+	// The API's own origin is always allowed.
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {server.URL}})
+	assert.NoError(t, err)
+	conn.Close()
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a handler span and makes it the parent of everything the
+// handler calls through c.Request.Context().
+func startSpan(c *gin.Context, name string) trace.Span {
+	ctx, span := tracing.Tracer().Start(c.Request.Context(), name)
+	c.Request = c.Request.WithContext(ctx)
+	return span
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+)
+
+// bindBody decodes the request body into obj in the format named by
+// Content-Type and validates it, writing a problem response and returning
+// false when it is invalid.
+func bindBody(c *gin.Context, obj interface{}) bool {
+	f, err := negotiation.RequestFormat(c.Request)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMedia, err.Error()))
+		return false
+	}
+	return abortOnBindError(c, negotiation.Decode(c.Request.Body, f, obj))
+}
+
+// bindJSON is bindBody for endpoints that only speak JSON.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	return abortOnBindError(c, validation.DecodeJSON(c.Request.Body, obj))
+}
+
+func abortOnBindError(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "The request body is invalid.").WithErrors(validationErr.Fields...))
+		return false
+	}
+	problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
+	return false
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	webhookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/webhook_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+const (
+	defaultDeliveryLimit = 100
+	maxDeliveryLimit     = 500
+)
+
+type WebhookController struct {
+	WebhookService webhookservices.WebhookServicesInterface
+	// Notify, if set, wakes the dispatcher after a delivery is retried.
+	Notify func()
+}
+
+func NewWebhookController(webhookService webhookservices.WebhookServicesInterface, notify func()) *WebhookController {
+	return &WebhookController{
+		WebhookService: webhookService,
+		Notify:         notify,
+	}
+}
+
+func (wc *WebhookController) CreateSubscription(c *gin.Context) {
+	var subscriptionRequest webhookservices.SubscriptionRequest
+	if !bindJSON(c, &subscriptionRequest) {
+		return
+	}
+	subscription, err := wc.WebhookService.CreateSubscription(c.Request.Context(), subscriptionRequest)
+	if err != nil {
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (wc *WebhookController) GetAllSubscriptions(c *gin.Context) {
+	subscriptions, err := wc.WebhookService.GetAllSubscriptions(c.Request.Context())
+	if err != nil {
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (wc *WebhookController) GetSubscriptionByID(c *gin.Context) {
+	webhookID := c.Param("webhookID")
+	if !isID(webhookID) {
+		abortWebhookNotFound(c, webhookID)
+		return
+	}
+	subscription, err := wc.WebhookService.GetSubscriptionByID(c.Request.Context(), webhookID)
+	if err != nil {
+		wc.abortSubscriptionError(c, webhookID, err)
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (wc *WebhookController) UpdateSubscriptionByID(c *gin.Context) {
+	webhookID := c.Param("webhookID")
+	if !isID(webhookID) {
+		abortWebhookNotFound(c, webhookID)
+		return
+	}
+	var subscriptionRequest webhookservices.SubscriptionRequest
+	if !bindJSON(c, &subscriptionRequest) {
+		return
+	}
+	subscription, err := wc.WebhookService.UpdateSubscriptionByID(c.Request.Context(), webhookID, subscriptionRequest)
+	if err != nil {
+		wc.abortSubscriptionError(c, webhookID, err)
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (wc *WebhookController) DeleteSubscriptionByID(c *gin.Context) {
+	webhookID := c.Param("webhookID")
+	if !isID(webhookID) {
+		abortWebhookNotFound(c, webhookID)
+		return
+	}
+	if err := wc.WebhookService.DeleteSubscriptionByID(c.Request.Context(), webhookID); err != nil {
+		wc.abortSubscriptionError(c, webhookID, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetDeliveries is the delivery log, newest first, optionally filtered by
+// subscription_id and status.
+func (wc *WebhookController) GetDeliveries(c *gin.Context) {
+	filter := webhookservices.DeliveryFilter{
+		SubscriptionID: c.Query("subscription_id"),
+		Status:         c.Query("status"),
+		Limit:          defaultDeliveryLimit,
+	}
+	var fieldErrors []problem.FieldError
+	if filter.SubscriptionID != "" && !isID(filter.SubscriptionID) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "subscription_id", Rule: "number", Message: "subscription_id must be a positive integer"})
+	}
+	if filter.Status != "" && !isDeliveryStatus(filter.Status) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "status", Rule: "oneof", Message: "status must be one of pending succeeded dead_lettered"})
+	}
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > maxDeliveryLimit {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: "limit", Rule: "range", Message: "limit must be between 1 and " + strconv.Itoa(maxDeliveryLimit)})
+		}
+		filter.Limit = limit
+	}
+	if len(fieldErrors) > 0 {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "The query parameters are invalid.").WithErrors(fieldErrors...))
+		return
+	}
+
+	deliveries, err := wc.WebhookService.GetDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		problem.Internal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RetryDeliveryByID queues a dead-lettered delivery for a fresh round of
+// attempts.
+func (wc *WebhookController) RetryDeliveryByID(c *gin.Context) {
+	deliveryID := c.Param("deliveryID")
+	if !isID(deliveryID) {
+		problem.Abort(c, problem.New(http.StatusNotFound, problem.CodeDeliveryNotFound, "Webhook delivery "+deliveryID+" was not found."))
+		return
+	}
+	delivery, err := wc.WebhookService.RetryDeliveryByID(c.Request.Context(), deliveryID)
+	switch {
+	case errors.Is(err, webhookservices.ErrDeliveryNotFound):
+		problem.Abort(c, problem.New(http.StatusNotFound, problem.CodeDeliveryNotFound, "Webhook delivery "+deliveryID+" was not found."))
+		return
+	case errors.Is(err, webhookservices.ErrDeliveryNotDeadLettered):
+		problem.Abort(c, problem.New(http.StatusConflict, problem.CodeDeliveryNotFailed, "Only dead-lettered deliveries can be retried."))
+		return
+	case err != nil:
+		problem.Internal(c, err)
+		return
+	}
+	if wc.Notify != nil {
+		wc.Notify()
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (wc *WebhookController) abortSubscriptionError(c *gin.Context, webhookID string, err error) {
+	if errors.Is(err, webhookservices.ErrSubscriptionNotFound) {
+		abortWebhookNotFound(c, webhookID)
+		return
+	}
+	problem.Internal(c, err)
+}
+
+func abortWebhookNotFound(c *gin.Context, webhookID string) {
+	problem.Abort(c, problem.New(http.StatusNotFound, problem.CodeWebhookNotFound, "Webhook "+webhookID+" was not found."))
+}
+
+func isID(id string) bool {
+	n, err := strconv.ParseUint(id, 10, 64)
+	return err == nil && n > 0
+}
+
+func isDeliveryStatus(status string) bool {
+	for _, s := range webhookservices.DeliveryStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	webhookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/webhook_services"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateSubscription(ctx context.Context, subscription webhookservices.SubscriptionRequest) (webhookservices.CreatedSubscriptionResponse, error) {
+	args := m.Called(ctx, subscription)
+	return args.Get(0).(webhookservices.CreatedSubscriptionResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) GetAllSubscriptions(ctx context.Context) ([]webhookservices.SubscriptionResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]webhookservices.SubscriptionResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) GetSubscriptionByID(ctx context.Context, subscriptionID string) (webhookservices.SubscriptionResponse, error) {
+	args := m.Called(ctx, subscriptionID)
+	return args.Get(0).(webhookservices.SubscriptionResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateSubscriptionByID(ctx context.Context, subscriptionID string, subscription webhookservices.SubscriptionRequest) (webhookservices.SubscriptionResponse, error) {
+	args := m.Called(ctx, subscriptionID, subscription)
+	return args.Get(0).(webhookservices.SubscriptionResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteSubscriptionByID(ctx context.Context, subscriptionID string) error {
+	args := m.Called(ctx, subscriptionID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) EnqueueEvent(ctx context.Context, event webhookservices.Event) (int, error) {
+	args := m.Called(ctx, event)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookService) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhookservices.DueDelivery, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]webhookservices.DueDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) RecordAttempt(ctx context.Context, attempt webhookservices.DeliveryAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetDeliveries(ctx context.Context, filter webhookservices.DeliveryFilter) ([]webhookservices.Delivery, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]webhookservices.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookService) RetryDeliveryByID(ctx context.Context, deliveryID string) (webhookservices.Delivery, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).(webhookservices.Delivery), args.Error(1)
+}
+
+func newWebhookRouter(controller *WebhookController) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/webhooks", controller.CreateSubscription)
+	router.GET("/webhooks/:webhookID", controller.GetSubscriptionByID)
+	router.GET("/deliveries", controller.GetDeliveries)
+	router.POST("/deliveries/:deliveryID/retry", controller.RetryDeliveryByID)
+	return router
+}
+
+func TestCreateSubscription(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		callsService   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			body:           `{"url": " https://example.com/hook ", "events": ["book.created", "book.deleted"]}`,
+			callsService:   true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"secret":"whsec_generated"`,
+		},
+		{
+			name:           "Not An HTTP URL",
+			body:           `{"url": "ftp://example.com/hook", "events": ["book.created"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"field":"url"`,
+		},
+		{
+			name:           "Unknown Event",
+			body:           `{"url": "https://example.com/hook", "events": ["book.archived"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"field":"events[0]"`,
+		},
+		{
+			name:           "Short Secret",
+			body:           `{"url": "https://example.com/hook", "secret": "short", "events": ["book.created"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"field":"secret"`,
+		},
+		{
+			name:           "No Events",
+			body:           `{"url": "https://example.com/hook", "events": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"field":"events"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			if tt.callsService {
+				mockService.On("CreateSubscription", mock.Anything, webhookservices.SubscriptionRequest{URL: "https://example.com/hook", Events: []string{"book.created", "book.deleted"}}).
+					Return(webhookservices.CreatedSubscriptionResponse{SubscriptionResponse: webhookservices.SubscriptionResponse{ID: 1}, Secret: "whsec_generated"}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			newWebhookRouter(NewWebhookController(mockService, nil)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSubscriptionByID(t *testing.T) {
+	mockService := new(MockWebhookService)
+	mockService.On("GetSubscriptionByID", mock.Anything, "1").Return(webhookservices.SubscriptionResponse{ID: 1, URL: "https://example.com/hook"}, nil)
+	mockService.On("GetSubscriptionByID", mock.Anything, "2").Return(webhookservices.SubscriptionResponse{}, webhookservices.ErrSubscriptionNotFound)
+	router := newWebhookRouter(NewWebhookController(mockService, nil))
+
+	for path, status := range map[string]int{"/webhooks/1": http.StatusOK, "/webhooks/2": http.StatusNotFound, "/webhooks/abc": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, status, w.Code, path)
+		if status == http.StatusOK {
+			assert.NotContains(t, w.Body.String(), "secret")
+		} else {
+			assert.Contains(t, w.Body.String(), `"code":"webhook_not_found"`)
+		}
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetDeliveries(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		filter         *webhookservices.DeliveryFilter
+		expectedStatus int
+	}{
+		{
+			name:           "Defaults",
+			filter:         &webhookservices.DeliveryFilter{Limit: defaultDeliveryLimit},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Filtered",
+			query:          "?subscription_id=3&status=dead_lettered&limit=20",
+			filter:         &webhookservices.DeliveryFilter{SubscriptionID: "3", Status: webhookservices.DeliveryDeadLettered, Limit: 20},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Filters",
+			query:          "?subscription_id=x&status=lost&limit=1000",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			if tt.filter != nil {
+				mockService.On("GetDeliveries", mock.Anything, *tt.filter).Return([]webhookservices.Delivery{{ID: 1}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			newWebhookRouter(NewWebhookController(mockService, nil)).ServeHTTP(w, httptest.NewRequest("GET", "/deliveries"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				for _, field := range []string{"subscription_id", "status", "limit"} {
+					assert.Contains(t, w.Body.String(), `"field":"`+field+`"`)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRetryDeliveryByID(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Success", expectedStatus: http.StatusAccepted},
+		{name: "Not Found", mockError: webhookservices.ErrDeliveryNotFound, expectedStatus: http.StatusNotFound, expectedCode: "webhook_delivery_not_found"},
+		{name: "Not Dead-Lettered", mockError: webhookservices.ErrDeliveryNotDeadLettered, expectedStatus: http.StatusConflict, expectedCode: "webhook_delivery_not_dead_lettered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			mockService.On("RetryDeliveryByID", mock.Anything, "7").Return(webhookservices.Delivery{ID: 7, Status: webhookservices.DeliveryPending}, tt.mockError)
+			notified := false
+
+			w := httptest.NewRecorder()
+			router := newWebhookRouter(NewWebhookController(mockService, func() { notified = true }))
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/deliveries/7/retry", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.mockError == nil, notified)
+			if tt.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+tt.expectedCode+`"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
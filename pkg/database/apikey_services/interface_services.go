@@ -0,0 +1,10 @@
+package apikeyservices
+
+import "context"
+
+type APIKeyServicesInterface interface {
+	IssueAPIKey(ctx context.Context, apiKey APIKeyRequest) (IssuedAPIKeyResponse, error)
+	GetAllAPIKeys(ctx context.Context) ([]APIKeyResponse, error)
+	RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (APIKeyResponse, error)
+}
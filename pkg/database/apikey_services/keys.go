@@ -0,0 +1,55 @@
+package apikeyservices
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const (
+	keyPrefix    = "bks_"
+	prefixLength = 12
+)
+
+var (
+	ErrAPIKeyInvalid  = errors.New("invalid api key")
+	ErrAPIKeyExpired  = errors.New("api key expired")
+	ErrAPIKeyRevoked  = errors.New("api key revoked")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// GenerateAPIKey returns a new random key and the prefix stored for display.
+func GenerateAPIKey() (string, string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	key := keyPrefix + hex.EncodeToString(buf)
+	return key, key[:prefixLength], nil
+}
+
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		valid := false
+		for _, available := range AvailableScopes {
+			if scope == available {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package apikeyservices
+
+import "time"
+
+const (
+	ScopeBooksRead   = "books:read"
+	ScopeBooksWrite  = "books:write"
+	ScopeOrdersWrite = "orders:write"
+	ScopeAdmin       = "admin"
+)
+
+var AvailableScopes = []string{ScopeBooksRead, ScopeBooksWrite, ScopeOrdersWrite, ScopeAdmin}
+
+type APIKey struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IssuedAPIKeyResponse is only returned once, when the key is issued.
+// The plaintext key is never stored.
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func (r APIKeyResponse) HasScope(scope string) bool {
+	for _, s := range r.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package apikeyservices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+type APIKeyServicesPostgres struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyServicesPostgres(db *sql.DB) *APIKeyServicesPostgres {
+	return &APIKeyServicesPostgres{
+		DB: db,
+	}
+}
+
+func (asp *APIKeyServicesPostgres) IssueAPIKey(ctx context.Context, apiKey APIKeyRequest) (IssuedAPIKeyResponse, error) {
+	if apiKey.Name == "" {
+		return IssuedAPIKeyResponse{}, errors.New("name is required")
+	}
+	if err := ValidateScopes(apiKey.Scopes); err != nil {
+		return IssuedAPIKeyResponse{}, err
+	}
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now()) {
+		return IssuedAPIKeyResponse{}, errors.New("expires_at must be in the future")
+	}
+
+	rawKey, prefix, err := GenerateAPIKey()
+	if err != nil {
+		return IssuedAPIKeyResponse{}, err
+	}
+
+	var issued IssuedAPIKeyResponse
+	query := "INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at"
+	err = asp.DB.QueryRowContext(ctx, query, apiKey.Name, prefix, HashAPIKey(rawKey), pq.Array(apiKey.Scopes), apiKey.ExpiresAt, time.Now()).
+		Scan(&issued.ID, &issued.Name, &issued.Prefix, pq.Array(&issued.Scopes), &issued.ExpiresAt, &issued.LastUsedAt, &issued.RevokedAt, &issued.CreatedAt)
+	if err != nil {
+		return IssuedAPIKeyResponse{}, err
+	}
+	issued.Key = rawKey
+	logger.FromContext(ctx).Info("api key issued", "api_key_id", issued.ID, "prefix", issued.Prefix, "scopes", issued.Scopes)
+	return issued, nil
+}
+
+func (asp *APIKeyServicesPostgres) GetAllAPIKeys(ctx context.Context) ([]APIKeyResponse, error) {
+	var apiKeys []APIKeyResponse
+	rows, err := asp.DB.QueryContext(ctx, "SELECT id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var apiKey APIKeyResponse
+		if err := rows.Scan(&apiKey.ID, &apiKey.Name, &apiKey.Prefix, pq.Array(&apiKey.Scopes), &apiKey.ExpiresAt, &apiKey.LastUsedAt, &apiKey.RevokedAt, &apiKey.CreatedAt); err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, rows.Err()
+}
+
+func (asp *APIKeyServicesPostgres) RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error {
+	query := "UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL"
+	result, err := asp.DB.ExecContext(ctx, query, time.Now(), apiKeyID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	logger.FromContext(ctx).Info("api key revoked", "api_key_id", apiKeyID)
+	return nil
+}
+
+func (asp *APIKeyServicesPostgres) AuthenticateAPIKey(ctx context.Context, rawKey string) (APIKeyResponse, error) {
+	var apiKey APIKeyResponse
+	query := "SELECT id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE key_hash = $1"
+	err := asp.DB.QueryRowContext(ctx, query, HashAPIKey(rawKey)).
+		Scan(&apiKey.ID, &apiKey.Name, &apiKey.Prefix, pq.Array(&apiKey.Scopes), &apiKey.ExpiresAt, &apiKey.LastUsedAt, &apiKey.RevokedAt, &apiKey.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return APIKeyResponse{}, ErrAPIKeyInvalid
+		}
+		return APIKeyResponse{}, err
+	}
+	if apiKey.RevokedAt != nil {
+		return APIKeyResponse{}, ErrAPIKeyRevoked
+	}
+	now := time.Now()
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return APIKeyResponse{}, ErrAPIKeyExpired
+	}
+
+	if _, err := asp.DB.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", now, apiKey.ID); err != nil {
+		return APIKeyResponse{}, err
+	}
+	apiKey.LastUsedAt = &now
+	logger.FromContext(ctx).Debug("api key authenticated", "api_key_id", apiKey.ID)
+	return apiKey, nil
+}
@@ -0,0 +1,175 @@
+package apikeyservices
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyColumns = []string{"id", "name", "prefix", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at"}
+
+func TestIssueAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name      string
+		request   APIKeyRequest
+		expectSQL bool
+		sqlErr    error
+		wantErr   bool
+	}{
+		{
+			name:      "IssueAPIKey_Success",
+			request:   APIKeyRequest{Name: "scanner", Scopes: []string{ScopeBooksRead}},
+			expectSQL: true,
+		},
+		{
+			name:    "IssueAPIKey_UnknownScope",
+			request: APIKeyRequest{Name: "scanner", Scopes: []string{"books:delete"}},
+			wantErr: true,
+		},
+		{
+			name:    "IssueAPIKey_ExpiredAlready",
+			request: APIKeyRequest{Name: "scanner", Scopes: []string{ScopeBooksRead}, ExpiresAt: &past},
+			wantErr: true,
+		},
+		{
+			name:      "IssueAPIKey_Failure",
+			request:   APIKeyRequest{Name: "scanner", Scopes: []string{ScopeBooksRead}},
+			expectSQL: true,
+			sqlErr:    errors.New("insert error"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			asp := NewAPIKeyServicesPostgres(db)
+
+			if tt.expectSQL {
+				expectation := mock.ExpectQuery("INSERT INTO api_keys").
+					WithArgs(tt.request.Name, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg())
+				if tt.sqlErr != nil {
+					expectation.WillReturnError(tt.sqlErr)
+				} else {
+					expectation.WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+						AddRow(1, tt.request.Name, "bks_01234567", "{books:read}", nil, nil, nil, time.Now()))
+				}
+			}
+
+			issued, err := asp.IssueAPIKey(context.Background(), tt.request)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(issued.Key, keyPrefix))
+				assert.Equal(t, []string{ScopeBooksRead}, issued.Scopes)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAllAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	asp := NewAPIKeyServicesPostgres(db)
+
+	mock.ExpectQuery("SELECT id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, "scanner", "bks_01234567", "{books:read,books:write}", nil, nil, nil, time.Now()))
+
+	apiKeys, err := asp.GetAllAPIKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 1)
+	assert.Equal(t, []string{ScopeBooksRead, ScopeBooksWrite}, apiKeys[0].Scopes)
+}
+
+func TestRevokeAPIKeyByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "RevokeAPIKeyByID_Success", affected: 1},
+		{name: "RevokeAPIKeyByID_NotFound", affected: 0, wantErr: ErrAPIKeyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			asp := NewAPIKeyServicesPostgres(db)
+
+			mock.ExpectExec("UPDATE api_keys SET revoked_at").
+				WithArgs(sqlmock.AnyArg(), "1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err = asp.RevokeAPIKeyByID(context.Background(), "1")
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name      string
+		expiresAt interface{}
+		revokedAt interface{}
+		noRows    bool
+		wantErr   error
+	}{
+		{name: "AuthenticateAPIKey_Success"},
+		{name: "AuthenticateAPIKey_Unknown", noRows: true, wantErr: ErrAPIKeyInvalid},
+		{name: "AuthenticateAPIKey_Expired", expiresAt: past, wantErr: ErrAPIKeyExpired},
+		{name: "AuthenticateAPIKey_Revoked", revokedAt: past, wantErr: ErrAPIKeyRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			asp := NewAPIKeyServicesPostgres(db)
+			rawKey := "bks_secret"
+
+			rows := sqlmock.NewRows(apiKeyColumns)
+			if !tt.noRows {
+				rows.AddRow(1, "scanner", "bks_secret", "{books:read}", tt.expiresAt, nil, tt.revokedAt, time.Now())
+			}
+			mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash = \\$1").
+				WithArgs(HashAPIKey(rawKey)).
+				WillReturnRows(rows)
+			if tt.wantErr == nil {
+				mock.ExpectExec("UPDATE api_keys SET last_used_at").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			apiKey, err := asp.AuthenticateAPIKey(context.Background(), rawKey)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, apiKey.LastUsedAt)
+				assert.True(t, apiKey.HasScope(ScopeBooksRead))
+				assert.False(t, apiKey.HasScope(ScopeBooksWrite))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
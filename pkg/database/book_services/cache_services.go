@@ -0,0 +1,189 @@
+package bookservices
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cache"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+const (
+	cacheName        = "books"
+	cacheKeyPrefix   = "books:"
+	cacheKeyAllBooks = cacheKeyPrefix + "all"
+	cacheKeyBookByID = cacheKeyPrefix + "id:"
+)
+
+// BookServicesCache caches GetBookByID, GetBooksByIDs and GetAllBooks and
+// invalidates the affected entries on every successful mutation. Cache
+// failures are logged and the call falls through to the wrapped service.
+type BookServicesCache struct {
+	BookServices BookServicesInterface
+	Store        cache.Store
+	TTL          time.Duration
+}
+
+func NewBookServicesCache(bs BookServicesInterface, store cache.Store, ttl time.Duration) *BookServicesCache {
+	return &BookServicesCache{
+		BookServices: bs,
+		Store:        store,
+		TTL:          ttl,
+	}
+}
+
+func (bsc *BookServicesCache) CreateBook(ctx context.Context, book BookRequest) (BookResponse, error) {
+	bookResponse, err := bsc.BookServices.CreateBook(ctx, book)
+	if err == nil {
+		bsc.invalidate(ctx, cacheKeyAllBooks)
+	}
+	return bookResponse, err
+}
+
+func (bsc *BookServicesCache) GetAllBooks(ctx context.Context) ([]BookResponse, error) {
+	var books []BookResponse
+	if bsc.get(ctx, cacheKeyAllBooks, &books) {
+		return books, nil
+	}
+	books, err := bsc.BookServices.GetAllBooks(ctx)
+	if err == nil {
+		bsc.set(ctx, cacheKeyAllBooks, books)
+	}
+	return books, err
+}
+
+func (bsc *BookServicesCache) GetBookByID(ctx context.Context, bookID string) (BookResponse, error) {
+	key, ok := bookKey(bookID)
+	if !ok {
+		return bsc.BookServices.GetBookByID(ctx, bookID)
+	}
+	var book BookResponse
+	if bsc.get(ctx, key, &book) {
+		return book, nil
+	}
+	book, err := bsc.BookServices.GetBookByID(ctx, bookID)
+	if err == nil {
+		bsc.set(ctx, key, book)
+	}
+	return book, err
+}
+
+// GetBooksByIDs serves the books it has cached and fetches the rest in one
+// call, caching them individually.
+func (bsc *BookServicesCache) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]BookResponse, error) {
+	var books []BookResponse
+	var missing []string
+	for _, bookID := range bookIDs {
+		var book BookResponse
+		if key, ok := bookKey(bookID); ok && bsc.get(ctx, key, &book) {
+			books = append(books, book)
+			continue
+		}
+		missing = append(missing, bookID)
+	}
+	if len(missing) == 0 {
+		return books, nil
+	}
+	fetched, err := bsc.BookServices.GetBooksByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, book := range fetched {
+		bsc.set(ctx, cacheKeyBookByID+strconv.FormatUint(uint64(book.ID), 10), book)
+	}
+	return append(books, fetched...), nil
+}
+
+func (bsc *BookServicesCache) UpdateBookByID(ctx context.Context, bookID string, book BookUpdateRequest) (BookResponse, error) {
+	bookResponse, err := bsc.BookServices.UpdateBookByID(ctx, bookID, book)
+	if err == nil {
+		bsc.Invalidate(ctx, bookID)
+	}
+	return bookResponse, err
+}
+
+func (bsc *BookServicesCache) DeleteBookByID(ctx context.Context, bookID string) error {
+	err := bsc.BookServices.DeleteBookByID(ctx, bookID)
+	if err == nil {
+		bsc.Invalidate(ctx, bookID)
+	}
+	return err
+}
+
+// Invalidate drops the cached list and the given books. It is exported for
+// callers that learn about changes made elsewhere, e.g. by other replicas.
+func (bsc *BookServicesCache) Invalidate(ctx context.Context, bookIDs ...string) {
+	keys := []string{cacheKeyAllBooks}
+	for _, bookID := range bookIDs {
+		if key, ok := bookKey(bookID); ok {
+			keys = append(keys, key)
+		}
+	}
+	bsc.invalidate(ctx, keys...)
+}
+
+// bookKey is the cache key of one book. The ID is parsed so that spellings
+// such as "01" share the entry of "1" and are invalidated with it; anything
+// that is not an ID is not cached.
+func bookKey(bookID string) (string, bool) {
+	id, err := strconv.ParseUint(bookID, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	return cacheKeyBookByID + strconv.FormatUint(id, 10), true
+}
+
+// PublishBookEvent implements EventPublisher: a change made by another
+// replica invalidates the book here too.
+func (bsc *BookServicesCache) PublishBookEvent(ctx context.Context, event BookEvent) error {
+	bsc.Invalidate(ctx, strconv.FormatUint(uint64(event.BookID), 10))
+	return nil
+}
+
+// Resync drops every cached book and list after changes may have been
+// missed.
+func (bsc *BookServicesCache) Resync(ctx context.Context) error {
+	if err := bsc.Store.DeletePrefix(ctx, cacheKeyPrefix); err != nil {
+		metrics.CacheErrorsTotal.WithLabelValues(cacheName, "delete").Inc()
+		return err
+	}
+	return nil
+}
+
+func (bsc *BookServicesCache) get(ctx context.Context, key string, v interface{}) bool {
+	data, found, err := bsc.Store.Get(ctx, key)
+	if err != nil {
+		metrics.CacheErrorsTotal.WithLabelValues(cacheName, "get").Inc()
+		logger.FromContext(ctx).Warn("cache get failed", "key", key, "error", err)
+	}
+	if found && err == nil {
+		if err := json.Unmarshal(data, v); err == nil {
+			metrics.CacheRequestsTotal.WithLabelValues(cacheName, "hit").Inc()
+			return true
+		}
+		logger.FromContext(ctx).Warn("cache entry is corrupt", "key", key)
+	}
+	metrics.CacheRequestsTotal.WithLabelValues(cacheName, "miss").Inc()
+	return false
+}
+
+func (bsc *BookServicesCache) set(ctx context.Context, key string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = bsc.Store.Set(ctx, key, data, bsc.TTL)
+	}
+	if err != nil {
+		metrics.CacheErrorsTotal.WithLabelValues(cacheName, "set").Inc()
+		logger.FromContext(ctx).Warn("cache set failed", "key", key, "error", err)
+	}
+}
+
+func (bsc *BookServicesCache) invalidate(ctx context.Context, keys ...string) {
+	if err := bsc.Store.Delete(ctx, keys...); err != nil {
+		metrics.CacheErrorsTotal.WithLabelValues(cacheName, "delete").Inc()
+		logger.FromContext(ctx).Warn("cache invalidation failed", "keys", keys, "error", err)
+	}
+}
@@ -0,0 +1,186 @@
+package bookservices
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cache"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+func TestBookServicesCache(t *testing.T) {
+	stores := map[string]func(t *testing.T) cache.Store{
+		"Memory": func(t *testing.T) cache.Store {
+			return cache.NewMemoryStore(100)
+		},
+		"Redis": func(t *testing.T) cache.Store {
+			client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			t.Cleanup(func() { client.Close() })
+			return cache.NewRedisStore(client, "test:")
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			mockService := new(MockBookServices)
+			bsc := NewBookServicesCache(mockService, newStore(t), time.Minute)
+
+			book := BookResponse{ID: 1, Name: "Cached", CreatedAt: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)}
+			mockService.On("GetBookByID", mock.Anything, "1").Return(book, nil).Twice()
+			mockService.On("GetAllBooks", mock.Anything).Return([]BookResponse{book}, nil).Twice()
+			mockService.On("UpdateBookByID", mock.Anything, "1", mock.Anything).Return(book, nil).Once()
+
+			hits := testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues("books", "hit"))
+			misses := testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues("books", "miss"))
+
+			// First calls miss and fill the cache, repeats are served from it.
+			for i := 0; i < 2; i++ {
+				got, err := bsc.GetBookByID(ctx, "1")
+				assert.NoError(t, err)
+				assert.Equal(t, book, got)
+
+				books, err := bsc.GetAllBooks(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, []BookResponse{book}, books)
+			}
+			assert.Equal(t, hits+2, testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues("books", "hit")))
+			assert.Equal(t, misses+2, testutil.ToFloat64(metrics.CacheRequestsTotal.WithLabelValues("books", "miss")))
+
+			// An update invalidates the book and the list.
+			_, err := bsc.UpdateBookByID(ctx, "1", BookUpdateRequest{Name: "Cached"})
+			assert.NoError(t, err)
+			_, err = bsc.GetBookByID(ctx, "1")
+			assert.NoError(t, err)
+			_, err = bsc.GetAllBooks(ctx)
+			assert.NoError(t, err)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBookServicesCacheDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockBookServices)
+	bsc := NewBookServicesCache(mockService, cache.NewMemoryStore(100), time.Minute)
+
+	mockService.On("GetBookByID", mock.Anything, "9").Return(BookResponse{}, errors.New("not found")).Twice()
+
+	_, err := bsc.GetBookByID(ctx, "9")
+	assert.Error(t, err)
+	_, err = bsc.GetBookByID(ctx, "9")
+	assert.Error(t, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestBookServicesCacheCanonicalKeys(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockBookServices)
+	bsc := NewBookServicesCache(mockService, cache.NewMemoryStore(100), time.Minute)
+
+	mockService.On("GetBookByID", mock.Anything, "01").Return(BookResponse{ID: 1}, nil).Twice()
+	mockService.On("GetBookByID", mock.Anything, "abc").Return(BookResponse{}, errors.New("invalid input syntax")).Twice()
+
+	// "01" and "1" share one entry, so the event for book 1 evicts it.
+	_, err := bsc.GetBookByID(ctx, "01")
+	assert.NoError(t, err)
+	_, err = bsc.GetBookByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.NoError(t, bsc.PublishBookEvent(ctx, BookEvent{Type: EventBookUpdated, BookID: 1}))
+	_, err = bsc.GetBookByID(ctx, "01")
+	assert.NoError(t, err)
+
+	// Anything that is not an ID goes straight to the service.
+	for i := 0; i < 2; i++ {
+		_, err = bsc.GetBookByID(ctx, "abc")
+		assert.Error(t, err)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestBookServicesCacheGetBooksByIDs(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockBookServices)
+	bsc := NewBookServicesCache(mockService, cache.NewMemoryStore(100), time.Minute)
+
+	mockService.On("GetBookByID", mock.Anything, "1").Return(BookResponse{ID: 1}, nil).Once()
+	mockService.On("GetBooksByIDs", mock.Anything, []string{"2", "3"}).Return([]BookResponse{{ID: 2}}, nil).Once()
+	mockService.On("GetBooksByIDs", mock.Anything, []string{"3"}).Return([]BookResponse(nil), nil).Once()
+
+	// Cached books are served from the cache and only the rest is fetched,
+	// then cached in turn; missing books are fetched every time.
+	_, err := bsc.GetBookByID(ctx, "1")
+	assert.NoError(t, err)
+	books, err := bsc.GetBooksByIDs(ctx, []string{"1", "2", "3"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []BookResponse{{ID: 1}, {ID: 2}}, books)
+	books, err = bsc.GetBooksByIDs(ctx, []string{"1", "2", "3"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []BookResponse{{ID: 1}, {ID: 2}}, books)
+	mockService.AssertExpectations(t)
+}
+
+func TestBookServicesCacheResync(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockBookServices)
+	bsc := NewBookServicesCache(mockService, cache.NewMemoryStore(100), time.Minute)
+
+	mockService.On("GetBookByID", mock.Anything, "1").Return(BookResponse{ID: 1}, nil).Twice()
+	mockService.On("GetAllBooks", mock.Anything).Return([]BookResponse{{ID: 1}}, nil).Twice()
+
+	// After a resync both the book and the list are read from the service
+	// again.
+	for i := 0; i < 2; i++ {
+		_, err := bsc.GetBookByID(ctx, "1")
+		assert.NoError(t, err)
+		_, err = bsc.GetAllBooks(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, bsc.Resync(ctx))
+	}
+	mockService.AssertExpectations(t)
+
+	assert.Error(t, NewBookServicesCache(mockService, failingStore{}, time.Minute).Resync(ctx))
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Delete(context.Context, ...string) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) DeletePrefix(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func TestBookServicesCacheFallsThroughOnStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockBookServices)
+	bsc := NewBookServicesCache(mockService, failingStore{}, time.Minute)
+
+	mockService.On("GetBookByID", mock.Anything, "1").Return(BookResponse{ID: 1}, nil)
+	mockService.On("DeleteBookByID", mock.Anything, "1").Return(nil)
+
+	errorsBefore := testutil.ToFloat64(metrics.CacheErrorsTotal.WithLabelValues("books", "get"))
+
+	book, err := bsc.GetBookByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), book.ID)
+	assert.NoError(t, bsc.DeleteBookByID(ctx, "1"))
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(metrics.CacheErrorsTotal.WithLabelValues("books", "get")))
+}
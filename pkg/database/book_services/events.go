@@ -0,0 +1,108 @@
+package bookservices
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/outbox"
+)
+
+const (
+	EventBookCreated = "book.created"
+	EventBookUpdated = "book.updated"
+	EventBookDeleted = "book.deleted"
+
+	// AggregateBook is the outbox aggregate type of book events; the
+	// aggregate ID is the book ID.
+	AggregateBook = "book"
+)
+
+var AvailableEvents = []string{EventBookCreated, EventBookUpdated, EventBookDeleted}
+
+// BookEvent describes a committed change to a book. Book is nil for
+// book.deleted, which only carries the ID.
+type BookEvent struct {
+	ID         string
+	Type       string
+	BookID     uint
+	Book       *BookResponse
+	OccurredAt time.Time
+}
+
+// EventPublisher receives book events, e.g. to deliver them as webhooks.
+type EventPublisher interface {
+	PublishBookEvent(ctx context.Context, event BookEvent) error
+}
+
+// NewEventID returns a random identifier receivers can use to de-duplicate
+// events.
+func NewEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
+
+// newOutboxMessage records a book event. The payload is the book, or only
+// its ID for deletions.
+func newOutboxMessage(eventType string, bookID uint, book *BookResponse) (outbox.Message, error) {
+	var data interface{} = map[string]uint{"id": bookID}
+	if book != nil {
+		data = book
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return outbox.Message{}, err
+	}
+	return outbox.Message{
+		EventID:       NewEventID(),
+		AggregateType: AggregateBook,
+		AggregateID:   strconv.FormatUint(uint64(bookID), 10),
+		EventType:     eventType,
+		Payload:       payload,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
+
+// OutboxPublisher turns the book messages relayed from the outbox back into
+// BookEvents for each of publishers. Messages of other aggregates are
+// skipped. If any publisher fails the message is retried for all of them,
+// so publishers see an event at least once.
+func OutboxPublisher(publishers ...EventPublisher) outbox.Publisher {
+	return outbox.PublisherFunc(func(ctx context.Context, msg outbox.Message) error {
+		if msg.AggregateType != AggregateBook {
+			return nil
+		}
+		event, err := bookEventFromMessage(msg)
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for _, publisher := range publishers {
+			if err := publisher.PublishBookEvent(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func bookEventFromMessage(msg outbox.Message) (BookEvent, error) {
+	bookID, err := strconv.ParseUint(msg.AggregateID, 10, 64)
+	if err != nil {
+		return BookEvent{}, err
+	}
+	event := BookEvent{ID: msg.EventID, Type: msg.EventType, BookID: uint(bookID), OccurredAt: msg.OccurredAt}
+	if msg.EventType != EventBookDeleted {
+		var book BookResponse
+		if err := json.Unmarshal(msg.Payload, &book); err != nil {
+			return BookEvent{}, err
+		}
+		event.Book = &book
+	}
+	return event, nil
+}
@@ -0,0 +1,53 @@
+package bookservices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/outbox"
+)
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) PublishBookEvent(ctx context.Context, event BookEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func TestOutboxPublisher(t *testing.T) {
+	publisher := new(MockEventPublisher)
+	feed := new(MockEventPublisher)
+	relayed := OutboxPublisher(publisher, feed)
+	ctx := context.Background()
+
+	created, err := newOutboxMessage(EventBookCreated, 1, &BookResponse{ID: 1, Name: "Dune"})
+	assert.NoError(t, err)
+	deleted, err := newOutboxMessage(EventBookDeleted, 1, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":1}`, string(deleted.Payload))
+	assert.NotEqual(t, created.EventID, deleted.EventID)
+
+	publisher.On("PublishBookEvent", mock.Anything, mock.MatchedBy(func(e BookEvent) bool {
+		return e.Type == EventBookCreated && e.ID == created.EventID && e.BookID == 1 && e.Book.Name == "Dune"
+	})).Return(nil).Once()
+	publisher.On("PublishBookEvent", mock.Anything, mock.MatchedBy(func(e BookEvent) bool {
+		return e.Type == EventBookDeleted && e.BookID == 1 && e.Book == nil
+	})).Return(errors.New("receiver unavailable")).Once()
+
+	// Every publisher gets each event, even when another one fails.
+	feed.On("PublishBookEvent", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	assert.NoError(t, relayed.Publish(ctx, created))
+	// The error is returned so the relay retries the message.
+	assert.Error(t, relayed.Publish(ctx, deleted))
+	// Messages of other aggregates are not book events.
+	assert.NoError(t, relayed.Publish(ctx, outbox.Message{AggregateType: "author", AggregateID: "7", EventType: "author.created"}))
+
+	publisher.AssertExpectations(t)
+	feed.AssertExpectations(t)
+}
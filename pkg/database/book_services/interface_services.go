@@ -1,9 +1,14 @@
 package bookservices
 
+import "context"
+
 type BookServicesInterface interface {
-	CreateBook(book BookRequest) (BookResponse, error)
-	GetAllBooks() ([]BookResponse, error)
-	GetBookByID(bookID string) (BookResponse, error)
-	UpdateBookByID(bookID string, book BookUpdateRequest) (BookResponse, error)
-	DeleteBookByID(bookID string) error
+	CreateBook(ctx context.Context, book BookRequest) (BookResponse, error)
+	GetAllBooks(ctx context.Context) ([]BookResponse, error)
+	GetBookByID(ctx context.Context, bookID string) (BookResponse, error)
+	// GetBooksByIDs returns the books with the given IDs in no particular
+	// order. IDs that match no book are left out.
+	GetBooksByIDs(ctx context.Context, bookIDs []string) ([]BookResponse, error)
+	UpdateBookByID(ctx context.Context, bookID string, book BookUpdateRequest) (BookResponse, error)
+	DeleteBookByID(ctx context.Context, bookID string) error
 }
@@ -0,0 +1,54 @@
+package bookservices
+
+import (
+	"context"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+// BookServicesMetrics counts successful book mutations for /metrics.
+type BookServicesMetrics struct {
+	BookServices BookServicesInterface
+}
+
+func NewBookServicesMetrics(bs BookServicesInterface) *BookServicesMetrics {
+	return &BookServicesMetrics{
+		BookServices: bs,
+	}
+}
+
+func (bsm *BookServicesMetrics) CreateBook(ctx context.Context, book BookRequest) (BookResponse, error) {
+	bookResponse, err := bsm.BookServices.CreateBook(ctx, book)
+	if err == nil {
+		metrics.BooksCreatedTotal.Inc()
+	}
+	return bookResponse, err
+}
+
+func (bsm *BookServicesMetrics) GetAllBooks(ctx context.Context) ([]BookResponse, error) {
+	return bsm.BookServices.GetAllBooks(ctx)
+}
+
+func (bsm *BookServicesMetrics) GetBookByID(ctx context.Context, bookID string) (BookResponse, error) {
+	return bsm.BookServices.GetBookByID(ctx, bookID)
+}
+
+func (bsm *BookServicesMetrics) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]BookResponse, error) {
+	return bsm.BookServices.GetBooksByIDs(ctx, bookIDs)
+}
+
+func (bsm *BookServicesMetrics) UpdateBookByID(ctx context.Context, bookID string, book BookUpdateRequest) (BookResponse, error) {
+	bookResponse, err := bsm.BookServices.UpdateBookByID(ctx, bookID, book)
+	if err == nil {
+		metrics.BooksUpdatedTotal.Inc()
+	}
+	return bookResponse, err
+}
+
+func (bsm *BookServicesMetrics) DeleteBookByID(ctx context.Context, bookID string) error {
+	err := bsm.BookServices.DeleteBookByID(ctx, bookID)
+	if err == nil {
+		metrics.BooksDeletedTotal.Inc()
+	}
+	return err
+}
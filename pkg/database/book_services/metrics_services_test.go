@@ -0,0 +1,35 @@
+package bookservices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+func TestBookServicesMetrics(t *testing.T) {
+	mockService := new(MockBookServices)
+	bsm := NewBookServicesMetrics(mockService)
+	ctx := context.Background()
+
+	created := testutil.ToFloat64(metrics.BooksCreatedTotal)
+	deleted := testutil.ToFloat64(metrics.BooksDeletedTotal)
+
+	mockService.On("CreateBook", mock.Anything, BookRequest{Name: "ok"}).Return(BookResponse{ID: 1}, nil)
+	mockService.On("CreateBook", mock.Anything, BookRequest{Name: "fail"}).Return(BookResponse{}, errors.New("insert error"))
+	mockService.On("DeleteBookByID", mock.Anything, "1").Return(nil)
+
+	_, err := bsm.CreateBook(ctx, BookRequest{Name: "ok"})
+	assert.NoError(t, err)
+	_, err = bsm.CreateBook(ctx, BookRequest{Name: "fail"})
+	assert.Error(t, err)
+	assert.NoError(t, bsm.DeleteBookByID(ctx, "1"))
+
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.BooksCreatedTotal))
+	assert.Equal(t, deleted+1, testutil.ToFloat64(metrics.BooksDeletedTotal))
+	mockService.AssertExpectations(t)
+}
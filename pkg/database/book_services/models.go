@@ -1,34 +1,41 @@
 package bookservices
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrBookNotFound is returned for an id that matches no book.
+var ErrBookNotFound = errors.New("book not found")
 
 type Book struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Author      string    `json:"author"`
-	Publication string    `json:"publication"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint      `json:"id" xml:"id" yaml:"id"`
+	Name        string    `json:"name" xml:"name" yaml:"name"`
+	Author      string    `json:"author" xml:"author" yaml:"author"`
+	Publication string    `json:"publication" xml:"publication" yaml:"publication"`
+	CreatedAt   time.Time `json:"created_at" xml:"created_at" yaml:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
 }
 
+// Length limits match the VARCHAR(255) columns of the books table.
 type BookRequest struct {
-	Name        string `json:"name"`
-	Author      string `json:"author"`
-	Publication string `json:"publication"`
+	Name        string `json:"name" xml:"name" yaml:"name" mod:"trim" validate:"required,max=255"`
+	Author      string `json:"author" xml:"author" yaml:"author" mod:"trim" validate:"required,max=255"`
+	Publication string `json:"publication" xml:"publication" yaml:"publication" mod:"trim" validate:"required,max=255"`
 }
 
 type BookUpdateRequest struct {
-	Name        string    `json:"name"`
-	Author      string    `json:"author"`
-	Publication string    `json:"publication"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Name        string    `json:"name" xml:"name" yaml:"name" mod:"trim" validate:"required,max=255"`
+	Author      string    `json:"author" xml:"author" yaml:"author" mod:"trim" validate:"required,max=255"`
+	Publication string    `json:"publication" xml:"publication" yaml:"publication" mod:"trim" validate:"required,max=255"`
+	UpdatedAt   time.Time `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
 }
 
 type BookResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Author      string    `json:"author"`
-	Publication string    `json:"publication"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint      `json:"id" xml:"id" yaml:"id"`
+	Name        string    `json:"name" xml:"name" yaml:"name"`
+	Author      string    `json:"author" xml:"author" yaml:"author"`
+	Publication string    `json:"publication" xml:"publication" yaml:"publication"`
+	CreatedAt   time.Time `json:"created_at" xml:"created_at" yaml:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
 }
@@ -0,0 +1,71 @@
+package bookservices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// BookChangesChannel is the Postgres channel the books triggers notify on.
+const BookChangesChannel = "book_changes"
+
+// notificationReset is sent when books was truncated.
+const notificationReset = "reset"
+
+// Resyncer is implemented by event publishers that keep state derived from
+// the events, and must drop it when events may have been missed.
+type Resyncer interface {
+	Resync(ctx context.Context) error
+}
+
+// bookNotification is the payload of the books triggers.
+type bookNotification struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	BookID     uint          `json:"book_id"`
+	Book       *BookResponse `json:"book"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// BookChangeHandler turns book_changes notifications into BookEvents for
+// its publishers. Unlike outbox events they are not retried: a replica that
+// misses them is resynchronised instead. It implements pgnotify.Handler.
+type BookChangeHandler struct {
+	Publishers []EventPublisher
+}
+
+func NewBookChangeHandler(publishers ...EventPublisher) *BookChangeHandler {
+	return &BookChangeHandler{Publishers: publishers}
+}
+
+func (h *BookChangeHandler) HandleNotification(ctx context.Context, payload string) error {
+	var n bookNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return err
+	}
+	if n.Type == notificationReset {
+		return h.Resync(ctx)
+	}
+	event := BookEvent{ID: n.ID, Type: n.Type, BookID: n.BookID, Book: n.Book, OccurredAt: n.OccurredAt}
+	var errs []error
+	for _, publisher := range h.Publishers {
+		if err := publisher.PublishBookEvent(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Resync resynchronises the publishers that are Resyncers.
+func (h *BookChangeHandler) Resync(ctx context.Context) error {
+	var errs []error
+	for _, publisher := range h.Publishers {
+		if r, ok := publisher.(Resyncer); ok {
+			if err := r.Resync(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
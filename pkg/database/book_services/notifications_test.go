@@ -0,0 +1,58 @@
+package bookservices
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/cache"
+)
+
+func TestBookChangeHandler(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryStore(100)
+	bookCache := NewBookServicesCache(new(MockBookServices), store, time.Minute)
+	publisher := new(MockEventPublisher)
+	h := NewBookChangeHandler(bookCache, publisher)
+
+	fill := func() {
+		for _, key := range []string{cacheKeyAllBooks, cacheKeyBookByID + "1", cacheKeyBookByID + "2"} {
+			assert.NoError(t, store.Set(ctx, key, []byte("{}"), time.Minute))
+		}
+	}
+	cached := func(key string) bool {
+		_, found, _ := store.Get(ctx, key)
+		return found
+	}
+
+	// The payload as built by notify_book_change().
+	occurredAt := time.Date(2026, 10, 19, 12, 0, 0, 123456000, time.UTC)
+	publisher.On("PublishBookEvent", mock.Anything, mock.MatchedBy(func(e BookEvent) bool {
+		return e.ID == "ntf_1" && e.Type == EventBookUpdated && e.BookID == 1 && e.Book.Name == "Dune" && e.OccurredAt.Equal(occurredAt)
+	})).Return(nil).Once()
+	fill()
+	err := h.HandleNotification(ctx, `{"id" : "ntf_1", "type" : "book.updated", "book_id" : 1, "book" : {"id":1,"created_at":"2026-10-19T12:00:00.123456+00:00","updated_at":"2026-10-19T12:00:00.123456+00:00","name":"Dune","author":"Frank Herbert","publication":"Chilton"}, "occurred_at" : "2026-10-19T12:00:00.123456+00:00"}`)
+	assert.NoError(t, err)
+	assert.False(t, cached(cacheKeyAllBooks))
+	assert.False(t, cached(cacheKeyBookByID+"1"))
+	assert.True(t, cached(cacheKeyBookByID+"2"))
+
+	publisher.On("PublishBookEvent", mock.Anything, mock.MatchedBy(func(e BookEvent) bool {
+		return e.Type == EventBookDeleted && e.BookID == 2 && e.Book == nil
+	})).Return(nil).Once()
+	fill()
+	assert.NoError(t, h.HandleNotification(ctx, `{"id" : "ntf_2", "type" : "book.deleted", "book_id" : 2, "book" : null, "occurred_at" : "2026-10-19T12:00:01+00:00"}`))
+	assert.False(t, cached(cacheKeyBookByID+"2"))
+	assert.True(t, cached(cacheKeyBookByID+"1"))
+
+	// A truncate resynchronises instead; only Resyncers take part.
+	fill()
+	assert.NoError(t, h.HandleNotification(ctx, `{"type" : "reset"}`))
+	assert.False(t, cached(cacheKeyAllBooks))
+	assert.False(t, cached(cacheKeyBookByID+"1"))
+
+	assert.Error(t, h.HandleNotification(ctx, "not json"))
+	publisher.AssertExpectations(t)
+}
@@ -1,9 +1,16 @@
 package bookservices
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"strconv"
 	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/outbox"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/tracing"
 )
 
 type BookServicesPostgres struct {
@@ -16,20 +23,59 @@ func NewBookServicesPostgres(db *sql.DB) *BookServicesPostgres {
 	}
 }
 
-func (bsp *BookServicesPostgres) CreateBook(book BookRequest) (BookResponse, error) {
+// inTx runs mutate in a transaction and writes the outbox message it returns,
+// if any, in the same transaction, so a change never commits without its
+// event or the other way round.
+func (bsp *BookServicesPostgres) inTx(ctx context.Context, mutate func(tx *sql.Tx) (*outbox.Message, error)) error {
+	tx, err := bsp.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	msg, err := mutate(tx)
+	if err != nil {
+		return err
+	}
+	if msg != nil {
+		if err := outbox.Write(ctx, tx, *msg); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (bsp *BookServicesPostgres) CreateBook(ctx context.Context, book BookRequest) (BookResponse, error) {
 	var bookResponse BookResponse
 	query := "INSERT INTO books (name, author, publication, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, name, author, publication, created_at, updated_at"
-	err := bsp.DB.QueryRow(query, book.Name, book.Author, book.Publication, time.Now(), time.Now()).Scan(&bookResponse.ID, &bookResponse.Name, &bookResponse.Author, &bookResponse.Publication, &bookResponse.CreatedAt, &bookResponse.UpdatedAt)
+	ctx, span := tracing.StartDBSpan(ctx, "BookServicesPostgres.CreateBook", query)
+	defer span.End()
+	err := bsp.inTx(ctx, func(tx *sql.Tx) (*outbox.Message, error) {
+		err := tx.QueryRowContext(ctx, query, book.Name, book.Author, book.Publication, time.Now(), time.Now()).Scan(&bookResponse.ID, &bookResponse.Name, &bookResponse.Author, &bookResponse.Publication, &bookResponse.CreatedAt, &bookResponse.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := newOutboxMessage(EventBookCreated, bookResponse.ID, &bookResponse)
+		return &msg, err
+	})
 	if err != nil {
+		tracing.RecordError(span, err)
+		logger.FromContext(ctx).Error("failed to insert book", "error", err)
 		return BookResponse{}, err
 	}
+	logger.FromContext(ctx).Debug("book inserted", "book_id", bookResponse.ID)
 	return bookResponse, nil
 }
 
-func (bsp *BookServicesPostgres) GetAllBooks() ([]BookResponse, error) {
+func (bsp *BookServicesPostgres) GetAllBooks(ctx context.Context) ([]BookResponse, error) {
 	var books []BookResponse
-	rows, err := bsp.DB.Query("SELECT id, name, author, publication, created_at, updated_at FROM books")
+	query := "SELECT id, name, author, publication, created_at, updated_at FROM books"
+	ctx, span := tracing.StartDBSpan(ctx, "BookServicesPostgres.GetAllBooks", query)
+	defer span.End()
+	rows, err := bsp.DB.QueryContext(ctx, query)
 	if err != nil {
+		tracing.RecordError(span, err)
+		logger.FromContext(ctx).Error("failed to query books", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -37,41 +83,116 @@ func (bsp *BookServicesPostgres) GetAllBooks() ([]BookResponse, error) {
 	for rows.Next() {
 		var book BookResponse
 		if err := rows.Scan(&book.ID, &book.Name, &book.Author, &book.Publication, &book.CreatedAt, &book.UpdatedAt); err != nil {
+			tracing.RecordError(span, err)
+			logger.FromContext(ctx).Error("failed to scan book", "error", err)
 			return nil, err
 		}
 		books = append(books, book)
 	}
+	logger.FromContext(ctx).Debug("books queried", "count", len(books))
 	return books, nil
 }
 
-func (bsp *BookServicesPostgres) GetBookByID(bookID string) (BookResponse, error) {
+func (bsp *BookServicesPostgres) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]BookResponse, error) {
+	var books []BookResponse
+	query := "SELECT id, name, author, publication, created_at, updated_at FROM books WHERE id = ANY($1)"
+	ctx, span := tracing.StartDBSpan(ctx, "BookServicesPostgres.GetBooksByIDs", query)
+	defer span.End()
+	rows, err := bsp.DB.QueryContext(ctx, query, pq.Array(bookIDs))
+	if err != nil {
+		tracing.RecordError(span, err)
+		logger.FromContext(ctx).Error("failed to query books", "book_ids", bookIDs, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book BookResponse
+		if err := rows.Scan(&book.ID, &book.Name, &book.Author, &book.Publication, &book.CreatedAt, &book.UpdatedAt); err != nil {
+			tracing.RecordError(span, err)
+			logger.FromContext(ctx).Error("failed to scan book", "error", err)
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	logger.FromContext(ctx).Debug("books queried", "count", len(books))
+	return books, nil
+}
+
+func (bsp *BookServicesPostgres) GetBookByID(ctx context.Context, bookID string) (BookResponse, error) {
 	var book BookResponse
 	query := "SELECT id, name, author, publication, created_at, updated_at FROM books WHERE id = $1"
-	err := bsp.DB.QueryRow(query, bookID).Scan(&book.ID, &book.Name, &book.Author, &book.Publication, &book.CreatedAt, &book.UpdatedAt)
+	ctx, span := tracing.StartDBSpan(ctx, "BookServicesPostgres.GetBookByID", query)
+	defer span.End()
+	err := bsp.DB.QueryRowContext(ctx, query, bookID).Scan(&book.ID, &book.Name, &book.Author, &book.Publication, &book.CreatedAt, &book.UpdatedAt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return BookResponse{}, errors.New("book not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return BookResponse{}, ErrBookNotFound
 		}
+		tracing.RecordError(span, err)
+		logger.FromContext(ctx).Error("failed to query book", "book_id", bookID, "error", err)
 		return BookResponse{}, err
 	}
 	return book, nil
 }
 
-func (bsp *BookServicesPostgres) UpdateBookByID(bookID string, book BookUpdateRequest) (BookResponse, error) {
+func (bsp *BookServicesPostgres) UpdateBookByID(ctx context.Context, bookID string, book BookUpdateRequest) (BookResponse, error) {
 	var bookResponse BookResponse
 	query := "UPDATE books SET name = $1, author = $2, publication = $3, updated_at = $4 WHERE id = $5 RETURNING id, name, author, publication, created_at, updated_at"
-	err := bsp.DB.QueryRow(query, book.Name, book.Author, book.Publication, time.Now(), bookID).Scan(&bookResponse.ID, &bookResponse.Name, &bookResponse.Author, &bookResponse.Publication, &bookResponse.CreatedAt, &bookResponse.UpdatedAt)
+	ctx, span := tracing.StartDBSpan(ctx, "BookServicesPostgres.UpdateBookByID", query)
+	defer span.End()
+	err := bsp.inTx(ctx, func(tx *sql.Tx) (*outbox.Message, error) {
+		err := tx.QueryRowContext(ctx, query, book.Name, book.Author, book.Publication, time.Now(), bookID).Scan(&bookResponse.ID, &bookResponse.Name, &bookResponse.Author, &bookResponse.Publication, &bookResponse.CreatedAt, &bookResponse.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		msg, err := newOutboxMessage(EventBookUpdated, bookResponse.ID, &bookResponse)
+		return &msg, err
+	})
+	if errors.Is(err, ErrBookNotFound) {
+		return BookResponse{}, err
+	}
 	if err != nil {
+		tracing.RecordError(span, err)
+		logger.FromContext(ctx).Error("failed to update book", "book_id", bookID, "error", err)
 		return BookResponse{}, err
 	}
+	logger.FromContext(ctx).Debug("book updated", "book_id", bookResponse.ID)
 	return bookResponse, nil
 }
 
-func (bsp *BookServicesPostgres) DeleteBookByID(bookID string) error {
+func (bsp *BookServicesPostgres) DeleteBookByID(ctx context.Context, bookID string) error {
 	query := "DELETE FROM books WHERE id = $1"
-	_, err := bsp.DB.Exec(query, bookID)
+	ctx, span := tracing.StartDBSpan(ctx, "BookServicesPostgres.DeleteBookByID", query)
+	defer span.End()
+	err := bsp.inTx(ctx, func(tx *sql.Tx) (*outbox.Message, error) {
+		result, err := tx.ExecContext(ctx, query, bookID)
+		if err != nil {
+			return nil, err
+		}
+		// Deleting a missing book is not an error, but there is no event.
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return nil, err
+		}
+		id, err := strconv.ParseUint(bookID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := newOutboxMessage(EventBookDeleted, uint(id), nil)
+		return &msg, err
+	})
 	if err != nil {
+		tracing.RecordError(span, err)
+		logger.FromContext(ctx).Error("failed to delete book", "book_id", bookID, "error", err)
 		return err
 	}
+	logger.FromContext(ctx).Debug("book deleted", "book_id", bookID)
 	return nil
 }
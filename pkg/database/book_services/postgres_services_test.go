@@ -1,12 +1,19 @@
 package bookservices
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestCreateBook(t *testing.T) {
@@ -43,18 +50,22 @@ func TestCreateBook(t *testing.T) {
 
 			bsp := NewBookServicesPostgres(db)
 
+			mock.ExpectBegin()
 			if !tt.wantErr {
 				mock.ExpectQuery("INSERT INTO books").
 					WithArgs(tt.book.Name, tt.book.Author, tt.book.Publication, sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "author", "publication", "created_at", "updated_at"}).
 						AddRow(1, tt.book.Name, tt.book.Author, tt.book.Publication, time.Now(), time.Now()))
+				expectOutbox(mock, EventBookCreated, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			} else {
 				mock.ExpectQuery("INSERT INTO books").
 					WithArgs(tt.book.Name, tt.book.Author, tt.book.Publication, sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
 			}
 
-			bookResponse, err := bsp.CreateBook(tt.book)
+			bookResponse, err := bsp.CreateBook(context.Background(), tt.book)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -63,10 +74,17 @@ func TestCreateBook(t *testing.T) {
 				assert.Equal(t, tt.book.Author, bookResponse.Author)
 				assert.Equal(t, tt.book.Publication, bookResponse.Publication)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
+// expectOutbox expects the outbox row written with a mutation.
+func expectOutbox(mock sqlmock.Sqlmock, eventType, bookID string) *sqlmock.ExpectedExec {
+	return mock.ExpectExec("INSERT INTO outbox").
+		WithArgs(sqlmock.AnyArg(), AggregateBook, bookID, eventType, sqlmock.AnyArg(), sqlmock.AnyArg())
+}
+
 func TestGetAllBooks(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -99,7 +117,51 @@ func TestGetAllBooks(t *testing.T) {
 					WillReturnError(errors.New("select error"))
 			}
 
-			books, err := bsp.GetAllBooks()
+			books, err := bsp.GetAllBooks(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, books, 1)
+				assert.Equal(t, "Test Book", books[0].Name)
+			}
+		})
+	}
+}
+
+func TestGetBooksByIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "GetBooksByIDs_Success",
+			wantErr: false,
+		},
+		{
+			name:    "GetBooksByIDs_Failure",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			bsp := NewBookServicesPostgres(db)
+
+			expectation := mock.ExpectQuery("SELECT id, name, author, publication, created_at, updated_at FROM books WHERE id = ANY\\(\\$1\\)").
+				WithArgs("{\"1\",\"2\"}")
+			if !tt.wantErr {
+				expectation.WillReturnRows(sqlmock.NewRows([]string{"id", "name", "author", "publication", "created_at", "updated_at"}).
+					AddRow(1, "Test Book", "Test Author", "Test Publication", time.Now(), time.Now()))
+			} else {
+				expectation.WillReturnError(errors.New("select error"))
+			}
+
+			books, err := bsp.GetBooksByIDs(context.Background(), []string{"1", "2"})
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -107,6 +169,7 @@ func TestGetAllBooks(t *testing.T) {
 				assert.Len(t, books, 1)
 				assert.Equal(t, "Test Book", books[0].Name)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -148,7 +211,7 @@ func TestGetBookByID(t *testing.T) {
 					WillReturnError(errors.New("select error"))
 			}
 
-			book, err := bsp.GetBookByID(tt.bookID)
+			book, err := bsp.GetBookByID(context.Background(), tt.bookID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -196,18 +259,22 @@ func TestUpdateBookByID(t *testing.T) {
 
 			bsp := NewBookServicesPostgres(db)
 
+			mock.ExpectBegin()
 			if !tt.wantErr {
 				mock.ExpectQuery("UPDATE books SET").
 					WithArgs(tt.book.Name, tt.book.Author, tt.book.Publication, sqlmock.AnyArg(), tt.bookID).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "author", "publication", "created_at", "updated_at"}).
 						AddRow(1, tt.book.Name, tt.book.Author, tt.book.Publication, time.Now(), time.Now()))
+				expectOutbox(mock, EventBookUpdated, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			} else {
 				mock.ExpectQuery("UPDATE books SET").
 					WithArgs(tt.book.Name, tt.book.Author, tt.book.Publication, sqlmock.AnyArg(), tt.bookID).
 					WillReturnError(errors.New("update error"))
+				mock.ExpectRollback()
 			}
 
-			bookResponse, err := bsp.UpdateBookByID(tt.bookID, tt.book)
+			bookResponse, err := bsp.UpdateBookByID(context.Background(), tt.bookID, tt.book)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -220,6 +287,26 @@ func TestUpdateBookByID(t *testing.T) {
 	}
 }
 
+func TestBookNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	bsp := NewBookServicesPostgres(db)
+
+	mock.ExpectQuery("SELECT id, name, author, publication, created_at, updated_at FROM books WHERE id = \\$1").
+		WithArgs("9").
+		WillReturnError(sql.ErrNoRows)
+	_, err = bsp.GetBookByID(context.Background(), "9")
+	assert.ErrorIs(t, err, ErrBookNotFound)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE books SET").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	_, err = bsp.UpdateBookByID(context.Background(), "9", BookUpdateRequest{Name: "Name", Author: "Author", Publication: "Publication"})
+	assert.ErrorIs(t, err, ErrBookNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteBookByID(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -236,6 +323,10 @@ func TestDeleteBookByID(t *testing.T) {
 			bookID:  "2",
 			wantErr: true,
 		},
+		{
+			name:   "DeleteBookByID_Missing",
+			bookID: "3",
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,22 +337,79 @@ func TestDeleteBookByID(t *testing.T) {
 
 			bsp := NewBookServicesPostgres(db)
 
-			if !tt.wantErr {
+			mock.ExpectBegin()
+			switch {
+			case tt.wantErr:
 				mock.ExpectExec("DELETE FROM books WHERE id = \\$1").
 					WithArgs(tt.bookID).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-			} else {
+					WillReturnError(errors.New("delete error"))
+				mock.ExpectRollback()
+			case tt.name == "DeleteBookByID_Missing":
+				// Nothing was deleted, so no event is recorded.
 				mock.ExpectExec("DELETE FROM books WHERE id = \\$1").
 					WithArgs(tt.bookID).
-					WillReturnError(errors.New("delete error"))
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			default:
+				mock.ExpectExec("DELETE FROM books WHERE id = \\$1").
+					WithArgs(tt.bookID).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectOutbox(mock, EventBookDeleted, tt.bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			}
 
-			err = bsp.DeleteBookByID(tt.bookID)
+			err = bsp.DeleteBookByID(context.Background(), tt.bookID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestCreateBookRollsBackWithoutOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	bsp := NewBookServicesPostgres(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO books").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "author", "publication", "created_at", "updated_at"}).
+			AddRow(1, "Dune", "Frank Herbert", "Chilton", time.Now(), time.Now()))
+	expectOutbox(mock, EventBookCreated, "1").WillReturnError(errors.New("outbox insert error"))
+	mock.ExpectRollback()
+
+	_, err = bsp.CreateBook(context.Background(), BookRequest{Name: "Dune", Author: "Frank Herbert", Publication: "Chilton"})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookServicesPostgresSpans(t *testing.T) {
+	originalProvider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(originalProvider)
+
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(tracing.NewTracerProvider(sdktrace.NewSimpleSpanProcessor(exporter), "bookstore"))
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	bsp := NewBookServicesPostgres(db)
+
+	mock.ExpectQuery("SELECT id, name, author, publication, created_at, updated_at FROM books WHERE id = \\$1").
+		WithArgs("1").
+		WillReturnError(errors.New("select error"))
+
+	_, err = bsp.GetBookByID(context.Background(), "1")
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "BookServicesPostgres.GetBookByID", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
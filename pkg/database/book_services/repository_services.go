@@ -1,5 +1,7 @@
 package bookservices
 
+import "context"
+
 func NewBookServicesRepository(bs BookServicesInterface) *BookServicesRepository {
 	return &BookServicesRepository{
 		BookServices: bs,
@@ -10,22 +12,26 @@ type BookServicesRepository struct {
 	BookServices BookServicesInterface
 }
 
-func (bsr *BookServicesRepository) CreateBook(book BookRequest) (BookResponse, error) {
-	return bsr.BookServices.CreateBook(book)
+func (bsr *BookServicesRepository) CreateBook(ctx context.Context, book BookRequest) (BookResponse, error) {
+	return bsr.BookServices.CreateBook(ctx, book)
+}
+
+func (bsr *BookServicesRepository) GetAllBooks(ctx context.Context) ([]BookResponse, error) {
+	return bsr.BookServices.GetAllBooks(ctx)
 }
 
-func (bsr *BookServicesRepository) GetAllBooks() ([]BookResponse, error) {
-	return bsr.BookServices.GetAllBooks()
+func (bsr *BookServicesRepository) GetBookByID(ctx context.Context, bookID string) (BookResponse, error) {
+	return bsr.BookServices.GetBookByID(ctx, bookID)
 }
 
-func (bsr *BookServicesRepository) GetBookByID(bookID string) (BookResponse, error) {
-	return bsr.BookServices.GetBookByID(bookID)
+func (bsr *BookServicesRepository) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]BookResponse, error) {
+	return bsr.BookServices.GetBooksByIDs(ctx, bookIDs)
 }
 
-func (bsr *BookServicesRepository) UpdateBookByID(bookID string, book BookUpdateRequest) (BookResponse, error) {
-	return bsr.BookServices.UpdateBookByID(bookID, book)
+func (bsr *BookServicesRepository) UpdateBookByID(ctx context.Context, bookID string, book BookUpdateRequest) (BookResponse, error) {
+	return bsr.BookServices.UpdateBookByID(ctx, bookID, book)
 }
 
-func (bsr *BookServicesRepository) DeleteBookByID(bookID string) error {
-	return bsr.BookServices.DeleteBookByID(bookID)
+func (bsr *BookServicesRepository) DeleteBookByID(ctx context.Context, bookID string) error {
+	return bsr.BookServices.DeleteBookByID(ctx, bookID)
 }
@@ -1,6 +1,7 @@
 package bookservices
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,28 +13,33 @@ type MockBookServices struct {
 	mock.Mock
 }
 
-func (m *MockBookServices) CreateBook(book BookRequest) (BookResponse, error) {
-	args := m.Called(book)
+func (m *MockBookServices) CreateBook(ctx context.Context, book BookRequest) (BookResponse, error) {
+	args := m.Called(ctx, book)
 	return args.Get(0).(BookResponse), args.Error(1)
 }
 
-func (m *MockBookServices) GetAllBooks() ([]BookResponse, error) {
-	args := m.Called()
+func (m *MockBookServices) GetAllBooks(ctx context.Context) ([]BookResponse, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]BookResponse), args.Error(1)
 }
 
-func (m *MockBookServices) GetBookByID(bookID string) (BookResponse, error) {
-	args := m.Called(bookID)
+func (m *MockBookServices) GetBookByID(ctx context.Context, bookID string) (BookResponse, error) {
+	args := m.Called(ctx, bookID)
 	return args.Get(0).(BookResponse), args.Error(1)
 }
 
-func (m *MockBookServices) UpdateBookByID(bookID string, book BookUpdateRequest) (BookResponse, error) {
-	args := m.Called(bookID, book)
+func (m *MockBookServices) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]BookResponse, error) {
+	args := m.Called(ctx, bookIDs)
+	return args.Get(0).([]BookResponse), args.Error(1)
+}
+
+func (m *MockBookServices) UpdateBookByID(ctx context.Context, bookID string, book BookUpdateRequest) (BookResponse, error) {
+	args := m.Called(ctx, bookID, book)
 	return args.Get(0).(BookResponse), args.Error(1)
 }
 
-func (m *MockBookServices) DeleteBookByID(bookID string) error {
-	args := m.Called(bookID)
+func (m *MockBookServices) DeleteBookByID(ctx context.Context, bookID string) error {
+	args := m.Called(ctx, bookID)
 	return args.Error(0)
 }
 
@@ -44,9 +50,9 @@ func TestCreateBookRepository(t *testing.T) {
 	bookRequest := BookRequest{Name: "Test Book", Author: "Test Author", Publication: "Test Publication"}
 	bookResponse := BookResponse{ID: 1, Name: "Test Book", Author: "Test Author", Publication: "Test Publication"}
 
-	mockService.On("CreateBook", bookRequest).Return(bookResponse, nil)
+	mockService.On("CreateBook", mock.Anything, bookRequest).Return(bookResponse, nil)
 
-	result, err := repo.CreateBook(bookRequest)
+	result, err := repo.CreateBook(context.Background(), bookRequest)
 	assert.NoError(t, err)
 	assert.Equal(t, bookResponse, result)
 
@@ -61,9 +67,9 @@ func TestGetAllBooksRepository(t *testing.T) {
 		{ID: 1, Name: "Test Book", Author: "Test Author", Publication: "Test Publication"},
 	}
 
-	mockService.On("GetAllBooks").Return(bookResponses, nil)
+	mockService.On("GetAllBooks", mock.Anything).Return(bookResponses, nil)
 
-	result, err := repo.GetAllBooks()
+	result, err := repo.GetAllBooks(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, bookResponses, result)
 
@@ -77,9 +83,9 @@ func TestGetBookByIDRepository(t *testing.T) {
 	bookID := "1"
 	bookResponse := BookResponse{ID: 1, Name: "Test Book", Author: "Test Author", Publication: "Test Publication"}
 
-	mockService.On("GetBookByID", bookID).Return(bookResponse, nil)
+	mockService.On("GetBookByID", mock.Anything, bookID).Return(bookResponse, nil)
 
-	result, err := repo.GetBookByID(bookID)
+	result, err := repo.GetBookByID(context.Background(), bookID)
 	assert.NoError(t, err)
 	assert.Equal(t, bookResponse, result)
 
@@ -94,9 +100,9 @@ func TestUpdateBookByIDRepository(t *testing.T) {
 	bookUpdateRequest := BookUpdateRequest{Name: "Updated Book", Author: "Updated Author", Publication: "Updated Publication"}
 	bookResponse := BookResponse{ID: 1, Name: "Updated Book", Author: "Updated Author", Publication: "Updated Publication"}
 
-	mockService.On("UpdateBookByID", bookID, bookUpdateRequest).Return(bookResponse, nil)
+	mockService.On("UpdateBookByID", mock.Anything, bookID, bookUpdateRequest).Return(bookResponse, nil)
 
-	result, err := repo.UpdateBookByID(bookID, bookUpdateRequest)
+	result, err := repo.UpdateBookByID(context.Background(), bookID, bookUpdateRequest)
 	assert.NoError(t, err)
 	assert.Equal(t, bookResponse, result)
 
@@ -109,9 +115,9 @@ func TestDeleteBookByIDRepository(t *testing.T) {
 
 	bookID := "1"
 
-	mockService.On("DeleteBookByID", bookID).Return(nil)
+	mockService.On("DeleteBookByID", mock.Anything, bookID).Return(nil)
 
-	err := repo.DeleteBookByID(bookID)
+	err := repo.DeleteBookByID(context.Background(), bookID)
 	assert.NoError(t, err)
 
 	mockService.AssertExpectations(t)
@@ -0,0 +1,23 @@
+package idempotencyservices
+
+import (
+	"context"
+	"time"
+)
+
+type IdempotencyServicesInterface interface {
+	// Claim reserves an idempotency key for a request. It returns claimed
+	// true when the caller should process the request and then Complete or
+	// Release the key. Otherwise the key is taken and the returned record
+	// says by what: a completed response to replay, a request still in
+	// progress, or a request with another fingerprint.
+	Claim(ctx context.Context, claim ClaimRequest) (record Record, claimed bool, err error)
+	// Complete stores the response of a claimed key so repeats replay it.
+	Complete(ctx context.Context, scope, key string, response StoredResponse) error
+	// Release forgets a claimed key whose request failed, so it can be
+	// retried.
+	Release(ctx context.Context, scope, key string) error
+	// DeleteExpired removes the keys that expired before now and returns how
+	// many there were.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
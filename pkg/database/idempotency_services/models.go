@@ -0,0 +1,35 @@
+package idempotencyservices
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClaimRequest reserves Key within Scope, the caller's identity, for a
+// request whose method, path and body hash to Fingerprint. A claim whose
+// request never completes is given up after LockTimeout; a completed key is
+// replayed until TTL has passed.
+type ClaimRequest struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	LockTimeout time.Duration
+	TTL         time.Duration
+}
+
+type StoredResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type Record struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	// Response is nil while the first request is in progress.
+	Response    *StoredResponse
+	CreatedAt   time.Time
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,119 @@
+package idempotencyservices
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+// claimQuery inserts the key, or takes over an existing one that expired or
+// whose request with the same fingerprint was abandoned. It returns no row
+// when the key is held by another request.
+const claimQuery = `INSERT INTO idempotency_keys (scope, key, fingerprint, created_at, locked_until, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (scope, key) DO UPDATE SET
+	fingerprint = EXCLUDED.fingerprint, status_code = NULL, response_headers = NULL, response_body = NULL,
+	created_at = EXCLUDED.created_at, locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at
+		AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+RETURNING scope`
+
+const recordColumns = "scope, key, fingerprint, status_code, response_headers, response_body, created_at, locked_until, expires_at"
+
+type IdempotencyServicesPostgres struct {
+	DB *sql.DB
+
+	now func() time.Time
+}
+
+func NewIdempotencyServicesPostgres(db *sql.DB) *IdempotencyServicesPostgres {
+	return &IdempotencyServicesPostgres{
+		DB:  db,
+		now: time.Now,
+	}
+}
+
+func (isp *IdempotencyServicesPostgres) Claim(ctx context.Context, claim ClaimRequest) (Record, bool, error) {
+	// The key can be released between the insert and the select; the
+	// second attempt then claims it.
+	for attempt := 0; ; attempt++ {
+		now := isp.now()
+		var scope string
+		err := isp.DB.QueryRowContext(ctx, claimQuery, claim.Scope, claim.Key, claim.Fingerprint, now, now.Add(claim.LockTimeout), now.Add(claim.TTL)).Scan(&scope)
+		if err == nil {
+			return Record{
+				Scope:       claim.Scope,
+				Key:         claim.Key,
+				Fingerprint: claim.Fingerprint,
+				CreatedAt:   now,
+				LockedUntil: now.Add(claim.LockTimeout),
+				ExpiresAt:   now.Add(claim.TTL),
+			}, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return Record{}, false, err
+		}
+
+		record, err := isp.get(ctx, claim.Scope, claim.Key)
+		if err == sql.ErrNoRows && attempt == 0 {
+			continue
+		}
+		return record, false, err
+	}
+}
+
+func (isp *IdempotencyServicesPostgres) get(ctx context.Context, scope, key string) (Record, error) {
+	var (
+		record     Record
+		statusCode sql.NullInt64
+		header     sql.NullString
+		body       []byte
+	)
+	query := "SELECT " + recordColumns + " FROM idempotency_keys WHERE scope = $1 AND key = $2"
+	err := isp.DB.QueryRowContext(ctx, query, scope, key).
+		Scan(&record.Scope, &record.Key, &record.Fingerprint, &statusCode, &header, &body, &record.CreatedAt, &record.LockedUntil, &record.ExpiresAt)
+	if err != nil {
+		return Record{}, err
+	}
+	if statusCode.Valid {
+		response := &StoredResponse{StatusCode: int(statusCode.Int64), Header: http.Header{}, Body: body}
+		if header.Valid && header.String != "" {
+			if err := json.Unmarshal([]byte(header.String), &response.Header); err != nil {
+				return Record{}, err
+			}
+		}
+		record.Response = response
+	}
+	return record, nil
+}
+
+func (isp *IdempotencyServicesPostgres) Complete(ctx context.Context, scope, key string, response StoredResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE idempotency_keys SET status_code = $1, response_headers = $2, response_body = $3 WHERE scope = $4 AND key = $5"
+	if _, err := isp.DB.ExecContext(ctx, query, response.StatusCode, string(header), response.Body, scope, key); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Debug("idempotency key completed", "idempotency_key", key, "status", response.StatusCode)
+	return nil
+}
+
+func (isp *IdempotencyServicesPostgres) Release(ctx context.Context, scope, key string) error {
+	_, err := isp.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status_code IS NULL", scope, key)
+	return err
+}
+
+func (isp *IdempotencyServicesPostgres) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := isp.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,131 @@
+package idempotencyservices
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var recordColumnNames = []string{"scope", "key", "fingerprint", "status_code", "response_headers", "response_body", "created_at", "locked_until", "expires_at"}
+
+func newTestServices(t *testing.T) (*IdempotencyServicesPostgres, sqlmock.Sqlmock, time.Time) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	isp := NewIdempotencyServicesPostgres(db)
+	isp.now = func() time.Time { return now }
+	return isp, mock, now
+}
+
+func TestClaim(t *testing.T) {
+	claim := ClaimRequest{Scope: "apikey:1", Key: "key-1", Fingerprint: "abc", LockTimeout: time.Minute, TTL: 24 * time.Hour}
+
+	tests := []struct {
+		name        string
+		setup       func(mock sqlmock.Sqlmock, now time.Time)
+		wantClaimed bool
+		wantRecord  Record
+		wantErr     bool
+	}{
+		{
+			name: "Claim_New",
+			setup: func(mock sqlmock.Sqlmock, now time.Time) {
+				mock.ExpectQuery("INSERT INTO idempotency_keys").
+					WithArgs("apikey:1", "key-1", "abc", now, now.Add(time.Minute), now.Add(24*time.Hour)).
+					WillReturnRows(sqlmock.NewRows([]string{"scope"}).AddRow("apikey:1"))
+			},
+			wantClaimed: true,
+		},
+		{
+			name: "Claim_Completed",
+			setup: func(mock sqlmock.Sqlmock, now time.Time) {
+				mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnRows(sqlmock.NewRows([]string{"scope"}))
+				mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").WithArgs("apikey:1", "key-1").
+					WillReturnRows(sqlmock.NewRows(recordColumnNames).
+						AddRow("apikey:1", "key-1", "abc", 200, `{"Content-Type":["application/json"]}`, []byte(`{"id":1}`), now, now, now.Add(time.Hour)))
+			},
+			wantRecord: Record{
+				Scope: "apikey:1", Key: "key-1", Fingerprint: "abc",
+				Response: &StoredResponse{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"id":1}`)},
+			},
+		},
+		{
+			name: "Claim_InProgress",
+			setup: func(mock sqlmock.Sqlmock, now time.Time) {
+				mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnRows(sqlmock.NewRows([]string{"scope"}))
+				mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+					WillReturnRows(sqlmock.NewRows(recordColumnNames).
+						AddRow("apikey:1", "key-1", "abc", nil, nil, nil, now, now, now.Add(time.Hour)))
+			},
+			wantRecord: Record{Scope: "apikey:1", Key: "key-1", Fingerprint: "abc"},
+		},
+		{
+			name: "Claim_ReleasedMeanwhile",
+			setup: func(mock sqlmock.Sqlmock, now time.Time) {
+				mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnRows(sqlmock.NewRows([]string{"scope"}))
+				mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").WillReturnRows(sqlmock.NewRows(recordColumnNames))
+				mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnRows(sqlmock.NewRows([]string{"scope"}).AddRow("apikey:1"))
+			},
+			wantClaimed: true,
+		},
+		{
+			name: "Claim_Failure",
+			setup: func(mock sqlmock.Sqlmock, now time.Time) {
+				mock.ExpectQuery("INSERT INTO idempotency_keys").WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isp, mock, now := newTestServices(t)
+			tt.setup(mock, now)
+
+			record, claimed, err := isp.Claim(context.Background(), claim)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantClaimed, claimed)
+				if !claimed {
+					assert.Equal(t, tt.wantRecord.Fingerprint, record.Fingerprint)
+					assert.Equal(t, tt.wantRecord.Response, record.Response)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	isp, mock, _ := newTestServices(t)
+	mock.ExpectExec("UPDATE idempotency_keys SET status_code").
+		WithArgs(201, `{"Location":["/api/v1/books/1"]}`, []byte("{}"), "apikey:1", "key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := isp.Complete(context.Background(), "apikey:1", "key-1", StoredResponse{
+		StatusCode: 201, Header: http.Header{"Location": {"/api/v1/books/1"}}, Body: []byte("{}"),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseAndDeleteExpired(t *testing.T) {
+	isp, mock, now := newTestServices(t)
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE scope").WithArgs("apikey:1", "key-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE expires_at").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, isp.Release(context.Background(), "apikey:1", "key-1"))
+	deleted, err := isp.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
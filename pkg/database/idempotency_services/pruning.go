@@ -0,0 +1,46 @@
+package idempotencyservices
+
+import (
+	"context"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+// StartPruning deletes expired keys every interval in the background. The
+// returned function stops it, or gives up when ctx ends.
+func StartPruning(service IdempotencyServicesInterface, interval time.Duration) func(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				deleted, err := service.DeleteExpired(ctx, now)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.FromContext(ctx).Warn("idempotency key pruning failed", "error", err)
+					}
+					continue
+				}
+				if deleted > 0 {
+					logger.FromContext(ctx).Debug("expired idempotency keys deleted", "count", deleted)
+				}
+			}
+		}
+	}()
+	return func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	}
+}
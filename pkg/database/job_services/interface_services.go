@@ -0,0 +1,29 @@
+package jobservices
+
+import (
+	"context"
+	"time"
+)
+
+type JobServicesInterface interface {
+	// Enqueue adds a pending job. It returns ErrJobExists when the unique
+	// key is taken.
+	Enqueue(ctx context.Context, job JobRequest) (Job, error)
+	// ClaimDueJobs marks up to limit due jobs as running, counting an
+	// attempt, and leases them until now+lease. Running jobs whose lease ran
+	// out are claimed again.
+	ClaimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Job, error)
+	// RecordResult stores the outcome of a run. It is ignored if the job was
+	// claimed again meanwhile.
+	RecordResult(ctx context.Context, result JobResult) error
+	GetJobs(ctx context.Context, filter JobFilter) ([]Job, error)
+	GetJobByID(ctx context.Context, jobID string) (Job, error)
+	// RetryJobByID queues a failed or cancelled job for a fresh round of
+	// attempts.
+	RetryJobByID(ctx context.Context, jobID string) (Job, error)
+	// CancelJobByID cancels a pending job. Running jobs cannot be cancelled.
+	CancelJobByID(ctx context.Context, jobID string) (Job, error)
+	// DeleteFinishedJobs removes the jobs that finished before the given
+	// time and returns how many there were.
+	DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,68 @@
+package jobservices
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+var JobStatuses = []string{JobPending, JobRunning, JobSucceeded, JobFailed, JobCancelled}
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobExists         = errors.New("a job with this unique key already exists")
+	ErrJobNotRetryable   = errors.New("job is not failed or cancelled")
+	ErrJobNotCancellable = errors.New("job is not pending")
+)
+
+type Job struct {
+	ID          uint            `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedUntil *time.Time      `json:"locked_until"`
+	UniqueKey   *string         `json:"unique_key"`
+	LastError   *string         `json:"last_error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+}
+
+// JobRequest enqueues a job. A zero RunAt runs it as soon as possible. A
+// job with the UniqueKey of an existing one is not enqueued.
+type JobRequest struct {
+	Type        string
+	Payload     json.RawMessage
+	RunAt       time.Time
+	MaxAttempts int
+	UniqueKey   string
+}
+
+// JobResult is the outcome of one run of a claimed job. Status is pending
+// for a job that will be retried at NextRunAt.
+type JobResult struct {
+	JobID      uint
+	Status     string
+	Attempts   int
+	FinishedAt time.Time
+	NextRunAt  time.Time
+	Error      string
+}
+
+// JobFilter narrows the job list. Empty fields match everything.
+type JobFilter struct {
+	Status string
+	Type   string
+	Limit  int
+}
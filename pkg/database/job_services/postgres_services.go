@@ -0,0 +1,203 @@
+package jobservices
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+const jobColumns = "id, type, payload, status, attempts, max_attempts, run_at, locked_until, unique_key, last_error, created_at, updated_at, finished_at"
+
+// claimDueJobsQuery locks due rows with SKIP LOCKED so concurrent workers,
+// e.g. on other replicas, never claim the same job.
+const claimDueJobsQuery = `WITH due AS (
+	SELECT id FROM jobs
+	WHERE (status = 'pending' AND run_at <= $1) OR (status = 'running' AND locked_until <= $1)
+	ORDER BY run_at, id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE jobs j SET status = 'running', attempts = j.attempts + 1, locked_until = $3, updated_at = $1
+FROM due
+WHERE j.id = due.id
+RETURNING j.id, j.type, j.payload, j.status, j.attempts, j.max_attempts, j.run_at, j.locked_until, j.unique_key, j.last_error, j.created_at, j.updated_at, j.finished_at`
+
+type JobServicesPostgres struct {
+	DB *sql.DB
+}
+
+func NewJobServicesPostgres(db *sql.DB) *JobServicesPostgres {
+	return &JobServicesPostgres{
+		DB: db,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row scanner) (Job, error) {
+	var job Job
+	var payload []byte
+	err := row.Scan(&job.ID, &job.Type, &payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt,
+		&job.LockedUntil, &job.UniqueKey, &job.LastError, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt)
+	job.Payload = payload
+	return job, err
+}
+
+func (jsp *JobServicesPostgres) Enqueue(ctx context.Context, job JobRequest) (Job, error) {
+	now := time.Now()
+	runAt := job.RunAt
+	if runAt.IsZero() {
+		runAt = now
+	}
+	payload := string(job.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+	var uniqueKey *string
+	if job.UniqueKey != "" {
+		uniqueKey = &job.UniqueKey
+	}
+
+	query := "INSERT INTO jobs (type, payload, status, attempts, max_attempts, run_at, unique_key, created_at, updated_at) " +
+		"VALUES ($1, $2, 'pending', 0, $3, $4, $5, $6, $6) ON CONFLICT (unique_key) DO NOTHING RETURNING " + jobColumns
+	created, err := scanJob(jsp.DB.QueryRowContext(ctx, query, job.Type, payload, job.MaxAttempts, runAt, uniqueKey, now))
+	if err == sql.ErrNoRows {
+		return Job{}, ErrJobExists
+	}
+	if err != nil {
+		return Job{}, err
+	}
+	logger.FromContext(ctx).Debug("job enqueued", "job_id", created.ID, "job_type", created.Type, "run_at", created.RunAt)
+	return created, nil
+}
+
+func (jsp *JobServicesPostgres) ClaimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Job, error) {
+	rows, err := jsp.DB.QueryContext(ctx, claimDueJobsQuery, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (jsp *JobServicesPostgres) RecordResult(ctx context.Context, result JobResult) error {
+	var lastError *string
+	if result.Error != "" {
+		lastError = &result.Error
+	}
+	var finishedAt *time.Time
+	if result.Status != JobPending {
+		finishedAt = &result.FinishedAt
+	}
+	// The attempts check skips results of runs that outlived their lease
+	// and were claimed by another worker.
+	query := "UPDATE jobs SET status = $1, attempts = $2, run_at = $3, locked_until = NULL, last_error = COALESCE($4, last_error), finished_at = $5, updated_at = $6 " +
+		"WHERE id = $7 AND status = 'running' AND attempts = $8"
+	_, err := jsp.DB.ExecContext(ctx, query, result.Status, result.Attempts, result.NextRunAt, lastError, finishedAt, result.FinishedAt, result.JobID, result.Attempts)
+	return err
+}
+
+func (jsp *JobServicesPostgres) GetJobs(ctx context.Context, filter JobFilter) ([]Job, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	query := "SELECT " + jobColumns + " FROM jobs"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := jsp.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (jsp *JobServicesPostgres) GetJobByID(ctx context.Context, jobID string) (Job, error) {
+	job, err := scanJob(jsp.DB.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = $1", jobID))
+	if err == sql.ErrNoRows {
+		return Job{}, ErrJobNotFound
+	}
+	return job, err
+}
+
+func (jsp *JobServicesPostgres) RetryJobByID(ctx context.Context, jobID string) (Job, error) {
+	query := "UPDATE jobs SET status = 'pending', attempts = 0, run_at = $1, finished_at = NULL, updated_at = $1 " +
+		"WHERE id = $2 AND status IN ('failed', 'cancelled') RETURNING " + jobColumns
+	job, err := jsp.transition(ctx, query, jobID, ErrJobNotRetryable)
+	if err == nil {
+		logger.FromContext(ctx).Info("job requeued", "job_id", job.ID, "job_type", job.Type)
+	}
+	return job, err
+}
+
+func (jsp *JobServicesPostgres) CancelJobByID(ctx context.Context, jobID string) (Job, error) {
+	query := "UPDATE jobs SET status = 'cancelled', finished_at = $1, updated_at = $1 " +
+		"WHERE id = $2 AND status = 'pending' RETURNING " + jobColumns
+	job, err := jsp.transition(ctx, query, jobID, ErrJobNotCancellable)
+	if err == nil {
+		logger.FromContext(ctx).Info("job cancelled", "job_id", job.ID, "job_type", job.Type)
+	}
+	return job, err
+}
+
+// transition runs a status update and tells a missing job apart from one in
+// the wrong status, which gets wrongStatus.
+func (jsp *JobServicesPostgres) transition(ctx context.Context, query, jobID string, wrongStatus error) (Job, error) {
+	job, err := scanJob(jsp.DB.QueryRowContext(ctx, query, time.Now(), jobID))
+	if err != sql.ErrNoRows {
+		return job, err
+	}
+
+	var status string
+	err = jsp.DB.QueryRowContext(ctx, "SELECT status FROM jobs WHERE id = $1", jobID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return Job{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Job{}, err
+	}
+	return Job{}, wrongStatus
+}
+
+func (jsp *JobServicesPostgres) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	result, err := jsp.DB.ExecContext(ctx, "DELETE FROM jobs WHERE finished_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,218 @@
+package jobservices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var jobColumnNames = []string{"id", "type", "payload", "status", "attempts", "max_attempts", "run_at", "locked_until", "unique_key", "last_error", "created_at", "updated_at", "finished_at"}
+
+func jobRow(id uint, status string, attempts int) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(jobColumnNames).AddRow(id, "jobs.prune", []byte("{}"), status, attempts, 5, now, nil, nil, nil, now, now, nil)
+}
+
+func newTestServices(t *testing.T) (*JobServicesPostgres, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return NewJobServicesPostgres(db), mock
+}
+
+func TestEnqueue(t *testing.T) {
+	runAt := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		request JobRequest
+		rows    *sqlmock.Rows
+		sqlErr  error
+		wantErr error
+	}{
+		{
+			name:    "Enqueue_Success",
+			request: JobRequest{Type: "jobs.prune", MaxAttempts: 5},
+			rows:    jobRow(1, JobPending, 0),
+		},
+		{
+			name:    "Enqueue_Duplicate",
+			request: JobRequest{Type: "jobs.prune", MaxAttempts: 5, RunAt: runAt, UniqueKey: "schedule:prune:1792414800"},
+			rows:    sqlmock.NewRows(jobColumnNames),
+			wantErr: ErrJobExists,
+		},
+		{
+			name:    "Enqueue_Failure",
+			request: JobRequest{Type: "jobs.prune", MaxAttempts: 5},
+			sqlErr:  errors.New("insert error"),
+			wantErr: errors.New("insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsp, mock := newTestServices(t)
+			var uniqueKey interface{} = nil
+			if tt.request.UniqueKey != "" {
+				uniqueKey = tt.request.UniqueKey
+			}
+			var runAt interface{} = sqlmock.AnyArg()
+			if !tt.request.RunAt.IsZero() {
+				runAt = tt.request.RunAt
+			}
+			expectation := mock.ExpectQuery("INSERT INTO jobs").
+				WithArgs(tt.request.Type, "{}", tt.request.MaxAttempts, runAt, uniqueKey, sqlmock.AnyArg())
+			if tt.sqlErr != nil {
+				expectation.WillReturnError(tt.sqlErr)
+			} else {
+				expectation.WillReturnRows(tt.rows)
+			}
+
+			job, err := jsp.Enqueue(context.Background(), tt.request)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), job.ID)
+				assert.Equal(t, JobPending, job.Status)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClaimDueJobs(t *testing.T) {
+	jsp, mock := newTestServices(t)
+	now := time.Now()
+	mock.ExpectQuery("FOR UPDATE SKIP LOCKED").WithArgs(now, 2, now.Add(time.Minute)).
+		WillReturnRows(jobRow(1, JobRunning, 1).AddRow(2, "jobs.prune", []byte(`{"days":7}`), JobRunning, 2, 5, now, now, nil, nil, now, now, nil))
+
+	jobs, err := jsp.ClaimDueJobs(context.Background(), now, time.Minute, 2)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.JSONEq(t, `{"days":7}`, string(jobs[1].Payload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordResult(t *testing.T) {
+	jsp, mock := newTestServices(t)
+	now := time.Now()
+	retryAt := now.Add(time.Minute)
+	mock.ExpectExec("UPDATE jobs SET status").
+		WithArgs(JobPending, 2, retryAt, "boom", nil, now, uint(7), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE jobs SET status").
+		WithArgs(JobSucceeded, 3, now, nil, now, now, uint(7), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, jsp.RecordResult(context.Background(), JobResult{JobID: 7, Status: JobPending, Attempts: 2, FinishedAt: now, NextRunAt: retryAt, Error: "boom"}))
+	assert.NoError(t, jsp.RecordResult(context.Background(), JobResult{JobID: 7, Status: JobSucceeded, Attempts: 3, FinishedAt: now, NextRunAt: now}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetJobs(t *testing.T) {
+	jsp, mock := newTestServices(t)
+	mock.ExpectQuery(`SELECT (.+) FROM jobs WHERE status = \$1 AND type = \$2 ORDER BY id DESC LIMIT \$3`).
+		WithArgs(JobFailed, "jobs.prune", 10).
+		WillReturnRows(jobRow(1, JobFailed, 5))
+
+	jobs, err := jsp.GetJobs(context.Background(), JobFilter{Status: JobFailed, Type: "jobs.prune", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetJobByIDNotFound(t *testing.T) {
+	jsp, mock := newTestServices(t)
+	mock.ExpectQuery("SELECT (.+) FROM jobs WHERE id").WithArgs("9").WillReturnError(sql.ErrNoRows)
+
+	_, err := jsp.GetJobByID(context.Background(), "9")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryAndCancelJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		call    func(jsp *JobServicesPostgres) (Job, error)
+		update  string
+		status  string // current status when the update matches nothing
+		found   bool
+		wantErr error
+	}{
+		{
+			name:   "Retry_Success",
+			call:   func(jsp *JobServicesPostgres) (Job, error) { return jsp.RetryJobByID(context.Background(), "1") },
+			update: "status IN",
+			found:  true,
+		},
+		{
+			name:    "Retry_NotFailed",
+			call:    func(jsp *JobServicesPostgres) (Job, error) { return jsp.RetryJobByID(context.Background(), "1") },
+			update:  "status IN",
+			status:  JobRunning,
+			wantErr: ErrJobNotRetryable,
+		},
+		{
+			name:    "Retry_NotFound",
+			call:    func(jsp *JobServicesPostgres) (Job, error) { return jsp.RetryJobByID(context.Background(), "1") },
+			update:  "status IN",
+			wantErr: ErrJobNotFound,
+		},
+		{
+			name:   "Cancel_Success",
+			call:   func(jsp *JobServicesPostgres) (Job, error) { return jsp.CancelJobByID(context.Background(), "1") },
+			update: "status = 'cancelled'",
+			found:  true,
+		},
+		{
+			name:    "Cancel_NotPending",
+			call:    func(jsp *JobServicesPostgres) (Job, error) { return jsp.CancelJobByID(context.Background(), "1") },
+			update:  "status = 'cancelled'",
+			status:  JobSucceeded,
+			wantErr: ErrJobNotCancellable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsp, mock := newTestServices(t)
+			expectation := mock.ExpectQuery("UPDATE jobs SET .*"+tt.update).WithArgs(sqlmock.AnyArg(), "1")
+			if tt.found {
+				expectation.WillReturnRows(jobRow(1, JobPending, 0))
+			} else {
+				expectation.WillReturnRows(sqlmock.NewRows(jobColumnNames))
+				status := mock.ExpectQuery("SELECT status FROM jobs").WithArgs("1")
+				if tt.status != "" {
+					status.WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(tt.status))
+				} else {
+					status.WillReturnError(sql.ErrNoRows)
+				}
+			}
+
+			job, err := tt.call(jsp)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), job.ID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteFinishedJobs(t *testing.T) {
+	jsp, mock := newTestServices(t)
+	before := time.Now()
+	mock.ExpectExec("DELETE FROM jobs WHERE finished_at").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := jsp.DeleteFinishedJobs(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
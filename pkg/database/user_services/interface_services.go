@@ -0,0 +1,7 @@
+package userservices
+
+import "context"
+
+type UserServicesInterface interface {
+	CreateUser(ctx context.Context, user UserRequest) (UserResponse, error)
+}
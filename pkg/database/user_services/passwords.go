@@ -0,0 +1,48 @@
+package userservices
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	MinPasswordLength = 12
+	// bcrypt ignores everything past 72 bytes, so longer passwords are
+	// refused rather than silently truncated.
+	MaxPasswordLength = 72
+)
+
+var (
+	ErrUsernameTaken = errors.New("username already taken")
+
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{2,63}$`)
+)
+
+func ValidateUser(user UserRequest) error {
+	if !usernamePattern.MatchString(user.Username) {
+		return errors.New("username must be 3 to 64 letters, digits, dots, dashes or underscores")
+	}
+	if len(user.Password) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	if len(user.Password) > MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", MaxPasswordLength)
+	}
+	return nil
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches a hash from HashPassword.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
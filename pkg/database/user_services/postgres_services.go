@@ -0,0 +1,45 @@
+package userservices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+type UserServicesPostgres struct {
+	DB *sql.DB
+}
+
+func NewUserServicesPostgres(db *sql.DB) *UserServicesPostgres {
+	return &UserServicesPostgres{
+		DB: db,
+	}
+}
+
+func (usp *UserServicesPostgres) CreateUser(ctx context.Context, user UserRequest) (UserResponse, error) {
+	if err := ValidateUser(user); err != nil {
+		return UserResponse{}, err
+	}
+	hash, err := HashPassword(user.Password)
+	if err != nil {
+		return UserResponse{}, err
+	}
+
+	var created UserResponse
+	now := time.Now()
+	// A taken username inserts nothing, so RETURNING yields no row.
+	query := "INSERT INTO users (username, password_hash, created_at, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (username) DO NOTHING RETURNING id, username, created_at, updated_at"
+	err = usp.DB.QueryRowContext(ctx, query, user.Username, hash, now, now).
+		Scan(&created.ID, &created.Username, &created.CreatedAt, &created.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return UserResponse{}, ErrUsernameTaken
+	}
+	if err != nil {
+		return UserResponse{}, err
+	}
+	logger.FromContext(ctx).Info("user created", "user_id", created.ID, "username", created.Username)
+	return created, nil
+}
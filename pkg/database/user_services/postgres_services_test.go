@@ -0,0 +1,101 @@
+package userservices
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var userColumns = []string{"id", "username", "created_at", "updated_at"}
+
+// passwordHashOf matches a bcrypt hash of the given password.
+type passwordHashOf string
+
+func (p passwordHashOf) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	return ok && hash != string(p) && CheckPassword(hash, string(p))
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   UserRequest
+		expectSQL bool
+		noRows    bool
+		sqlErr    error
+		wantErr   error
+	}{
+		{
+			name:      "CreateUser_Success",
+			request:   UserRequest{Username: "alice", Password: "correct horse battery"},
+			expectSQL: true,
+		},
+		{
+			name:      "CreateUser_UsernameTaken",
+			request:   UserRequest{Username: "alice", Password: "correct horse battery"},
+			expectSQL: true,
+			noRows:    true,
+			wantErr:   ErrUsernameTaken,
+		},
+		{
+			name:      "CreateUser_Failure",
+			request:   UserRequest{Username: "alice", Password: "correct horse battery"},
+			expectSQL: true,
+			sqlErr:    errors.New("insert error"),
+		},
+		{
+			name:    "CreateUser_InvalidUsername",
+			request: UserRequest{Username: "a b", Password: "correct horse battery"},
+		},
+		{
+			name:    "CreateUser_ShortPassword",
+			request: UserRequest{Username: "alice", Password: "short"},
+		},
+		{
+			name:    "CreateUser_LongPassword",
+			request: UserRequest{Username: "alice", Password: strings.Repeat("x", MaxPasswordLength+1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			usp := NewUserServicesPostgres(db)
+
+			if tt.expectSQL {
+				expectation := mock.ExpectQuery("INSERT INTO users").
+					WithArgs(tt.request.Username, passwordHashOf(tt.request.Password), sqlmock.AnyArg(), sqlmock.AnyArg())
+				switch {
+				case tt.sqlErr != nil:
+					expectation.WillReturnError(tt.sqlErr)
+				case tt.noRows:
+					expectation.WillReturnRows(sqlmock.NewRows(userColumns))
+				default:
+					expectation.WillReturnRows(sqlmock.NewRows(userColumns).
+						AddRow(1, tt.request.Username, time.Now(), time.Now()))
+				}
+			}
+
+			user, err := usp.CreateUser(context.Background(), tt.request)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.expectSQL && tt.sqlErr == nil:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.request.Username, user.Username)
+			default:
+				assert.Error(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
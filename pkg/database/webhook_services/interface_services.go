@@ -0,0 +1,29 @@
+package webhookservices
+
+import (
+	"context"
+	"time"
+)
+
+type WebhookServicesInterface interface {
+	CreateSubscription(ctx context.Context, subscription SubscriptionRequest) (CreatedSubscriptionResponse, error)
+	GetAllSubscriptions(ctx context.Context) ([]SubscriptionResponse, error)
+	GetSubscriptionByID(ctx context.Context, subscriptionID string) (SubscriptionResponse, error)
+	UpdateSubscriptionByID(ctx context.Context, subscriptionID string, subscription SubscriptionRequest) (SubscriptionResponse, error)
+	DeleteSubscriptionByID(ctx context.Context, subscriptionID string) error
+
+	// EnqueueEvent creates a pending delivery of event for every subscription
+	// that listens to its type and returns how many were created. Enqueueing
+	// the same event ID twice is a no-op.
+	EnqueueEvent(ctx context.Context, event Event) (int, error)
+	// ClaimDueDeliveries returns up to limit pending deliveries that are due
+	// at now and pushes their next attempt back by lease, so other workers
+	// skip them while they are being sent.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DueDelivery, error)
+	// RecordAttempt stores the outcome of an attempt, unless the delivery has
+	// been claimed again since attempt.LeasedUntil was set.
+	RecordAttempt(ctx context.Context, attempt DeliveryAttempt) error
+	GetDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error)
+	// RetryDeliveryByID puts a dead-lettered delivery back in the queue.
+	RetryDeliveryByID(ctx context.Context, deliveryID string) (Delivery, error)
+}
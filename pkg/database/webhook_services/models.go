@@ -0,0 +1,103 @@
+package webhookservices
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryPending      = "pending"
+	DeliverySucceeded    = "succeeded"
+	DeliveryDeadLettered = "dead_lettered"
+)
+
+var DeliveryStatuses = []string{DeliveryPending, DeliverySucceeded, DeliveryDeadLettered}
+
+type Subscription struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SubscriptionRequest creates or replaces a subscription. A secret is
+// generated when none is given; on update an empty secret keeps the current
+// one.
+type SubscriptionRequest struct {
+	URL         string   `json:"url" mod:"trim" validate:"required,http_url,max=2048"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	Events      []string `json:"events" validate:"required,min=1,dive,oneof=book.created book.updated book.deleted"`
+	Description string   `json:"description" mod:"trim" validate:"max=255"`
+}
+
+type SubscriptionResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreatedSubscriptionResponse is only returned when the subscription is
+// created, so the receiver can be configured with the signing secret.
+type CreatedSubscriptionResponse struct {
+	SubscriptionResponse
+	Secret string `json:"secret"`
+}
+
+// Event is a payload to deliver to the subscriptions listening to Type.
+// Payload is sent as is, so every attempt carries the same bytes.
+type Event struct {
+	ID         string
+	Type       string
+	Payload    []byte
+	OccurredAt time.Time
+}
+
+type Delivery struct {
+	ID             uint            `json:"id"`
+	SubscriptionID uint            `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// DueDelivery is a claimed delivery with what is needed to send it.
+type DueDelivery struct {
+	Delivery
+	URL    string
+	Secret string
+}
+
+// DeliveryAttempt is the outcome of one attempt. NextAttemptAt is only used
+// when Status is still pending. LeasedUntil is the next attempt time the
+// claim set; it tells this claim apart from a later one.
+type DeliveryAttempt struct {
+	DeliveryID    uint
+	LeasedUntil   time.Time
+	Status        string
+	Attempts      int
+	AttemptedAt   time.Time
+	NextAttemptAt time.Time
+	StatusCode    *int
+	Error         string
+}
+
+// DeliveryFilter narrows the delivery log. Empty fields match everything.
+type DeliveryFilter struct {
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
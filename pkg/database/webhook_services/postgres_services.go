@@ -0,0 +1,251 @@
+package webhookservices
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+const (
+	subscriptionColumns = "id, url, events, description, created_at, updated_at"
+	deliveryColumns     = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at"
+)
+
+// claimDueDeliveriesQuery locks due rows with SKIP LOCKED so concurrent
+// dispatchers, e.g. on other replicas, never claim the same delivery.
+const claimDueDeliveriesQuery = `WITH due AS (
+	SELECT id FROM webhook_deliveries
+	WHERE status = 'pending' AND next_attempt_at <= $1
+	ORDER BY next_attempt_at, id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries d SET next_attempt_at = $3
+FROM due, webhook_subscriptions s
+WHERE d.id = due.id AND s.id = d.subscription_id
+RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at, s.url, s.secret`
+
+type WebhookServicesPostgres struct {
+	DB *sql.DB
+}
+
+func NewWebhookServicesPostgres(db *sql.DB) *WebhookServicesPostgres {
+	return &WebhookServicesPostgres{
+		DB: db,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row scanner) (SubscriptionResponse, error) {
+	var subscription SubscriptionResponse
+	err := row.Scan(&subscription.ID, &subscription.URL, pq.Array(&subscription.Events), &subscription.Description, &subscription.CreatedAt, &subscription.UpdatedAt)
+	return subscription, err
+}
+
+func deliveryFields(delivery *Delivery, payload *[]byte) []interface{} {
+	return []interface{}{&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &delivery.LastAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.DeliveredAt, &delivery.CreatedAt}
+}
+
+func scanDelivery(row scanner) (Delivery, error) {
+	var delivery Delivery
+	var payload []byte
+	if err := row.Scan(deliveryFields(&delivery, &payload)...); err != nil {
+		return Delivery{}, err
+	}
+	delivery.Payload = payload
+	return delivery, nil
+}
+
+func (wsp *WebhookServicesPostgres) CreateSubscription(ctx context.Context, subscription SubscriptionRequest) (CreatedSubscriptionResponse, error) {
+	secret := subscription.Secret
+	if secret == "" {
+		var err error
+		if secret, err = GenerateSecret(); err != nil {
+			return CreatedSubscriptionResponse{}, err
+		}
+	}
+
+	now := time.Now()
+	query := "INSERT INTO webhook_subscriptions (url, secret, events, description, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $5) RETURNING " + subscriptionColumns
+	created, err := scanSubscription(wsp.DB.QueryRowContext(ctx, query, subscription.URL, secret, pq.Array(subscription.Events), subscription.Description, now))
+	if err != nil {
+		return CreatedSubscriptionResponse{}, err
+	}
+	logger.FromContext(ctx).Info("webhook subscription created", "subscription_id", created.ID, "url", created.URL, "events", created.Events)
+	return CreatedSubscriptionResponse{SubscriptionResponse: created, Secret: secret}, nil
+}
+
+func (wsp *WebhookServicesPostgres) GetAllSubscriptions(ctx context.Context) ([]SubscriptionResponse, error) {
+	rows, err := wsp.DB.QueryContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []SubscriptionResponse
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (wsp *WebhookServicesPostgres) GetSubscriptionByID(ctx context.Context, subscriptionID string) (SubscriptionResponse, error) {
+	subscription, err := scanSubscription(wsp.DB.QueryRowContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE id = $1", subscriptionID))
+	if err == sql.ErrNoRows {
+		return SubscriptionResponse{}, ErrSubscriptionNotFound
+	}
+	return subscription, err
+}
+
+func (wsp *WebhookServicesPostgres) UpdateSubscriptionByID(ctx context.Context, subscriptionID string, subscription SubscriptionRequest) (SubscriptionResponse, error) {
+	query := "UPDATE webhook_subscriptions SET url = $1, secret = COALESCE(NULLIF($2, ''), secret), events = $3, description = $4, updated_at = $5 WHERE id = $6 RETURNING " + subscriptionColumns
+	updated, err := scanSubscription(wsp.DB.QueryRowContext(ctx, query, subscription.URL, subscription.Secret, pq.Array(subscription.Events), subscription.Description, time.Now(), subscriptionID))
+	if err == sql.ErrNoRows {
+		return SubscriptionResponse{}, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return SubscriptionResponse{}, err
+	}
+	logger.FromContext(ctx).Info("webhook subscription updated", "subscription_id", updated.ID, "secret_rotated", subscription.Secret != "")
+	return updated, nil
+}
+
+// DeleteSubscriptionByID removes the subscription together with its
+// delivery log.
+func (wsp *WebhookServicesPostgres) DeleteSubscriptionByID(ctx context.Context, subscriptionID string) error {
+	result, err := wsp.DB.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", subscriptionID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	logger.FromContext(ctx).Info("webhook subscription deleted", "subscription_id", subscriptionID)
+	return nil
+}
+
+func (wsp *WebhookServicesPostgres) EnqueueEvent(ctx context.Context, event Event) (int, error) {
+	query := "INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at) " +
+		"SELECT id, $1, $2, $3, 'pending', 0, $4, $4 FROM webhook_subscriptions WHERE $2 = ANY(events) " +
+		"ON CONFLICT (subscription_id, event_id) DO NOTHING"
+	result, err := wsp.DB.ExecContext(ctx, query, event.ID, event.Type, string(event.Payload), time.Now())
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	logger.FromContext(ctx).Debug("webhook event enqueued", "event_id", event.ID, "event", event.Type, "deliveries", affected)
+	return int(affected), nil
+}
+
+func (wsp *WebhookServicesPostgres) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DueDelivery, error) {
+	rows, err := wsp.DB.QueryContext(ctx, claimDueDeliveriesQuery, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []DueDelivery
+	for rows.Next() {
+		var due DueDelivery
+		var payload []byte
+		if err := rows.Scan(append(deliveryFields(&due.Delivery, &payload), &due.URL, &due.Secret)...); err != nil {
+			return nil, err
+		}
+		due.Payload = payload
+		deliveries = append(deliveries, due)
+	}
+	return deliveries, rows.Err()
+}
+
+func (wsp *WebhookServicesPostgres) RecordAttempt(ctx context.Context, attempt DeliveryAttempt) error {
+	var lastError *string
+	if attempt.Error != "" {
+		lastError = &attempt.Error
+	}
+	var deliveredAt *time.Time
+	if attempt.Status == DeliverySucceeded {
+		deliveredAt = &attempt.AttemptedAt
+	}
+	// The lease check skips results of attempts that outlived their lease
+	// and were claimed by another dispatcher.
+	query := "UPDATE webhook_deliveries SET status = $1, attempts = $2, last_attempt_at = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7 " +
+		"WHERE id = $8 AND status = 'pending' AND next_attempt_at = $9"
+	_, err := wsp.DB.ExecContext(ctx, query, attempt.Status, attempt.Attempts, attempt.AttemptedAt, attempt.NextAttemptAt, attempt.StatusCode, lastError, deliveredAt, attempt.DeliveryID, attempt.LeasedUntil)
+	return err
+}
+
+func (wsp *WebhookServicesPostgres) GetDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.SubscriptionID != "" {
+		args = append(args, filter.SubscriptionID)
+		conditions = append(conditions, fmt.Sprintf("subscription_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := wsp.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (wsp *WebhookServicesPostgres) RetryDeliveryByID(ctx context.Context, deliveryID string) (Delivery, error) {
+	query := "UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = $1 WHERE id = $2 AND status = 'dead_lettered' RETURNING " + deliveryColumns
+	delivery, err := scanDelivery(wsp.DB.QueryRowContext(ctx, query, time.Now(), deliveryID))
+	if err != sql.ErrNoRows {
+		if err == nil {
+			logger.FromContext(ctx).Info("webhook delivery requeued", "delivery_id", delivery.ID, "event_id", delivery.EventID)
+		}
+		return delivery, err
+	}
+
+	var status string
+	err = wsp.DB.QueryRowContext(ctx, "SELECT status FROM webhook_deliveries WHERE id = $1", deliveryID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return Delivery{}, err
+	}
+	return Delivery{}, ErrDeliveryNotDeadLettered
+}
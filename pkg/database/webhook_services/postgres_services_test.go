@@ -0,0 +1,278 @@
+package webhookservices
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	subscriptionColumnNames = []string{"id", "url", "events", "description", "created_at", "updated_at"}
+	deliveryColumnNames     = []string{"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at", "last_attempt_at", "last_status_code", "last_error", "delivered_at", "created_at"}
+)
+
+func TestCreateSubscription(t *testing.T) {
+	tests := []struct {
+		name    string
+		request SubscriptionRequest
+		sqlErr  error
+	}{
+		{
+			name:    "CreateSubscription_GeneratedSecret",
+			request: SubscriptionRequest{URL: "https://example.com/hook", Events: []string{"book.created"}},
+		},
+		{
+			name:    "CreateSubscription_GivenSecret",
+			request: SubscriptionRequest{URL: "https://example.com/hook", Secret: "0123456789abcdef", Events: []string{"book.created"}},
+		},
+		{
+			name:    "CreateSubscription_Failure",
+			request: SubscriptionRequest{URL: "https://example.com/hook", Events: []string{"book.created"}},
+			sqlErr:  errors.New("insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			wsp := NewWebhookServicesPostgres(db)
+
+			secret := interface{}(sqlmock.AnyArg())
+			if tt.request.Secret != "" {
+				secret = tt.request.Secret
+			}
+			expectation := mock.ExpectQuery("INSERT INTO webhook_subscriptions").
+				WithArgs(tt.request.URL, secret, sqlmock.AnyArg(), "", sqlmock.AnyArg())
+			if tt.sqlErr != nil {
+				expectation.WillReturnError(tt.sqlErr)
+			} else {
+				expectation.WillReturnRows(sqlmock.NewRows(subscriptionColumnNames).
+					AddRow(1, tt.request.URL, "{book.created}", "", time.Now(), time.Now()))
+			}
+
+			created, err := wsp.CreateSubscription(context.Background(), tt.request)
+			if tt.sqlErr != nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"book.created"}, created.Events)
+				if tt.request.Secret != "" {
+					assert.Equal(t, tt.request.Secret, created.Secret)
+				} else {
+					assert.True(t, strings.HasPrefix(created.Secret, secretPrefix))
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetSubscriptionByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	wsp := NewWebhookServicesPostgres(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions WHERE id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(subscriptionColumnNames).
+			AddRow(1, "https://example.com/hook", "{book.created,book.deleted}", "catalogue sync", time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions WHERE id = \\$1").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows(subscriptionColumnNames))
+
+	subscription, err := wsp.GetSubscriptionByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"book.created", "book.deleted"}, subscription.Events)
+
+	_, err = wsp.GetSubscriptionByID(context.Background(), "2")
+	assert.Equal(t, ErrSubscriptionNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSubscriptionByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "DeleteSubscriptionByID_Success", affected: 1},
+		{name: "DeleteSubscriptionByID_NotFound", affected: 0, wantErr: ErrSubscriptionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			wsp := NewWebhookServicesPostgres(db)
+
+			mock.ExpectExec("DELETE FROM webhook_subscriptions WHERE id = \\$1").
+				WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err = wsp.DeleteSubscriptionByID(context.Background(), "1")
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestEnqueueEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	wsp := NewWebhookServicesPostgres(db)
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries (.+) FROM webhook_subscriptions WHERE \\$2 = ANY\\(events\\) ON CONFLICT").
+		WithArgs("evt_1", "book.created", `{"id":"evt_1"}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	enqueued, err := wsp.EnqueueEvent(context.Background(), Event{ID: "evt_1", Type: "book.created", Payload: []byte(`{"id":"evt_1"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, enqueued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimDueDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	wsp := NewWebhookServicesPostgres(db)
+	now := time.Now()
+
+	mock.ExpectQuery("FOR UPDATE SKIP LOCKED").
+		WithArgs(now, 10, now.Add(time.Minute)).
+		WillReturnRows(sqlmock.NewRows(append(deliveryColumnNames, "url", "secret")).
+			AddRow(7, 1, "evt_1", "book.created", `{"id":"evt_1"}`, "pending", 2, now, nil, nil, nil, nil, now, "https://example.com/hook", "whsec_test"))
+
+	deliveries, err := wsp.ClaimDueDeliveries(context.Background(), now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, uint(7), deliveries[0].ID)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, "whsec_test", deliveries[0].Secret)
+	assert.JSONEq(t, `{"id":"evt_1"}`, string(deliveries[0].Payload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	wsp := NewWebhookServicesPostgres(db)
+	now := time.Now()
+	leasedUntil := now.Add(20 * time.Second)
+	code := 200
+
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$1.* WHERE id = \\$8 AND status = 'pending' AND next_attempt_at = \\$9").
+		WithArgs(DeliverySucceeded, 1, now, sqlmock.AnyArg(), &code, nil, &now, 7, leasedUntil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = wsp.RecordAttempt(context.Background(), DeliveryAttempt{DeliveryID: 7, LeasedUntil: leasedUntil, Status: DeliverySucceeded, Attempts: 1, AttemptedAt: now, StatusCode: &code})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeliveries(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter DeliveryFilter
+		query  string
+		args   []driver.Value
+	}{
+		{
+			name:   "GetDeliveries_All",
+			filter: DeliveryFilter{Limit: 50},
+			query:  "FROM webhook_deliveries ORDER BY id DESC LIMIT \\$1",
+			args:   []driver.Value{50},
+		},
+		{
+			name:   "GetDeliveries_Filtered",
+			filter: DeliveryFilter{SubscriptionID: "1", Status: DeliveryDeadLettered, Limit: 50},
+			query:  "WHERE subscription_id = \\$1 AND status = \\$2 ORDER BY id DESC LIMIT \\$3",
+			args:   []driver.Value{"1", DeliveryDeadLettered, 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			wsp := NewWebhookServicesPostgres(db)
+
+			mock.ExpectQuery(tt.query).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows(deliveryColumnNames).
+					AddRow(7, 1, "evt_1", "book.created", `{}`, DeliveryDeadLettered, 8, time.Now(), time.Now(), 500, "receiver returned 500", nil, time.Now()))
+
+			deliveries, err := wsp.GetDeliveries(context.Background(), tt.filter)
+			assert.NoError(t, err)
+			assert.Len(t, deliveries, 1)
+			assert.Equal(t, 500, *deliveries[0].LastStatusCode)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRetryDeliveryByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		retried bool
+		status  string
+		wantErr error
+	}{
+		{name: "RetryDeliveryByID_Success", retried: true},
+		{name: "RetryDeliveryByID_NotDeadLettered", status: DeliverySucceeded, wantErr: ErrDeliveryNotDeadLettered},
+		{name: "RetryDeliveryByID_NotFound", wantErr: ErrDeliveryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			wsp := NewWebhookServicesPostgres(db)
+
+			rows := sqlmock.NewRows(deliveryColumnNames)
+			if tt.retried {
+				rows.AddRow(7, 1, "evt_1", "book.created", `{}`, DeliveryPending, 0, time.Now(), time.Now(), 500, "receiver returned 500", nil, time.Now())
+			}
+			mock.ExpectQuery("UPDATE webhook_deliveries SET status = 'pending'").
+				WithArgs(sqlmock.AnyArg(), "7").
+				WillReturnRows(rows)
+			if !tt.retried {
+				statusRows := sqlmock.NewRows([]string{"status"})
+				if tt.status != "" {
+					statusRows.AddRow(tt.status)
+				}
+				mock.ExpectQuery("SELECT status FROM webhook_deliveries").WithArgs("7").WillReturnRows(statusRows)
+			}
+
+			delivery, err := wsp.RetryDeliveryByID(context.Background(), "7")
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, DeliveryPending, delivery.Status)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package webhookservices
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+const secretPrefix = "whsec_"
+
+var (
+	ErrSubscriptionNotFound    = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound        = errors.New("webhook delivery not found")
+	ErrDeliveryNotDeadLettered = errors.New("webhook delivery is not dead-lettered")
+)
+
+// GenerateSecret returns a new random signing secret.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,159 @@
+package graphqlapi
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+type Handler struct {
+	Schema   graphql.Schema
+	Services Services
+	Limits   Limits
+	// Authorize builds the per-request scope check; nil allows everything.
+	Authorize func(c *gin.Context) Authorizer
+}
+
+func NewHandler(services Services, limits Limits, authorize func(c *gin.Context) Authorizer) (*Handler, error) {
+	schema, err := NewSchema(services)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{Schema: schema, Services: services, Limits: limits, Authorize: authorize}, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Serve executes a query sent as a GET query string or as a POST body of
+// application/json or application/graphql. Mutations are only accepted over
+// POST.
+func (h *Handler) Serve(c *gin.Context) {
+	req, ok := readRequest(c)
+	if !ok {
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		c.JSON(http.StatusOK, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if validation := graphql.ValidateDocument(&h.Schema, doc, nil); !validation.IsValid {
+		c.JSON(http.StatusOK, &graphql.Result{Errors: validation.Errors})
+		return
+	}
+	if c.Request.Method == http.MethodGet && isMutation(doc, req.OperationName) {
+		c.Header("Allow", http.MethodPost)
+		problem.Abort(c, problem.New(http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Mutations must be sent with POST."))
+		return
+	}
+	if err := checkLimits(&h.Schema, doc, req.OperationName, h.Limits); err != nil {
+		c.JSON(http.StatusOK, &graphql.Result{Errors: []gqlerrors.FormattedError{{Message: err.Message, Extensions: err.Extensions()}}})
+		return
+	}
+
+	var authorizer Authorizer
+	if h.Authorize != nil {
+		authorizer = h.Authorize(c)
+	}
+	ctx := withState(c.Request.Context(), newRequestState(h.Services, authorizer))
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.Schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	restoreExtensions(result.Errors)
+	c.JSON(http.StatusOK, result)
+}
+
+// restoreExtensions puts back the extensions of *Error values returned from
+// thunks, which graphql-go drops while wrapping them.
+func restoreExtensions(errs []gqlerrors.FormattedError) {
+	for i := range errs {
+		if errs[i].Extensions != nil {
+			continue
+		}
+		if e := originalError(errs[i].OriginalError()); e != nil {
+			errs[i].Extensions = e.Extensions()
+		}
+	}
+}
+
+func originalError(err error) *Error {
+	for err != nil {
+		switch e := err.(type) {
+		case *Error:
+			return e
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func readRequest(c *gin.Context) (request, bool) {
+	var req request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeBadRequest, "variables must be a JSON object."))
+				return req, false
+			}
+		}
+	} else {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		switch mediaType {
+		case "application/graphql":
+			body, err := c.GetRawData()
+			if err != nil {
+				problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
+				return req, false
+			}
+			req.Query = string(body)
+		case "application/json", "":
+			if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+				problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
+				return req, false
+			}
+		default:
+			problem.Abort(c, problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMedia, "Send application/json or application/graphql."))
+			return req, false
+		}
+	}
+	if req.Query == "" {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeBadRequest, "A query is required."))
+		return req, false
+	}
+	return req, true
+}
+
+func isMutation(doc *ast.Document, operationName string) bool {
+	for _, definition := range doc.Definitions {
+		if op, ok := definition.(*ast.OperationDefinition); ok {
+			if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+				return op.Operation == ast.OperationTypeMutation
+			}
+		}
+	}
+	return false
+}
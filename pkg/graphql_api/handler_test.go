@@ -0,0 +1,268 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+type MockBookService struct {
+	mock.Mock
+}
+
+func (m *MockBookService) CreateBook(ctx context.Context, book bookservices.BookRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, book)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetAllBooks(ctx context.Context) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBookByID(ctx context.Context, bookID string) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookIDs)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) UpdateBookByID(ctx context.Context, bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID, book)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) DeleteBookByID(ctx context.Context, bookID string) error {
+	args := m.Called(ctx, bookID)
+	return args.Error(0)
+}
+
+var testBooks = []bookservices.BookResponse{
+	{ID: 1, Name: "Dune", Author: "Frank Herbert", Publication: "Chilton", CreatedAt: time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)},
+	{ID: 2, Name: "Children of Dune", Author: "Frank Herbert", Publication: "Putnam"},
+	{ID: 3, Name: "Neuromancer", Author: "William Gibson", Publication: "Ace"},
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newTestRouter(t *testing.T, service *MockBookService, limits Limits, authorize func(c *gin.Context) Authorizer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler, err := NewHandler(Services{Books: service}, limits, authorize)
+	assert.NoError(t, err)
+	router := gin.New()
+	router.GET("/graphql", handler.Serve)
+	router.POST("/graphql", handler.Serve)
+	return router
+}
+
+func post(router *gin.Engine, query string, variables map[string]interface{}, headers ...string) (*httptest.ResponseRecorder, response) {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var resp response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestQueryBooksWithAuthorsLoadsOnce(t *testing.T) {
+	service := new(MockBookService)
+	// Listing the books and every author's books takes two calls, however
+	// many books and authors there are.
+	service.On("GetAllBooks", mock.Anything).Return(testBooks, nil).Twice()
+	router := newTestRouter(t, service, Limits{}, nil)
+
+	w, resp := post(router, `{ books { id name createdAt author { name books { name } } } }`, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Errors)
+	books := resp.Data["books"].([]interface{})
+	assert.Len(t, books, 3)
+	first := books[0].(map[string]interface{})
+	assert.Equal(t, "1", first["id"])
+	assert.Equal(t, "2026-10-19T08:00:00Z", first["createdAt"])
+	author := first["author"].(map[string]interface{})
+	assert.Equal(t, "Frank Herbert", author["name"])
+	assert.Len(t, author["books"], 2)
+	service.AssertExpectations(t)
+}
+
+func TestQueryBookByID(t *testing.T) {
+	service := new(MockBookService)
+	service.On("GetBooksByIDs", mock.Anything, []string{"3"}).Return([]bookservices.BookResponse{testBooks[2]}, nil).Once()
+	service.On("GetBooksByIDs", mock.Anything, []string{"9"}).Return([]bookservices.BookResponse(nil), nil).Once()
+	service.On("GetBooksByIDs", mock.Anything, []string{"8"}).Return([]bookservices.BookResponse(nil), errors.New("connection refused")).Once()
+	router := newTestRouter(t, service, Limits{}, nil)
+
+	_, resp := post(router, `query($id: ID!) { book(id: $id) { name } }`, map[string]interface{}{"id": "3"})
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{"name": "Neuromancer"}, resp.Data["book"])
+
+	_, resp = post(router, `{ book(id: 9) { name } }`, nil)
+	assert.Nil(t, resp.Data["book"])
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, "book_not_found", resp.Errors[0].Extensions["code"])
+
+	_, resp = post(router, `{ book(id: 8) { name } }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, "internal_error", resp.Errors[0].Extensions["code"])
+
+	// IDs that cannot name a book never reach the service.
+	_, resp = post(router, `{ a: book(id: "abc") { name } b: book(id: "0") { name } }`, nil)
+	assert.Len(t, resp.Errors, 2)
+	for _, err := range resp.Errors {
+		assert.Equal(t, "book_not_found", err.Extensions["code"])
+	}
+
+	// Several lookups in one query are served by one call, whatever the
+	// spelling of the IDs.
+	bothIDs := mock.MatchedBy(func(ids []string) bool {
+		ids = slices.Clone(ids)
+		slices.Sort(ids)
+		return slices.Equal(ids, []string{"1", "2"})
+	})
+	service.On("GetBooksByIDs", mock.Anything, bothIDs).Return([]bookservices.BookResponse{testBooks[0], testBooks[1]}, nil).Once()
+	_, resp = post(router, `{ a: book(id: "01") { name } b: book(id: 2) { name } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{"name": "Dune"}, resp.Data["a"])
+	assert.Equal(t, map[string]interface{}{"name": "Children of Dune"}, resp.Data["b"])
+	service.AssertExpectations(t)
+}
+
+func TestMutations(t *testing.T) {
+	service := new(MockBookService)
+	service.On("CreateBook", mock.Anything, bookservices.BookRequest{Name: "Dune", Author: "Frank Herbert", Publication: "Chilton"}).Return(testBooks[0], nil).Once()
+	service.On("UpdateBookByID", mock.Anything, "1", bookservices.BookUpdateRequest{Name: "Dune Messiah", Author: "Frank Herbert", Publication: "Putnam"}).Return(testBooks[0], nil).Once()
+	service.On("DeleteBookByID", mock.Anything, "1").Return(nil).Once()
+	router := newTestRouter(t, service, Limits{}, nil)
+
+	_, resp := post(router, `mutation { createBook(input: {name: " Dune ", author: "Frank Herbert", publication: "Chilton"}) { id } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{"id": "1"}, resp.Data["createBook"])
+
+	_, resp = post(router, `mutation { updateBook(id: 1, input: {name: "Dune Messiah", author: "Frank Herbert", publication: "Putnam"}) { id } }`, nil)
+	assert.Empty(t, resp.Errors)
+
+	_, resp = post(router, `mutation { deleteBook(id: 1) }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, true, resp.Data["deleteBook"])
+
+	_, resp = post(router, `mutation { createBook(input: {name: "  ", author: "x", publication: "y"}) { id } }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, "validation_failed", resp.Errors[0].Extensions["code"])
+
+	_, resp = post(router, `mutation { deleteBook(id: "abc") }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, "book_not_found", resp.Errors[0].Extensions["code"])
+	service.AssertExpectations(t)
+}
+
+func TestMutationOverGETIsRejected(t *testing.T) {
+	router := newTestRouter(t, new(MockBookService), Limits{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { deleteBook(id: 1) }`), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLimits(t *testing.T) {
+	router := newTestRouter(t, new(MockBookService), Limits{MaxDepth: 3, MaxComplexity: 50, ListFactor: 10}, nil)
+
+	_, resp := post(router, `{ books { author { books { author { name } } } } }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, CodeQueryTooDeep, resp.Errors[0].Extensions["code"])
+
+	// Fragments count as if inlined: books 1 + 10*(id 1 + author 1 + books 1 + 10*(id 1 + name 1)) = 231.
+	router = newTestRouter(t, new(MockBookService), Limits{MaxComplexity: 50, ListFactor: 10}, nil)
+	_, resp = post(router, `{ books { ...fields } } fragment fields on Book { id author { books { id name } } }`, nil)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, CodeQueryTooComplex, resp.Errors[0].Extensions["code"])
+	assert.Contains(t, resp.Errors[0].Message, "complexity 231")
+
+	// Introspection is not limited.
+	service := new(MockBookService)
+	router = newTestRouter(t, service, Limits{MaxDepth: 2, MaxComplexity: 5, ListFactor: 10}, nil)
+	_, resp = post(router, `{ __schema { types { name fields { name type { name ofType { name } } } } } }`, nil)
+	assert.Empty(t, resp.Errors)
+}
+
+func TestScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := new(MockBookService)
+	service.On("GetAllBooks", mock.Anything).Return(testBooks, nil)
+	apiKeys := new(MockAPIKeyService)
+	apiKeys.On("AuthenticateAPIKey", mock.Anything, "bks_read").Return(apikeyservices.APIKeyResponse{Scopes: []string{apikeyservices.ScopeBooksRead}}, nil)
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(apiKeys, true, "")
+
+	handler, err := NewHandler(Services{Books: service}, Limits{}, APIKeyAuthorizer(apiKeyMiddleware))
+	assert.NoError(t, err)
+	router := gin.New()
+	router.POST("/graphql", apiKeyMiddleware.Authenticate(), handler.Serve)
+
+	_, resp := post(router, `{ books { id } }`, nil)
+	assert.Equal(t, "api_key_required", resp.Errors[0].Extensions["code"])
+
+	_, resp = post(router, `{ books { id } }`, nil, "X-API-Key", "bks_read")
+	assert.Empty(t, resp.Errors)
+
+	_, resp = post(router, `mutation { deleteBook(id: 1) }`, nil, "X-API-Key", "bks_read")
+	assert.Equal(t, "insufficient_scope", resp.Errors[0].Extensions["code"])
+	service.AssertNotCalled(t, "DeleteBookByID", mock.Anything, mock.Anything)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) IssueAPIKey(ctx context.Context, apiKey apikeyservices.APIKeyRequest) (apikeyservices.IssuedAPIKeyResponse, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(apikeyservices.IssuedAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAllAPIKeys(ctx context.Context) ([]apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error {
+	args := m.Called(ctx, apiKeyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(apikeyservices.APIKeyResponse), args.Error(1)
+}
@@ -0,0 +1,150 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bound the work a single operation may ask for. Introspection
+// fields (__schema, __type, ...) are not counted so GraphiQL keeps working.
+type Limits struct {
+	// MaxDepth is the deepest allowed field nesting; top-level fields are 1.
+	MaxDepth int
+	// MaxComplexity caps the operation cost: every field costs 1 and the
+	// selections under a list field are multiplied by ListFactor.
+	MaxComplexity int
+	ListFactor    int
+}
+
+const (
+	CodeQueryTooDeep    = "query_too_deep"
+	CodeQueryTooComplex = "query_too_complex"
+)
+
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// checkLimits measures the selected operation of an already validated
+// document against l, zero limits being unlimited.
+func checkLimits(schema *graphql.Schema, doc *ast.Document, operationName string, l Limits) *Error {
+	var operation *ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	m := measurer{schema: schema, fragments: fragments, listFactor: l.ListFactor, visiting: map[string]bool{}}
+	c := m.selectionSet(operation.SelectionSet, root)
+
+	if l.MaxDepth > 0 && c.depth > l.MaxDepth {
+		return &Error{Message: fmt.Sprintf("The query has depth %d, the limit is %d.", c.depth, l.MaxDepth), Code: CodeQueryTooDeep}
+	}
+	if l.MaxComplexity > 0 && c.complexity > l.MaxComplexity {
+		return &Error{Message: fmt.Sprintf("The query has complexity %d, the limit is %d.", c.complexity, l.MaxComplexity), Code: CodeQueryTooComplex}
+	}
+	return nil
+}
+
+type measurer struct {
+	schema     *graphql.Schema
+	fragments  map[string]*ast.FragmentDefinition
+	listFactor int
+	visiting   map[string]bool
+}
+
+func (m measurer) selectionSet(set *ast.SelectionSet, parent graphql.Type) cost {
+	var total cost
+	if set == nil {
+		return total
+	}
+	for _, selection := range set.Selections {
+		var c cost
+		switch sel := selection.(type) {
+		case *ast.Field:
+			c = m.field(sel, parent)
+		case *ast.InlineFragment:
+			typ := parent
+			if sel.TypeCondition != nil {
+				typ = m.schema.Type(sel.TypeCondition.Name.Value)
+			}
+			c = m.selectionSet(sel.SelectionSet, typ)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := m.fragments[name]
+			if !ok || m.visiting[name] {
+				continue
+			}
+			m.visiting[name] = true
+			c = m.selectionSet(fragment.SelectionSet, m.schema.Type(fragment.TypeCondition.Name.Value))
+			delete(m.visiting, name)
+		}
+		total.depth = max(total.depth, c.depth)
+		total.complexity += c.complexity
+	}
+	return total
+}
+
+func (m measurer) field(field *ast.Field, parent graphql.Type) cost {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return cost{}
+	}
+
+	var fieldType graphql.Type
+	if fields, ok := parent.(fieldsGetter); ok {
+		if def, ok := fields.Fields()[name]; ok {
+			fieldType = def.Type
+		}
+	}
+	children := m.selectionSet(field.SelectionSet, unwrap(fieldType))
+	if isList(fieldType) && m.listFactor > 1 {
+		children.complexity *= m.listFactor
+	}
+	return cost{depth: children.depth + 1, complexity: children.complexity + 1}
+}
+
+// fieldsGetter is implemented by *graphql.Object and *graphql.Interface.
+type fieldsGetter interface {
+	Fields() graphql.FieldDefinitionMap
+}
+
+// unwrap strips NonNull and List wrappers.
+func unwrap(t graphql.Type) graphql.Type {
+	for {
+		switch typ := t.(type) {
+		case *graphql.NonNull:
+			t = typ.OfType
+		case *graphql.List:
+			t = typ.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
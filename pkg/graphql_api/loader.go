@@ -0,0 +1,90 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is returned by a Loader for keys missing from the batch result.
+var ErrNotFound = errors.New("not found")
+
+// BatchFunc loads many keys in one call. Keys missing from the returned map
+// resolve to ErrNotFound.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type loaderResult[V any] struct {
+	value V
+	err   error
+}
+
+// Loader collects the keys requested while one level of a query is
+// resolved and fetches them with a single BatchFunc call when the first
+// result is needed, which turns N lookups into one. graphql-go resolves
+// thunks breadth-first, so every sibling has queued its key by then.
+// Results are cached for the loader's lifetime, one request.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]loaderResult[V]
+}
+
+func NewLoader[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{batch: batch, results: map[K]loaderResult[V]{}}
+}
+
+// Load queues key and returns a thunk that yields its value.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok && !l.isPending(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.results[key]; !ok {
+			l.dispatch(ctx)
+		}
+		result := l.results[key]
+		return result.value, result.err
+	}
+}
+
+// Prime stores a value fetched elsewhere, e.g. by a list query.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = loaderResult[V]{value: value}
+	}
+}
+
+func (l *Loader[K, V]) isPending(key K) bool {
+	for _, k := range l.pending {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch runs the batch for every pending key. l.mu must be held.
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		switch value, ok := values[key]; {
+		case err != nil:
+			l.results[key] = loaderResult[V]{err: err}
+		case !ok:
+			l.results[key] = loaderResult[V]{err: ErrNotFound}
+		default:
+			l.results[key] = loaderResult[V]{value: value}
+		}
+	}
+}
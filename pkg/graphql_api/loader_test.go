@@ -0,0 +1,54 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoaderBatchesAndCaches(t *testing.T) {
+	var batches [][]int
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := map[int]string{}
+		for _, key := range keys {
+			if key != 3 {
+				values[key] = string(rune('a' + key))
+			}
+		}
+		return values, nil
+	})
+	loader.Prime(9, "primed")
+
+	ctx := context.Background()
+	one, two, oneAgain, missing, primed := loader.Load(ctx, 1), loader.Load(ctx, 2), loader.Load(ctx, 1), loader.Load(ctx, 3), loader.Load(ctx, 9)
+
+	value, err := two()
+	assert.NoError(t, err)
+	assert.Equal(t, "c", value)
+	value, _ = one()
+	assert.Equal(t, "b", value)
+	value, _ = oneAgain()
+	assert.Equal(t, "b", value)
+	_, err = missing()
+	assert.ErrorIs(t, err, ErrNotFound)
+	value, _ = primed()
+	assert.Equal(t, "primed", value)
+
+	// A key loaded after the batch ran is fetched in a new batch, a cached
+	// one is not fetched again.
+	value, _ = loader.Load(ctx, 4)()
+	assert.Equal(t, "e", value)
+	_, _ = loader.Load(ctx, 2)()
+	assert.Equal(t, [][]int{{1, 2, 3}, {4}}, batches)
+}
+
+func TestLoaderError(t *testing.T) {
+	loader := NewLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, errors.New("database down")
+	})
+	_, err := loader.Load(context.Background(), "a")()
+	assert.EqualError(t, err, "database down")
+}
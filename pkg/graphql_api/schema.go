@@ -0,0 +1,342 @@
+// Package graphqlapi serves the book catalogue over GraphQL at /graphql.
+//
+// The schema is built from Services, the same service interfaces the REST
+// controllers use, so new services (stock, reviews, ...) plug in as new
+// fields. Authors are derived from the books' author column until they get
+// a service of their own.
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+)
+
+type Services struct {
+	Books bookservices.BookServicesInterface
+}
+
+// Author is the GraphQL author object, keyed by name.
+type Author struct {
+	Name string
+}
+
+// Error is a resolver error whose code is exposed in extensions.code. Codes
+// are the same stable codes as the REST problem responses.
+type Error struct {
+	Message string
+	Code    string
+	Fields  []problem.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if len(e.Fields) > 0 {
+		extensions["errors"] = e.Fields
+	}
+	return extensions
+}
+
+// internalError logs err and hides it from the client.
+func internalError(ctx context.Context, err error) error {
+	logger.FromContext(ctx).Error("graphql resolver failed", "error", err)
+	return &Error{Message: "An unexpected error occurred.", Code: problem.CodeInternal}
+}
+
+func NewSchema(services Services) (graphql.Schema, error) {
+	r := resolver{services: services}
+
+	var bookType *graphql.Object
+	authorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(Author).Name, nil
+					},
+				},
+				"books": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+					Resolve: r.authorBooks,
+				},
+			}
+		}),
+	})
+	bookType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Book",
+		Fields: graphql.Fields{
+			"id":          bookField(graphql.ID, func(b bookservices.BookResponse) interface{} { return bookID(b) }),
+			"name":        bookField(graphql.String, func(b bookservices.BookResponse) interface{} { return b.Name }),
+			"publication": bookField(graphql.String, func(b bookservices.BookResponse) interface{} { return b.Publication }),
+			"createdAt":   bookField(graphql.DateTime, func(b bookservices.BookResponse) interface{} { return b.CreatedAt }),
+			"updatedAt":   bookField(graphql.DateTime, func(b bookservices.BookResponse) interface{} { return b.UpdatedAt }),
+			"author": &graphql.Field{
+				Type: graphql.NewNonNull(authorType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return Author{Name: p.Source.(bookservices.BookResponse).Author}, nil
+				},
+			},
+		},
+	})
+
+	bookInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "BookInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"author":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"publication": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	idArg := graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"books": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+				Resolve: r.books,
+			},
+			"book": &graphql.Field{
+				Type:    bookType,
+				Args:    idArg,
+				Resolve: r.book,
+			},
+			"authors": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(authorType))),
+				Resolve: r.authors,
+			},
+			"author": &graphql.Field{
+				Type:    authorType,
+				Args:    graphql.FieldConfigArgument{"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: r.author,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createBook": &graphql.Field{
+				Type:    graphql.NewNonNull(bookType),
+				Args:    graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInput)}},
+				Resolve: r.createBook,
+			},
+			"updateBook": &graphql.Field{
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.FieldConfigArgument{
+					"id":    idArg["id"],
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInput)},
+				},
+				Resolve: r.updateBook,
+			},
+			"deleteBook": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArg,
+				Resolve: r.deleteBook,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func bookID(b bookservices.BookResponse) string {
+	return strconv.FormatUint(uint64(b.ID), 10)
+}
+
+// parseBookID returns id in the form bookID gives it, e.g. "1" for "01",
+// and false for anything that cannot name a book, which is answered as
+// missing without a trip to the database.
+func parseBookID(id string) (string, bool) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n == 0 {
+		return "", false
+	}
+	return strconv.FormatUint(n, 10), true
+}
+
+func bookNotFound(id string) error {
+	return &Error{Message: "Book " + id + " was not found.", Code: problem.CodeBookNotFound}
+}
+
+func bookField(t graphql.Output, get func(bookservices.BookResponse) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(t),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(bookservices.BookResponse)), nil
+		},
+	}
+}
+
+type resolver struct {
+	services Services
+}
+
+func (r resolver) books(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksRead); err != nil {
+		return nil, err
+	}
+	books, err := r.services.Books.GetAllBooks(p.Context)
+	if err != nil {
+		return nil, internalError(p.Context, err)
+	}
+	state := stateFrom(p.Context)
+	for _, book := range books {
+		state.books.Prime(bookID(book), book)
+	}
+	return books, nil
+}
+
+func (r resolver) book(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksRead); err != nil {
+		return nil, err
+	}
+	id := p.Args["id"].(string)
+	load := stateFrom(p.Context).books.Load(p.Context, id)
+	return func() (interface{}, error) {
+		book, err := load()
+		if errors.Is(err, ErrNotFound) {
+			return nil, bookNotFound(id)
+		}
+		if err != nil {
+			return nil, internalError(p.Context, err)
+		}
+		return book, nil
+	}, nil
+}
+
+func (r resolver) authors(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksRead); err != nil {
+		return nil, err
+	}
+	books, err := r.services.Books.GetAllBooks(p.Context)
+	if err != nil {
+		return nil, internalError(p.Context, err)
+	}
+	seen := map[string]bool{}
+	var authors []Author
+	for _, book := range books {
+		if !seen[book.Author] {
+			seen[book.Author] = true
+			authors = append(authors, Author{Name: book.Author})
+		}
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Name < authors[j].Name })
+	return authors, nil
+}
+
+func (r resolver) author(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksRead); err != nil {
+		return nil, err
+	}
+	name := p.Args["name"].(string)
+	load := stateFrom(p.Context).booksByAuthor.Load(p.Context, name)
+	return func() (interface{}, error) {
+		books, err := load()
+		if err != nil {
+			return nil, internalError(p.Context, err)
+		}
+		if len(books) == 0 {
+			return nil, nil
+		}
+		return Author{Name: name}, nil
+	}, nil
+}
+
+func (r resolver) authorBooks(p graphql.ResolveParams) (interface{}, error) {
+	load := stateFrom(p.Context).booksByAuthor.Load(p.Context, p.Source.(Author).Name)
+	return func() (interface{}, error) {
+		books, err := load()
+		if err != nil {
+			return nil, internalError(p.Context, err)
+		}
+		return books, nil
+	}, nil
+}
+
+func (r resolver) createBook(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksWrite); err != nil {
+		return nil, err
+	}
+	input := p.Args["input"].(map[string]interface{})
+	request := bookservices.BookRequest{
+		Name:        input["name"].(string),
+		Author:      input["author"].(string),
+		Publication: input["publication"].(string),
+	}
+	if err := validateInput(&request); err != nil {
+		return nil, err
+	}
+	book, err := r.services.Books.CreateBook(p.Context, request)
+	if err != nil {
+		return nil, internalError(p.Context, err)
+	}
+	logger.FromContext(p.Context).Info("book created", "book_id", book.ID)
+	return book, nil
+}
+
+func (r resolver) updateBook(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksWrite); err != nil {
+		return nil, err
+	}
+	id := p.Args["id"].(string)
+	if _, ok := parseBookID(id); !ok {
+		return nil, bookNotFound(id)
+	}
+	input := p.Args["input"].(map[string]interface{})
+	request := bookservices.BookUpdateRequest{
+		Name:        input["name"].(string),
+		Author:      input["author"].(string),
+		Publication: input["publication"].(string),
+	}
+	if err := validateInput(&request); err != nil {
+		return nil, err
+	}
+	book, err := r.services.Books.UpdateBookByID(p.Context, id, request)
+	if errors.Is(err, bookservices.ErrBookNotFound) {
+		return nil, bookNotFound(id)
+	}
+	if err != nil {
+		return nil, internalError(p.Context, err)
+	}
+	logger.FromContext(p.Context).Info("book updated", "book_id", book.ID)
+	return book, nil
+}
+
+func (r resolver) deleteBook(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, apikeyservices.ScopeBooksWrite); err != nil {
+		return nil, err
+	}
+	id := p.Args["id"].(string)
+	if _, ok := parseBookID(id); !ok {
+		return nil, bookNotFound(id)
+	}
+	if err := r.services.Books.DeleteBookByID(p.Context, id); err != nil {
+		return nil, internalError(p.Context, err)
+	}
+	logger.FromContext(p.Context).Info("book deleted", "book_id", id)
+	return true, nil
+}
+
+// validateInput applies the same trimming and rules as the REST bodies.
+func validateInput(v interface{}) error {
+	err := validation.Struct(v)
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		return &Error{Message: "The input is invalid.", Code: problem.CodeValidationFailed, Fields: validationErr.Fields}
+	}
+	return err
+}
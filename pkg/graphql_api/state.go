@@ -0,0 +1,117 @@
+package graphqlapi
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+// Authorizer reports whether the current request may use scope, returning
+// an *Error when it may not.
+type Authorizer func(scope string) error
+
+type stateKey struct{}
+
+// requestState holds what resolvers share within one request.
+type requestState struct {
+	authorize     Authorizer
+	books         *Loader[string, bookservices.BookResponse]
+	booksByAuthor *Loader[string, []bookservices.BookResponse]
+}
+
+func newRequestState(services Services, authorize Authorizer) *requestState {
+	return &requestState{
+		authorize:     authorize,
+		books:         NewLoader(booksBatch(services.Books)),
+		booksByAuthor: NewLoader(booksByAuthorBatch(services.Books)),
+	}
+}
+
+func withState(ctx context.Context, state *requestState) context.Context {
+	return context.WithValue(ctx, stateKey{}, state)
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	if state, ok := ctx.Value(stateKey{}).(*requestState); ok {
+		return state
+	}
+	return newRequestState(Services{}, nil)
+}
+
+func authorize(ctx context.Context, scope string) error {
+	state := stateFrom(ctx)
+	if state.authorize == nil {
+		return nil
+	}
+	return state.authorize(scope)
+}
+
+// booksBatch looks the books up with one GetBooksByIDs call. IDs that
+// cannot name a book are left out, so they load as ErrNotFound without
+// reaching the service.
+func booksBatch(service bookservices.BookServicesInterface) BatchFunc[string, bookservices.BookResponse] {
+	return func(ctx context.Context, ids []string) (map[string]bookservices.BookResponse, error) {
+		canonical := make(map[string]string, len(ids))
+		var bookIDs []string
+		for _, id := range ids {
+			if parsed, ok := parseBookID(id); ok {
+				canonical[id] = parsed
+				bookIDs = append(bookIDs, parsed)
+			}
+		}
+		found := make(map[string]bookservices.BookResponse, len(ids))
+		if len(bookIDs) == 0 {
+			return found, nil
+		}
+		books, err := service.GetBooksByIDs(ctx, bookIDs)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]bookservices.BookResponse, len(books))
+		for _, book := range books {
+			byID[bookID(book)] = book
+		}
+		for id, parsed := range canonical {
+			if book, ok := byID[parsed]; ok {
+				found[id] = book
+			}
+		}
+		return found, nil
+	}
+}
+
+// booksByAuthorBatch groups one GetAllBooks call by author. Every requested
+// author gets an entry, empty when they have no books.
+func booksByAuthorBatch(service bookservices.BookServicesInterface) BatchFunc[string, []bookservices.BookResponse] {
+	return func(ctx context.Context, names []string) (map[string][]bookservices.BookResponse, error) {
+		books, err := service.GetAllBooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		byAuthor := make(map[string][]bookservices.BookResponse, len(names))
+		for _, name := range names {
+			byAuthor[name] = []bookservices.BookResponse{}
+		}
+		for _, book := range books {
+			if list, ok := byAuthor[book.Author]; ok {
+				byAuthor[book.Author] = append(list, book)
+			}
+		}
+		return byAuthor, nil
+	}
+}
+
+// APIKeyAuthorizer checks scopes with the same rules as the REST routes.
+// Authenticate must run before the handler.
+func APIKeyAuthorizer(m *middlewares.APIKeyMiddleware) func(c *gin.Context) Authorizer {
+	return func(c *gin.Context) Authorizer {
+		return func(scope string) error {
+			if err := m.Allows(c, scope); err != nil {
+				return &Error{Message: err.Message, Code: err.Code}
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package grpcapi serves BookServicesInterface over gRPC, next to the REST
+// API and from the same binary. The protobuf contract lives in
+// proto/bookstore/v1/book.proto.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api --go-grpc_out=../.. --go-grpc_opt=module=github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api bookstore/v1/book.proto
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/grpc_api/bookstorev1"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type BookServer struct {
+	bookstorev1.UnimplementedBookServiceServer
+	BookService bookservices.BookServicesInterface
+}
+
+func NewBookServer(bookService bookservices.BookServicesInterface) *BookServer {
+	return &BookServer{
+		BookService: bookService,
+	}
+}
+
+func (bs *BookServer) CreateBook(ctx context.Context, req *bookstorev1.BookRequest) (*bookstorev1.Book, error) {
+	bookRequest := bookservices.BookRequest{
+		Name:        req.GetName(),
+		Author:      req.GetAuthor(),
+		Publication: req.GetPublication(),
+	}
+	if err := validateRequest(&bookRequest); err != nil {
+		return nil, err
+	}
+	book, err := bs.BookService.CreateBook(ctx, bookRequest)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	logger.FromContext(ctx).Info("book created", "book_id", book.ID)
+	return toProto(book), nil
+}
+
+func (bs *BookServer) GetAllBooks(ctx context.Context, req *bookstorev1.GetAllBooksRequest) (*bookstorev1.GetAllBooksResponse, error) {
+	books, err := bs.BookService.GetAllBooks(ctx)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	resp := &bookstorev1.GetAllBooksResponse{Books: make([]*bookstorev1.Book, 0, len(books))}
+	for _, book := range books {
+		resp.Books = append(resp.Books, toProto(book))
+	}
+	return resp, nil
+}
+
+func (bs *BookServer) GetBookByID(ctx context.Context, req *bookstorev1.GetBookByIDRequest) (*bookstorev1.Book, error) {
+	bookID := strconv.FormatUint(req.GetId(), 10)
+	book, err := bs.BookService.GetBookByID(ctx, bookID)
+	if err != nil {
+		return nil, bookError(ctx, bookID, err)
+	}
+	return toProto(book), nil
+}
+
+func (bs *BookServer) UpdateBookByID(ctx context.Context, req *bookstorev1.UpdateBookByIDRequest) (*bookstorev1.Book, error) {
+	bookID := strconv.FormatUint(req.GetId(), 10)
+	bookUpdateRequest := bookservices.BookUpdateRequest{
+		Name:        req.GetBook().GetName(),
+		Author:      req.GetBook().GetAuthor(),
+		Publication: req.GetBook().GetPublication(),
+	}
+	if err := validateRequest(&bookUpdateRequest); err != nil {
+		return nil, err
+	}
+	book, err := bs.BookService.UpdateBookByID(ctx, bookID, bookUpdateRequest)
+	if err != nil {
+		return nil, bookError(ctx, bookID, err)
+	}
+	logger.FromContext(ctx).Info("book updated", "book_id", book.ID)
+	return toProto(book), nil
+}
+
+func (bs *BookServer) DeleteBookByID(ctx context.Context, req *bookstorev1.DeleteBookByIDRequest) (*bookstorev1.DeleteBookByIDResponse, error) {
+	bookID := strconv.FormatUint(req.GetId(), 10)
+	if err := bs.BookService.DeleteBookByID(ctx, bookID); err != nil {
+		return nil, internalError(ctx, err)
+	}
+	logger.FromContext(ctx).Info("book deleted", "book_id", bookID)
+	return &bookstorev1.DeleteBookByIDResponse{}, nil
+}
+
+func toProto(book bookservices.BookResponse) *bookstorev1.Book {
+	return &bookstorev1.Book{
+		Id:          uint64(book.ID),
+		Name:        book.Name,
+		Author:      book.Author,
+		Publication: book.Publication,
+		CreatedAt:   timestamppb.New(book.CreatedAt),
+		UpdatedAt:   timestamppb.New(book.UpdatedAt),
+	}
+}
+
+// validateRequest applies the REST validation rules and reports failures as
+// InvalidArgument with a BadRequest detail listing every field.
+func validateRequest(v interface{}) error {
+	err := validation.Struct(v)
+	var validationErr *validation.Error
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range validationErr.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+	st, detailErr := status.New(codes.InvalidArgument, "The request is invalid.").WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+	return st.Err()
+}
+
+// bookError returns NotFound for a missing book and Internal for anything
+// else.
+func bookError(ctx context.Context, bookID string, err error) error {
+	if errors.Is(err, bookservices.ErrBookNotFound) {
+		return status.Errorf(codes.NotFound, "Book %s was not found.", bookID)
+	}
+	return internalError(ctx, err)
+}
+
+// internalError logs err and returns an Internal status that does not leak it.
+func internalError(ctx context.Context, err error) error {
+	logger.FromContext(ctx).Error("grpc request failed", "error", err)
+	return status.Error(codes.Internal, "An unexpected error occurred.")
+}
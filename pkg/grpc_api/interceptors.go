@@ -0,0 +1,197 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/auth"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/grpc_api/bookstorev1"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadataKey carries the request ID in both directions, like the
+// X-Request-ID header of the REST API.
+const RequestIDMetadataKey = "x-request-id"
+
+// RequestLogger reuses the caller's request ID or generates one, attaches a
+// logger carrying it to the context and logs one record per call.
+func RequestLogger(base *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := logger.RequestID(firstMetadata(ctx, RequestIDMetadataKey))
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, requestID))
+
+		log := base.With("request_id", requestID)
+		ctx = logger.WithContext(logger.WithRequestID(ctx, requestID), log)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+		log.Log(ctx, level, "grpc request completed",
+			"method", info.FullMethod,
+			"code", code.String(),
+			"latency_ms", time.Since(start).Milliseconds(),
+		)
+		return resp, err
+	}
+}
+
+// Recovery turns a panicking handler into an Internal error.
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(ctx).Error("grpc handler panicked", "method", info.FullMethod, "panic", r)
+				err = status.Error(codes.Internal, "An unexpected error occurred.")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// methodScopes lists the scope each BookService method needs. Methods not
+// listed here (health, reflection) are not authenticated.
+var methodScopes = map[string]string{
+	bookstorev1.BookService_CreateBook_FullMethodName:     apikeyservices.ScopeBooksWrite,
+	bookstorev1.BookService_GetAllBooks_FullMethodName:    apikeyservices.ScopeBooksRead,
+	bookstorev1.BookService_GetBookByID_FullMethodName:    apikeyservices.ScopeBooksRead,
+	bookstorev1.BookService_UpdateBookByID_FullMethodName: apikeyservices.ScopeBooksWrite,
+	bookstorev1.BookService_DeleteBookByID_FullMethodName: apikeyservices.ScopeBooksWrite,
+}
+
+// APIKeyAuth applies the REST API key rules to gRPC calls. The key is read
+// from "x-api-key" or "authorization: Bearer|ApiKey <key>" metadata.
+type APIKeyAuth struct {
+	APIKeyService apikeyservices.APIKeyServicesInterface
+	// Required rejects calls without a key.
+	Required bool
+	// AdminKey is the bootstrap key, granted the admin scope.
+	AdminKey string
+}
+
+func (a APIKeyAuth) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		scope, ok := methodScopes[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		rawKey := auth.KeyFromHeaders(firstMetadata(ctx, "x-api-key"), firstMetadata(ctx, "authorization"))
+		if rawKey == "" {
+			if a.Required {
+				return nil, status.Error(codes.Unauthenticated, "An API key is required.")
+			}
+			return handler(ctx, req)
+		}
+
+		apiKey, err := auth.Authenticator{APIKeyService: a.APIKeyService, AdminKey: a.AdminKey}.Authenticate(ctx, rawKey)
+		if auth.IsRejected(err) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if err != nil {
+			return nil, internalError(ctx, err)
+		}
+		if !apiKey.HasScope(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "The API key is missing scope %s.", scope)
+		}
+		return handler(context.WithValue(ctx, apiKeyContextKey{}, apiKey), req)
+	}
+}
+
+type apiKeyContextKey struct{}
+
+// methodRoutes names each BookService method after its REST route, so the
+// configured route limits apply to both and share their buckets. Methods
+// not listed here (health, reflection) are not rate limited.
+var methodRoutes = map[string]string{
+	bookstorev1.BookService_CreateBook_FullMethodName:     "POST /books/",
+	bookstorev1.BookService_GetAllBooks_FullMethodName:    "GET /books/",
+	bookstorev1.BookService_GetBookByID_FullMethodName:    "GET /books/:bookID",
+	bookstorev1.BookService_UpdateBookByID_FullMethodName: "PUT /books/:bookID",
+	bookstorev1.BookService_DeleteBookByID_FullMethodName: "DELETE /books/:bookID",
+}
+
+// RateLimit applies the REST rate limits to gRPC calls. Callers are told
+// apart by API key, or else by peer address, like the HTTP middleware.
+type RateLimit struct {
+	Store        ratelimit.Store
+	DefaultLimit ratelimit.Limit
+	RouteLimits  map[string]ratelimit.Limit
+}
+
+func (r RateLimit) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		route, ok := methodRoutes[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		limit, ok := r.RouteLimits[route]
+		if !ok {
+			limit = r.DefaultLimit
+		}
+
+		result, err := r.Store.Take(route+"|"+clientKey(ctx), limit)
+		if err != nil {
+			// Fail open: an unavailable limiter store should not take the API down.
+			logger.FromContext(ctx).Error("rate limit store error", "error", err)
+			return handler(ctx, req)
+		}
+
+		header := metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(result.Limit),
+			"ratelimit-remaining", strconv.Itoa(result.Remaining),
+			"ratelimit-reset", strconv.Itoa(ratelimit.Seconds(result.Reset)),
+		)
+		if !result.Allowed {
+			retryAfter := ratelimit.Seconds(result.RetryAfter)
+			header.Set("retry-after", strconv.Itoa(retryAfter))
+			_ = grpc.SetHeader(ctx, header)
+			return nil, status.Errorf(codes.ResourceExhausted, "Rate limit exceeded, retry after %d seconds.", retryAfter)
+		}
+		_ = grpc.SetHeader(ctx, header)
+		return handler(ctx, req)
+	}
+}
+
+func clientKey(ctx context.Context) string {
+	if apiKey, ok := ctx.Value(apiKeyContextKey{}).(apikeyservices.APIKeyResponse); ok {
+		return auth.ClientKey(&apiKey, "")
+	}
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return auth.ClientKey(nil, ip)
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
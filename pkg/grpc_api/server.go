@@ -0,0 +1,80 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/grpc_api/bookstorev1"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/health"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type Options struct {
+	Books  *BookServer
+	Auth   APIKeyAuth
+	Health *health.Health
+	// RateLimit, when set, limits BookService calls like the REST routes.
+	RateLimit *RateLimit
+	// Reflection lets grpcurl and similar tools discover the services.
+	Reflection bool
+}
+
+// NewServer builds a gRPC server with BookService, the standard health
+// service and, optionally, server reflection registered.
+func NewServer(opts Options) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{
+		RequestLogger(slog.Default()),
+		Recovery(),
+		opts.Auth.Unary(),
+	}
+	if opts.RateLimit != nil {
+		interceptors = append(interceptors, opts.RateLimit.Unary())
+	}
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	bookstorev1.RegisterBookServiceServer(srv, opts.Books)
+	healthpb.RegisterHealthServer(srv, &HealthServer{Health: opts.Health})
+	if opts.Reflection {
+		reflection.Register(srv)
+	}
+	return srv
+}
+
+// HealthServer answers grpc.health.v1 checks with the same readiness checks
+// as /readyz, so it reports NOT_SERVING while the database is down or the
+// process drains. Both the whole server ("") and BookService are known.
+type HealthServer struct {
+	healthpb.UnimplementedHealthServer
+	Health *health.Health
+}
+
+func (hs *HealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "", bookstorev1.BookService_ServiceDesc.ServiceName:
+	default:
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	if hs.Health.Check(ctx).Status != health.StatusOK {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// Shutdown stops srv gracefully, cutting remaining calls off when ctx ends.
+func Shutdown(ctx context.Context, srv *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,296 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/grpc_api/bookstorev1"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/health"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type MockBookService struct {
+	mock.Mock
+}
+
+func (m *MockBookService) CreateBook(ctx context.Context, book bookservices.BookRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, book)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetAllBooks(ctx context.Context) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBookByID(ctx context.Context, bookID string) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookIDs)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) UpdateBookByID(ctx context.Context, bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID, book)
+	return args.Get(0).(bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) DeleteBookByID(ctx context.Context, bookID string) error {
+	args := m.Called(ctx, bookID)
+	return args.Error(0)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) IssueAPIKey(ctx context.Context, apiKey apikeyservices.APIKeyRequest) (apikeyservices.IssuedAPIKeyResponse, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(apikeyservices.IssuedAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAllAPIKeys(ctx context.Context) ([]apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error {
+	args := m.Called(ctx, apiKeyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+// dial serves opts on an in-memory listener and returns a client connection.
+func dial(t *testing.T, opts Options) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	srv := NewServer(opts)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestBookService(t *testing.T) {
+	created := time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)
+	book := bookservices.BookResponse{ID: 1, Name: "Dune", Author: "Frank Herbert", Publication: "Chilton", CreatedAt: created, UpdatedAt: created}
+
+	service := new(MockBookService)
+	service.On("CreateBook", mock.Anything, bookservices.BookRequest{Name: "Dune", Author: "Frank Herbert", Publication: "Chilton"}).Return(book, nil).Once()
+	service.On("GetAllBooks", mock.Anything).Return([]bookservices.BookResponse{book}, nil).Once()
+	service.On("GetBookByID", mock.Anything, "1").Return(book, nil).Once()
+	service.On("GetBookByID", mock.Anything, "2").Return(bookservices.BookResponse{}, bookservices.ErrBookNotFound).Once()
+	service.On("GetBookByID", mock.Anything, "4").Return(bookservices.BookResponse{}, errors.New("connection refused")).Once()
+	service.On("UpdateBookByID", mock.Anything, "2", mock.Anything).Return(bookservices.BookResponse{}, bookservices.ErrBookNotFound).Once()
+	service.On("UpdateBookByID", mock.Anything, "1", bookservices.BookUpdateRequest{Name: "Dune", Author: "Frank Herbert", Publication: "Ace"}).Return(book, nil).Once()
+	service.On("DeleteBookByID", mock.Anything, "1").Return(nil).Once()
+	service.On("DeleteBookByID", mock.Anything, "3").Return(errors.New("connection reset")).Once()
+
+	client := bookstorev1.NewBookServiceClient(dial(t, Options{Books: NewBookServer(service), Health: health.New(time.Second)}))
+	ctx := context.Background()
+
+	var header metadata.MD
+	got, err := client.CreateBook(ctx, &bookstorev1.BookRequest{Name: " Dune ", Author: "Frank Herbert", Publication: "Chilton"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), got.GetId())
+	assert.Equal(t, created, got.GetCreatedAt().AsTime())
+	assert.Len(t, header.Get(RequestIDMetadataKey), 1)
+
+	list, err := client.GetAllBooks(ctx, &bookstorev1.GetAllBooksRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetBooks(), 1)
+
+	got, err = client.GetBookByID(ctx, &bookstorev1.GetBookByIDRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "Dune", got.GetName())
+
+	_, err = client.GetBookByID(ctx, &bookstorev1.GetBookByIDRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetBookByID(ctx, &bookstorev1.GetBookByIDRequest{Id: 4})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = client.UpdateBookByID(ctx, &bookstorev1.UpdateBookByIDRequest{Id: 2, Book: &bookstorev1.BookUpdateRequest{Name: "Dune", Author: "Frank Herbert", Publication: "Ace"}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.UpdateBookByID(ctx, &bookstorev1.UpdateBookByIDRequest{Id: 1, Book: &bookstorev1.BookUpdateRequest{Name: "Dune", Author: "Frank Herbert", Publication: "Ace"}})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteBookByID(ctx, &bookstorev1.DeleteBookByIDRequest{Id: 1})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteBookByID(ctx, &bookstorev1.DeleteBookByIDRequest{Id: 3})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "connection reset")
+
+	service.AssertExpectations(t)
+}
+
+func TestBookServiceValidation(t *testing.T) {
+	service := new(MockBookService)
+	client := bookstorev1.NewBookServiceClient(dial(t, Options{Books: NewBookServer(service), Health: health.New(time.Second)}))
+
+	_, err := client.CreateBook(context.Background(), &bookstorev1.BookRequest{Name: "  ", Author: "Frank Herbert"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 1)
+	badRequest := st.Details()[0].(*errdetails.BadRequest)
+	var fields []string
+	for _, violation := range badRequest.GetFieldViolations() {
+		fields = append(fields, violation.GetField())
+	}
+	assert.ElementsMatch(t, []string{"name", "publication"}, fields)
+	service.AssertNotCalled(t, "CreateBook", mock.Anything, mock.Anything)
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	service := new(MockBookService)
+	service.On("GetAllBooks", mock.Anything).Return([]bookservices.BookResponse{}, nil)
+	apiKeys := new(MockAPIKeyService)
+	apiKeys.On("AuthenticateAPIKey", mock.Anything, "bks_read").Return(apikeyservices.APIKeyResponse{Scopes: []string{apikeyservices.ScopeBooksRead}}, nil)
+	apiKeys.On("AuthenticateAPIKey", mock.Anything, "bks_old").Return(apikeyservices.APIKeyResponse{}, apikeyservices.ErrAPIKeyExpired)
+
+	conn := dial(t, Options{
+		Books:  NewBookServer(service),
+		Auth:   APIKeyAuth{APIKeyService: apiKeys, Required: true, AdminKey: "bootstrap"},
+		Health: health.New(time.Second),
+	})
+	client := bookstorev1.NewBookServiceClient(conn)
+	withKey := func(header, value string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), header, value)
+	}
+
+	_, err := client.GetAllBooks(context.Background(), &bookstorev1.GetAllBooksRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetAllBooks(withKey("x-api-key", "bks_read"), &bookstorev1.GetAllBooksRequest{})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteBookByID(withKey("authorization", "Bearer bks_read"), &bookstorev1.DeleteBookByIDRequest{Id: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetAllBooks(withKey("authorization", "ApiKey bks_old"), &bookstorev1.GetAllBooksRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetAllBooks(withKey("x-api-key", "bootstrap"), &bookstorev1.GetAllBooksRequest{})
+	assert.NoError(t, err)
+
+	// Health checks stay open to probes without a key.
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestRateLimit(t *testing.T) {
+	service := new(MockBookService)
+	service.On("GetAllBooks", mock.Anything).Return([]bookservices.BookResponse{}, nil)
+	apiKeys := new(MockAPIKeyService)
+	apiKeys.On("AuthenticateAPIKey", mock.Anything, "bks_read").Return(apikeyservices.APIKeyResponse{ID: 7, Scopes: []string{apikeyservices.ScopeBooksRead}}, nil)
+
+	store := ratelimit.NewMemoryStore()
+	conn := dial(t, Options{
+		Books:  NewBookServer(service),
+		Auth:   APIKeyAuth{APIKeyService: apiKeys},
+		Health: health.New(time.Second),
+		RateLimit: &RateLimit{
+			Store:        store,
+			DefaultLimit: ratelimit.Limit{Requests: 5, Period: time.Minute},
+			RouteLimits:  map[string]ratelimit.Limit{"GET /books/": {Requests: 1, Period: time.Minute}},
+		},
+	})
+	client := bookstorev1.NewBookServiceClient(conn)
+	ctx := context.Background()
+
+	var header metadata.MD
+	_, err := client.GetAllBooks(ctx, &bookstorev1.GetAllBooksRequest{}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-limit"))
+	assert.Equal(t, []string{"0"}, header.Get("ratelimit-remaining"))
+
+	_, err = client.GetAllBooks(ctx, &bookstorev1.GetAllBooksRequest{}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"60"}, header.Get("retry-after"))
+
+	// An API key has its own bucket, shared with its REST requests.
+	withKey := metadata.AppendToOutgoingContext(ctx, "x-api-key", "bks_read")
+	_, err = client.GetAllBooks(withKey, &bookstorev1.GetAllBooksRequest{})
+	assert.NoError(t, err)
+	result, err := store.Take("GET /books/|apikey:7", ratelimit.Limit{Requests: 1, Period: time.Minute})
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Health checks are never limited.
+	for i := 0; i < 3; i++ {
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err)
+	}
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	checks := health.New(time.Second)
+	conn := dial(t, Options{Books: NewBookServer(new(MockBookService)), Health: checks, Reflection: true})
+	healthClient := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+
+	resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: "bookstore.v1.BookService"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	_, err = healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: "orders.v1.OrderService"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	checks.SetDraining(true)
+	resp, err = healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}))
+	reply, err := stream.Recv()
+	assert.NoError(t, err)
+	var services []string
+	for _, service := range reply.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "bookstore.v1.BookService")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestShutdown(t *testing.T) {
+	srv := NewServer(Options{Books: NewBookServer(new(MockBookService)), Health: health.New(time.Second)})
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(listener) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, Shutdown(ctx, srv))
+}
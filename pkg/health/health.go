@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Health runs readiness checks. Readiness can also be switched off
+// explicitly, e.g. while the server drains on shutdown.
+type Health struct {
+	Checkers []Checker
+	Timeout  time.Duration
+	draining atomic.Bool
+}
+
+func New(timeout time.Duration, checkers ...Checker) *Health {
+	return &Health{
+		Checkers: checkers,
+		Timeout:  timeout,
+	}
+}
+
+func (h *Health) SetDraining(draining bool) {
+	h.draining.Store(draining)
+}
+
+// Check runs every checker concurrently, each bounded by Timeout.
+func (h *Health) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(h.Checkers))}
+	if h.draining.Load() {
+		report.Status = StatusUnavailable
+		report.Checks["shutdown"] = CheckResult{Status: StatusUnavailable, Error: "server is shutting down"}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range h.Checkers {
+		wg.Add(1)
+		go func(checker Checker) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Check(checkCtx)
+			result := CheckResult{Status: StatusOK, LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = StatusUnavailable
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.Name()] = result
+			if err != nil {
+				report.Status = StatusUnavailable
+			}
+		}(checker)
+	}
+	wg.Wait()
+	return report
+}
+
+type DBChecker struct {
+	DB *sql.DB
+}
+
+func (dc DBChecker) Name() string {
+	return "database"
+}
+
+func (dc DBChecker) Check(ctx context.Context) error {
+	if dc.DB == nil {
+		return errors.New("database not initialized")
+	}
+	return dc.DB.PingContext(ctx)
+}
+
+// MigrationChecker reports ready once the golang-migrate schema_migrations
+// table is clean and at least at ExpectedVersion.
+type MigrationChecker struct {
+	DB              *sql.DB
+	ExpectedVersion uint64
+}
+
+func (mc MigrationChecker) Name() string {
+	return "migrations"
+}
+
+func (mc MigrationChecker) Check(ctx context.Context) error {
+	if mc.DB == nil {
+		return errors.New("database not initialized")
+	}
+	var version uint64
+	var dirty bool
+	err := mc.DB.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("no migrations applied")
+		}
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < mc.ExpectedVersion {
+		return fmt.Errorf("schema at version %d, expected %d", version, mc.ExpectedVersion)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubChecker struct {
+	name  string
+	err   error
+	delay time.Duration
+}
+
+func (sc stubChecker) Name() string {
+	return sc.name
+}
+
+func (sc stubChecker) Check(ctx context.Context) error {
+	select {
+	case <-time.After(sc.delay):
+		return sc.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	h := New(50*time.Millisecond,
+		stubChecker{name: "fast"},
+		stubChecker{name: "slow", delay: time.Second},
+	)
+
+	report := h.Check(context.Background())
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["fast"].Status)
+	assert.Equal(t, StatusUnavailable, report.Checks["slow"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestHealthDraining(t *testing.T) {
+	h := New(time.Second, stubChecker{name: "fast"})
+	assert.Equal(t, StatusOK, h.Check(context.Background()).Status)
+
+	h.SetDraining(true)
+	report := h.Check(context.Background())
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, StatusUnavailable, report.Checks["shutdown"].Status)
+}
+
+func TestMigrationChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		version uint64
+		dirty   bool
+		err     error
+		wantErr bool
+	}{
+		{name: "Up to date", version: 20241112230429},
+		{name: "Behind", version: 20241112230428, wantErr: true},
+		{name: "Dirty", version: 20241112230429, dirty: true, wantErr: true},
+		{name: "Missing table", err: errors.New(`relation "schema_migrations" does not exist`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			expectation := mock.ExpectQuery("SELECT version, dirty FROM schema_migrations")
+			if tt.err != nil {
+				expectation.WillReturnError(tt.err)
+			} else {
+				expectation.WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(tt.version, tt.dirty))
+			}
+
+			err = MigrationChecker{DB: db, ExpectedVersion: 20241112230429}.Check(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
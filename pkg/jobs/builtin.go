@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	jobservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/job_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+// TypePruneJobs deletes finished jobs once they are older than the
+// retention.
+const TypePruneJobs = "jobs.prune"
+
+func PruneJobs(store jobservices.JobServicesInterface, retention time.Duration) Handler {
+	return func(ctx context.Context, job jobservices.Job) error {
+		deleted, err := store.DeleteFinishedJobs(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info("finished jobs pruned", "count", deleted)
+		return nil
+	}
+}
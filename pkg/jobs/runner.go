@@ -0,0 +1,317 @@
+// Package jobs runs background work stored in the jobs table: re-indexing,
+// reports, emails and anything else that should not hold up a request.
+//
+// Jobs are claimed with row locks, so any number of replicas can run a
+// worker pool against the same table. A failed job is retried with
+// exponential backoff until it runs out of attempts; a job whose worker died
+// is picked up again once its lease runs out. Recurring jobs are enqueued
+// from cron schedules by every replica under the same unique key, so each
+// run happens once.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	jobservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/job_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/retry"
+)
+
+// Handler runs one job. ctx ends when the job times out or the runner is
+// shut down before the job finished.
+type Handler func(ctx context.Context, job jobservices.Job) error
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without using its remaining
+// attempts, e.g. for a payload that cannot be decoded.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Options tune the runner. Workers is how many jobs run at once on this
+// replica. A failed run is retried after InitialBackoff, doubling up to
+// MaxBackoff, until MaxAttempts runs failed.
+type Options struct {
+	Workers        int
+	PollInterval   time.Duration
+	Timeout        time.Duration // per run
+	MaxAttempts    int           // for jobs enqueued without their own
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type schedule struct {
+	name     string
+	spec     cron.Schedule
+	template jobservices.JobRequest
+}
+
+// Runner claims due jobs and runs them with the handler registered for
+// their type.
+type Runner struct {
+	Store   jobservices.JobServicesInterface
+	Options Options
+
+	mu        sync.Mutex
+	handlers  map[string]Handler
+	schedules []schedule
+
+	now  func() time.Time
+	wake chan struct{}
+}
+
+func NewRunner(store jobservices.JobServicesInterface, opts Options) *Runner {
+	return &Runner{
+		Store:    store,
+		Options:  opts,
+		handlers: map[string]Handler{},
+		now:      time.Now,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler of a job type.
+func (r *Runner) Register(jobType string, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[jobType] = h
+}
+
+// Schedule enqueues job whenever the cron spec fires, e.g. "0 3 * * *",
+// "@hourly" or "@every 15m". Schedules are evaluated in UTC and "@every"
+// intervals are aligned to the clock, so all replicas agree on the run
+// times. Schedules must be added before Start.
+func (r *Runner) Schedule(name, spec string, job jobservices.JobRequest) error {
+	parsed, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("schedule %s: %w", name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedules = append(r.schedules, schedule{name: name, spec: parsed, template: job})
+	return nil
+}
+
+// Enqueue adds a job, with the default number of attempts unless it sets
+// its own, and wakes the workers.
+func (r *Runner) Enqueue(ctx context.Context, job jobservices.JobRequest) (jobservices.Job, error) {
+	if job.MaxAttempts < 1 {
+		job.MaxAttempts = r.Options.MaxAttempts
+	}
+	created, err := r.Store.Enqueue(ctx, job)
+	if err != nil {
+		return jobservices.Job{}, err
+	}
+	r.Notify()
+	return created, nil
+}
+
+// Notify wakes the workers before the next poll, e.g. after a job is
+// retried.
+func (r *Runner) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the workers and schedules in the background. The returned
+// function stops claiming jobs and waits for the running ones to finish.
+// If its ctx ends first, the running jobs' contexts are cancelled and they
+// are retried, on this replica or another.
+func (r *Runner) Start() func(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	jobCtx, interrupt := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.run(ctx, jobCtx)
+	}()
+	return func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			interrupt()
+			return nil
+		case <-shutdownCtx.Done():
+			interrupt()
+			return shutdownCtx.Err()
+		}
+	}
+}
+
+// Run runs the workers and schedules until ctx is cancelled, then waits for
+// the running jobs.
+func (r *Runner) Run(ctx context.Context) {
+	r.run(ctx, context.WithoutCancel(ctx))
+}
+
+func (r *Runner) run(ctx, jobCtx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	r.mu.Lock()
+	schedules := append([]schedule(nil), r.schedules...)
+	r.mu.Unlock()
+	for _, s := range schedules {
+		wg.Add(1)
+		go func(s schedule) {
+			defer wg.Done()
+			r.runSchedule(ctx, s)
+		}(s)
+	}
+
+	workers := r.Options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	// A claim outlives the run, so a dead worker's job is picked up again
+	// once the lease runs out.
+	lease := 2 * r.Options.Timeout
+	slots := make(chan struct{}, workers)
+	ticker := time.NewTicker(r.Options.PollInterval)
+	defer ticker.Stop()
+	for {
+		if free := workers - len(slots); free > 0 {
+			claimed, err := r.Store.ClaimDueJobs(ctx, r.now(), lease, free)
+			if err != nil && ctx.Err() == nil {
+				logger.FromContext(ctx).Error("job claim failed", "error", err)
+			}
+			for _, job := range claimed {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(job jobservices.Job) {
+					defer func() {
+						<-slots
+						wg.Done()
+						// A worker is free; more jobs may be due.
+						r.Notify()
+					}()
+					r.execute(jobCtx, job)
+				}(job)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+func (r *Runner) execute(ctx context.Context, job jobservices.Job) {
+	log := logger.FromContext(ctx).With("job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts)
+	started := r.now()
+
+	var err error
+	r.mu.Lock()
+	handler, ok := r.handlers[job.Type]
+	r.mu.Unlock()
+	switch {
+	case !ok:
+		err = Permanent(fmt.Errorf("no handler for job type %q", job.Type))
+	case job.Attempts > job.MaxAttempts:
+		// Only a job whose workers kept dying gets here.
+		err = Permanent(errors.New("attempts exhausted"))
+	default:
+		err = r.call(ctx, handler, job, log)
+	}
+
+	finished := r.now()
+	metrics.JobRunDuration.WithLabelValues(job.Type).Observe(finished.Sub(started).Seconds())
+	result := jobservices.JobResult{JobID: job.ID, Attempts: job.Attempts, FinishedAt: finished, NextRunAt: finished}
+	var permanent permanentError
+	switch {
+	case err == nil:
+		result.Status = jobservices.JobSucceeded
+		log.Info("job succeeded", "duration", finished.Sub(started))
+	case ctx.Err() != nil:
+		// Shutdown interrupted the job; another worker takes it over.
+		result.Status = jobservices.JobPending
+		result.Error = "interrupted by shutdown: " + err.Error()
+		log.Warn("job interrupted", "error", err)
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		result.Status = jobservices.JobFailed
+		result.Error = err.Error()
+		log.Error("job failed", "error", err)
+	default:
+		backoff := retry.Backoff{Initial: r.Options.InitialBackoff, Max: r.Options.MaxBackoff}.Delay(job.Attempts)
+		result.Status = jobservices.JobPending
+		result.NextRunAt = finished.Add(backoff)
+		result.Error = err.Error()
+		log.Warn("job run failed", "retry_in", backoff, "error", err)
+	}
+	metrics.JobRunsTotal.WithLabelValues(job.Type, result.Status).Inc()
+
+	// Record the outcome even when shutdown interrupted the run.
+	if err := r.Store.RecordResult(context.WithoutCancel(ctx), result); err != nil {
+		log.Error("job result could not be recorded", "error", err)
+	}
+}
+
+// call runs handler with the per-run timeout, turning a panic into an error.
+func (r *Runner) call(ctx context.Context, handler Handler, job jobservices.Job, log *slog.Logger) (err error) {
+	ctx, cancel := context.WithTimeout(logger.WithContext(ctx, log), r.Options.Timeout)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Error("job panicked", "panic", recovered)
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// runSchedule enqueues the schedule's job at every run time until ctx is
+// cancelled.
+func (r *Runner) runSchedule(ctx context.Context, s schedule) {
+	for {
+		now := r.now()
+		next := nextRun(s.spec, now)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		job := s.template
+		job.RunAt = next
+		job.UniqueKey = "schedule:" + s.name + ":" + strconv.FormatInt(next.Unix(), 10)
+		_, err := r.Enqueue(ctx, job)
+		switch {
+		case errors.Is(err, jobservices.ErrJobExists):
+			logger.FromContext(ctx).Debug("scheduled job already enqueued", "schedule", s.name, "run_at", next)
+		case err != nil && ctx.Err() == nil:
+			logger.FromContext(ctx).Error("scheduled job could not be enqueued", "schedule", s.name, "run_at", next, "error", err)
+		}
+	}
+}
+
+// nextRun is the first run time of spec after now, in UTC. "@every"
+// intervals count from the zero time rather than from now, so they fire at
+// the same instants on every replica.
+func nextRun(spec cron.Schedule, now time.Time) time.Time {
+	now = now.UTC()
+	if every, ok := spec.(cron.ConstantDelaySchedule); ok {
+		return now.Truncate(every.Delay).Add(every.Delay)
+	}
+	return spec.Next(now)
+}
@@ -0,0 +1,286 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	jobservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/job_services"
+)
+
+// memoryStore keeps jobs in memory, mirroring the Postgres queries closely
+// enough to drive the runner.
+type memoryStore struct {
+	mu   sync.Mutex
+	jobs []jobservices.Job
+}
+
+func (s *memoryStore) Enqueue(ctx context.Context, request jobservices.JobRequest) (jobservices.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if request.UniqueKey != "" && job.UniqueKey != nil && *job.UniqueKey == request.UniqueKey {
+			return jobservices.Job{}, jobservices.ErrJobExists
+		}
+	}
+	job := jobservices.Job{
+		ID:          uint(len(s.jobs) + 1),
+		Type:        request.Type,
+		Payload:     request.Payload,
+		Status:      jobservices.JobPending,
+		MaxAttempts: request.MaxAttempts,
+		RunAt:       request.RunAt,
+	}
+	if request.UniqueKey != "" {
+		key := request.UniqueKey
+		job.UniqueKey = &key
+	}
+	s.jobs = append(s.jobs, job)
+	return job, nil
+}
+
+func (s *memoryStore) ClaimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]jobservices.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []jobservices.Job
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		due := job.Status == jobservices.JobPending && !job.RunAt.After(now)
+		expired := job.Status == jobservices.JobRunning && !job.LockedUntil.After(now)
+		if len(claimed) == limit || !(due || expired) {
+			continue
+		}
+		lockedUntil := now.Add(lease)
+		job.Status = jobservices.JobRunning
+		job.Attempts++
+		job.LockedUntil = &lockedUntil
+		claimed = append(claimed, *job)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) RecordResult(ctx context.Context, result jobservices.JobResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job := &s.jobs[result.JobID-1]
+	if job.Status != jobservices.JobRunning || job.Attempts != result.Attempts {
+		return nil
+	}
+	job.Status = result.Status
+	job.RunAt = result.NextRunAt
+	job.LockedUntil = nil
+	if result.Error != "" {
+		job.LastError = &result.Error
+	}
+	return nil
+}
+
+func (s *memoryStore) GetJobs(ctx context.Context, filter jobservices.JobFilter) ([]jobservices.Job, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *memoryStore) GetJobByID(ctx context.Context, jobID string) (jobservices.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := strconv.Atoi(jobID)
+	if id < 1 || id > len(s.jobs) {
+		return jobservices.Job{}, jobservices.ErrJobNotFound
+	}
+	return s.jobs[id-1], nil
+}
+
+func (s *memoryStore) RetryJobByID(ctx context.Context, jobID string) (jobservices.Job, error) {
+	return jobservices.Job{}, errors.New("not implemented")
+}
+
+func (s *memoryStore) CancelJobByID(ctx context.Context, jobID string) (jobservices.Job, error) {
+	return jobservices.Job{}, errors.New("not implemented")
+}
+
+func (s *memoryStore) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s *memoryStore) job(t *testing.T, id uint) jobservices.Job {
+	t.Helper()
+	job, err := s.GetJobByID(context.Background(), strconv.Itoa(int(id)))
+	assert.NoError(t, err)
+	return job
+}
+
+func newTestRunner(store *memoryStore) *Runner {
+	return NewRunner(store, Options{
+		Workers:        2,
+		PollInterval:   5 * time.Millisecond,
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	})
+}
+
+func TestRunnerRunsJobs(t *testing.T) {
+	store := &memoryStore{}
+	r := newTestRunner(store)
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	count := func(jobType string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[jobType]++
+		return calls[jobType]
+	}
+	r.Register("ok", func(ctx context.Context, job jobservices.Job) error {
+		count("ok")
+		return nil
+	})
+	r.Register("flaky", func(ctx context.Context, job jobservices.Job) error {
+		if count("flaky") < 2 {
+			return errors.New("temporarily unavailable")
+		}
+		return nil
+	})
+	r.Register("broken", func(ctx context.Context, job jobservices.Job) error {
+		count("broken")
+		return errors.New("always fails")
+	})
+	r.Register("invalid", func(ctx context.Context, job jobservices.Job) error {
+		count("invalid")
+		return Permanent(errors.New("bad payload"))
+	})
+	r.Register("panics", func(ctx context.Context, job jobservices.Job) error {
+		panic("boom")
+	})
+
+	stop := r.Start()
+	for _, jobType := range []string{"ok", "flaky", "broken", "invalid", "panics", "unknown"} {
+		_, err := r.Enqueue(context.Background(), jobservices.JobRequest{Type: jobType})
+		assert.NoError(t, err)
+	}
+
+	want := map[uint]string{
+		1: jobservices.JobSucceeded,
+		2: jobservices.JobSucceeded,
+		3: jobservices.JobFailed,
+		4: jobservices.JobFailed,
+		5: jobservices.JobFailed,
+		6: jobservices.JobFailed,
+	}
+	assert.Eventually(t, func() bool {
+		for id, status := range want {
+			if store.job(t, id).Status != status {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, time.Millisecond)
+	assert.NoError(t, stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]int{"ok": 1, "flaky": 2, "broken": 3, "invalid": 1}, calls)
+	assert.Equal(t, 2, store.job(t, 2).Attempts)
+	assert.Equal(t, "always fails", *store.job(t, 3).LastError)
+	assert.Equal(t, "panic: boom", *store.job(t, 5).LastError)
+	assert.Equal(t, `no handler for job type "unknown"`, *store.job(t, 6).LastError)
+}
+
+func TestRunnerShutdown(t *testing.T) {
+	store := &memoryStore{}
+	r := newTestRunner(store)
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	r.Register("finishes", func(ctx context.Context, job jobservices.Job) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	r.Register("blocks", func(ctx context.Context, job jobservices.Job) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// A graceful stop waits for the running job.
+	stop := r.Start()
+	_, err := r.Enqueue(context.Background(), jobservices.JobRequest{Type: "finishes"})
+	assert.NoError(t, err)
+	<-started
+	close(release)
+	assert.NoError(t, stop(context.Background()))
+	assert.Equal(t, jobservices.JobSucceeded, store.job(t, 1).Status)
+
+	// A job still running when the shutdown times out is interrupted and
+	// left for another worker.
+	stop = r.Start()
+	_, err = r.Enqueue(context.Background(), jobservices.JobRequest{Type: "blocks"})
+	assert.NoError(t, err)
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, stop(ctx), context.DeadlineExceeded)
+	assert.Eventually(t, func() bool {
+		return store.job(t, 2).Status == jobservices.JobPending
+	}, time.Second, time.Millisecond)
+	assert.Contains(t, *store.job(t, 2).LastError, "interrupted by shutdown")
+}
+
+func TestSchedulesEnqueueOnce(t *testing.T) {
+	store := &memoryStore{}
+	// Both replicas start just before the next run time of "@every 1m".
+	start := time.Now()
+	now := time.Date(2026, 10, 19, 12, 59, 59, 990_000_000, time.UTC)
+	var stops []func(context.Context) error
+	for i := 0; i < 2; i++ {
+		r := newTestRunner(store)
+		r.now = func() time.Time { return now.Add(time.Since(start)) }
+		assert.NoError(t, r.Schedule("prune", "@every 1m", jobservices.JobRequest{Type: "noop"}))
+		r.Register("noop", func(context.Context, jobservices.Job) error { return nil })
+		stops = append(stops, r.Start())
+	}
+
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.jobs) == 1 && store.jobs[0].Status == jobservices.JobSucceeded
+	}, time.Second, time.Millisecond)
+	// Give the replicas time to enqueue duplicates, which the unique key
+	// rejects.
+	time.Sleep(50 * time.Millisecond)
+	for _, stop := range stops {
+		assert.NoError(t, stop(context.Background()))
+	}
+
+	job := store.job(t, 1)
+	assert.Equal(t, "schedule:prune:"+strconv.FormatInt(now.Add(10*time.Millisecond).Unix(), 10), *job.UniqueKey)
+	assert.Equal(t, 3, job.MaxAttempts)
+	assert.Len(t, store.jobs, 1)
+
+	assert.Error(t, newTestRunner(store).Schedule("bad", "every day", jobservices.JobRequest{}))
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 7, 30, 0, time.FixedZone("WIB", 7*60*60))
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "@every 15m", want: time.Date(2026, 10, 19, 5, 15, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", want: time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := cron.ParseStandard(tt.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, nextRun(spec, now))
+		})
+	}
+}
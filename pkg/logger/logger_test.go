@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "warn", "json")
+	assert.NoError(t, err)
+
+	l.Info("dropped")
+	l.Warn("kept", "book_id", 1)
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "kept", record["msg"])
+	assert.Equal(t, float64(1), record["book_id"])
+
+	_, err = New(&buf, "loud", "json")
+	assert.Error(t, err)
+	_, err = New(&buf, "info", "xml")
+	assert.Error(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	var buf bytes.Buffer
+	l, _ := New(&buf, "info", "text")
+	ctx := WithRequestID(WithContext(context.Background(), l), "abc")
+	assert.Equal(t, l, FromContext(ctx))
+	assert.Equal(t, "abc", RequestIDFromContext(ctx))
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "abc", RequestID("abc"))
+	assert.Len(t, RequestID(""), 32)
+	assert.Len(t, RequestID(strings.Repeat("x", 129)), 32)
+	assert.NotEqual(t, RequestID(""), RequestID(""))
+}
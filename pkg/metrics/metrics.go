@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bookstore"
+
+// Registry holds every collector exposed on /metrics. A dedicated registry
+// keeps tests independent of the global default one.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests processed, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	BooksCreatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "books_created_total",
+		Help:      "Books created.",
+	})
+
+	BooksUpdatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "books_updated_total",
+		Help:      "Books updated.",
+	})
+
+	BooksDeletedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "books_deleted_total",
+		Help:      "Books deleted.",
+	})
+
+	CacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	CacheErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_errors_total",
+		Help:      "Cache backend errors, by cache and operation.",
+	}, []string{"cache", "operation"})
+
+	WebhookDeliveriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook delivery attempts, by event and resulting status (succeeded, pending or dead_lettered).",
+	}, []string{"event", "status"})
+
+	OutboxMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_messages_total",
+		Help:      "Outbox publish attempts, by event and result (published or failed).",
+	}, []string{"event", "result"})
+
+	StreamSubscribers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_subscribers",
+		Help:      "Connected live feed subscribers, by transport (sse or websocket).",
+	}, []string{"transport"})
+
+	StreamSlowConsumersTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_slow_consumers_total",
+		Help:      "Live feed subscribers disconnected for falling behind, by transport.",
+	}, []string{"transport"})
+
+	NotificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Postgres notifications received, by channel and result (handled or failed).",
+	}, []string{"channel", "result"})
+
+	NotificationListenerReconnectsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_listener_reconnects_total",
+		Help:      "Times the notification listener re-established its connection, by channel.",
+	}, []string{"channel"})
+
+	IdempotentRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "idempotent_requests_total",
+		Help:      "Requests carrying an Idempotency-Key, by result (processed, replayed, in_progress or mismatch).",
+	}, []string{"result"})
+
+	JobRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Background job runs, by job type and resulting status (succeeded, pending for a retry, or failed).",
+	}, []string{"type", "status"})
+
+	JobRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_run_duration_seconds",
+		Help:      "Background job run time, by job type.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		BooksCreatedTotal,
+		BooksUpdatedTotal,
+		BooksDeletedTotal,
+		CacheRequestsTotal,
+		CacheErrorsTotal,
+		WebhookDeliveriesTotal,
+		OutboxMessagesTotal,
+		StreamSubscribers,
+		StreamSlowConsumersTotal,
+		NotificationsTotal,
+		NotificationListenerReconnectsTotal,
+		IdempotentRequestsTotal,
+		JobRunsTotal,
+		JobRunDuration,
+	)
+}
+
+// RegisterDBStats exports the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+func Handler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	return gin.WrapH(h)
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerExposesDBStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, RegisterDBStats(db, "metrics_test"))
+
+	router := gin.New()
+	router.GET("/metrics", Handler())
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body := resp.Body.String()
+	assert.True(t, strings.Contains(body, `go_sql_max_open_connections{db_name="metrics_test"}`))
+	assert.True(t, strings.Contains(body, "bookstore_books_created_total"))
+}
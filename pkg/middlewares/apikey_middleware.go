@@ -0,0 +1,139 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/auth"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+const APIKeyContextKey = "apiKey"
+
+var authErrorCodes = map[error]string{
+	apikeyservices.ErrAPIKeyInvalid: problem.CodeAPIKeyInvalid,
+	apikeyservices.ErrAPIKeyExpired: problem.CodeAPIKeyExpired,
+	apikeyservices.ErrAPIKeyRevoked: problem.CodeAPIKeyRevoked,
+}
+
+type APIKeyMiddleware struct {
+	APIKeyService apikeyservices.APIKeyServicesInterface
+	// Required rejects requests without a key on routes guarded by Authorize.
+	Required bool
+	// AdminKey is a bootstrap key that is granted the admin scope so the
+	// first real keys can be issued.
+	AdminKey string
+}
+
+func NewAPIKeyMiddleware(apiKeyService apikeyservices.APIKeyServicesInterface, required bool, adminKey string) *APIKeyMiddleware {
+	return &APIKeyMiddleware{
+		APIKeyService: apiKeyService,
+		Required:      required,
+		AdminKey:      adminKey,
+	}
+}
+
+// ExtractAPIKey reads the key from "X-API-Key" or from an
+// "Authorization: Bearer|ApiKey <key>" header.
+func ExtractAPIKey(c *gin.Context) string {
+	return auth.KeyFromHeaders(c.GetHeader("X-API-Key"), c.GetHeader("Authorization"))
+}
+
+// Authenticate resolves the key sent with the request, if any, and stores it
+// in the context. Requests with an invalid, expired or revoked key are rejected.
+func (m *APIKeyMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := ExtractAPIKey(c)
+		if rawKey == "" {
+			c.Next()
+			return
+		}
+
+		apiKey, err := auth.Authenticator{APIKeyService: m.APIKeyService, AdminKey: m.AdminKey}.Authenticate(c.Request.Context(), rawKey)
+		if err != nil {
+			for sentinel, code := range authErrorCodes {
+				if errors.Is(err, sentinel) {
+					problem.Abort(c, problem.New(http.StatusUnauthorized, code, err.Error()))
+					return
+				}
+			}
+			logger.FromContext(c.Request.Context()).Error("api key lookup failed", "error", err)
+			problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "An unexpected error occurred."))
+			return
+		}
+		c.Set(APIKeyContextKey, apiKey)
+		c.Next()
+	}
+}
+
+// RequireScope rejects the request unless an authenticated key holds scope.
+func (m *APIKeyMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, ok := GetAPIKey(c)
+		if !ok {
+			problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeAPIKeyRequired, "An API key is required."))
+			return
+		}
+		if !apiKey.HasScope(scope) {
+			problem.Abort(c, problem.New(http.StatusForbidden, problem.CodeInsufficientScope, "The API key is missing scope "+scope+"."))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Authorize checks readScope for safe methods and writeScope for everything
+// else. Requests without a key pass through unless the middleware is Required.
+func (m *APIKeyMiddleware) Authorize(readScope, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := writeScope
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			scope = readScope
+		}
+		if err := m.Allows(c, scope); err != nil {
+			problem.Abort(c, problem.New(err.Status, err.Code, err.Message))
+			return
+		}
+		c.Next()
+	}
+}
+
+// ScopeError explains why Allows refused a scope.
+type ScopeError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *ScopeError) Error() string {
+	return e.Message
+}
+
+// Allows applies the Authorize rules for one scope, for handlers such as
+// GraphQL that pick the scope per operation rather than per HTTP method.
+func (m *APIKeyMiddleware) Allows(c *gin.Context, scope string) *ScopeError {
+	apiKey, ok := GetAPIKey(c)
+	if !ok {
+		if m.Required {
+			return &ScopeError{Status: http.StatusUnauthorized, Code: problem.CodeAPIKeyRequired, Message: "An API key is required."}
+		}
+		return nil
+	}
+	if !apiKey.HasScope(scope) {
+		return &ScopeError{Status: http.StatusForbidden, Code: problem.CodeInsufficientScope, Message: "The API key is missing scope " + scope + "."}
+	}
+	return nil
+}
+
+func GetAPIKey(c *gin.Context) (apikeyservices.APIKeyResponse, bool) {
+	value, exists := c.Get(APIKeyContextKey)
+	if !exists {
+		return apikeyservices.APIKeyResponse{}, false
+	}
+	apiKey, ok := value.(apikeyservices.APIKeyResponse)
+	return apiKey, ok
+}
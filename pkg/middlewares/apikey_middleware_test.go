@@ -0,0 +1,148 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) IssueAPIKey(ctx context.Context, apiKey apikeyservices.APIKeyRequest) (apikeyservices.IssuedAPIKeyResponse, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(apikeyservices.IssuedAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAllAPIKeys(ctx context.Context) ([]apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKeyByID(ctx context.Context, apiKeyID string) error {
+	args := m.Called(ctx, apiKeyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (apikeyservices.APIKeyResponse, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(apikeyservices.APIKeyResponse), args.Error(1)
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	readKey := apikeyservices.APIKeyResponse{ID: 1, Scopes: []string{apikeyservices.ScopeBooksRead}}
+
+	tests := []struct {
+		name         string
+		required     bool
+		method       string
+		headers      map[string]string
+		mockFunc     func(m *MockAPIKeyService)
+		expectedCode int
+	}{
+		{
+			name:         "No key, optional",
+			method:       "GET",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "No key, required",
+			required:     true,
+			method:       "GET",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "X-API-Key with read scope",
+			method:  "GET",
+			headers: map[string]string{"X-API-Key": "bks_read"},
+			mockFunc: func(m *MockAPIKeyService) {
+				m.On("AuthenticateAPIKey", mock.Anything, "bks_read").Return(readKey, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "Bearer key missing write scope",
+			method:  "POST",
+			headers: map[string]string{"Authorization": "Bearer bks_read"},
+			mockFunc: func(m *MockAPIKeyService) {
+				m.On("AuthenticateAPIKey", mock.Anything, "bks_read").Return(readKey, nil)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:    "Expired key",
+			method:  "GET",
+			headers: map[string]string{"Authorization": "ApiKey bks_old"},
+			mockFunc: func(m *MockAPIKeyService) {
+				m.On("AuthenticateAPIKey", mock.Anything, "bks_old").Return(apikeyservices.APIKeyResponse{}, apikeyservices.ErrAPIKeyExpired)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Bootstrap admin key",
+			method:       "POST",
+			headers:      map[string]string{"X-API-Key": "bootstrap"},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			if tt.mockFunc != nil {
+				tt.mockFunc(mockService)
+			}
+			apiKeyMiddleware := NewAPIKeyMiddleware(mockService, tt.required, "bootstrap")
+
+			router := gin.New()
+			router.Use(apiKeyMiddleware.Authenticate(), apiKeyMiddleware.Authorize(apikeyservices.ScopeBooksRead, apikeyservices.ScopeBooksWrite))
+			router.Handle(tt.method, "/books", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(tt.method, "/books", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAPIKeyService)
+	mockService.On("AuthenticateAPIKey", mock.Anything, "bks_read").Return(apikeyservices.APIKeyResponse{Scopes: []string{apikeyservices.ScopeBooksRead}}, nil)
+	apiKeyMiddleware := NewAPIKeyMiddleware(mockService, false, "")
+
+	router := gin.New()
+	router.GET("/admin", apiKeyMiddleware.Authenticate(), apiKeyMiddleware.RequireScope(apikeyservices.ScopeAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin", nil)
+	req.Header.Set("X-API-Key", "bks_read")
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":"insufficient_scope"`)
+}
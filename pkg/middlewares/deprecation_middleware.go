@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation marks responses from routes that are scheduled for removal,
+// using the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a Link
+// to the successor route.
+type Deprecation struct {
+	// Since is when the routes were deprecated; zero sends "Deprecation: true".
+	Since time.Time
+	// Sunset is when the routes stop working; zero omits the header.
+	Sunset time.Time
+	// SuccessorPrefix is prepended to the request path to build the
+	// successor-version link, e.g. "/api/v1".
+	SuccessorPrefix string
+}
+
+func (d Deprecation) Handler() gin.HandlerFunc {
+	deprecation := "true"
+	if !d.Since.IsZero() {
+		deprecation = fmt.Sprintf("@%d", d.Since.Unix())
+	}
+	sunset := ""
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("Deprecation", deprecation)
+		if sunset != "" {
+			header.Set("Sunset", sunset)
+		}
+		if d.SuccessorPrefix != "" {
+			successor := strings.TrimSuffix(d.SuccessorPrefix, "/") + c.Request.URL.Path
+			header.Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		deprecation        Deprecation
+		expectedDeprecated string
+		expectedSunset     string
+		expectedLink       string
+	}{
+		{
+			name: "Dated deprecation with sunset",
+			deprecation: Deprecation{
+				Since:           time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+				Sunset:          time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+				SuccessorPrefix: "/api/v1",
+			},
+			expectedDeprecated: "@1792368000",
+			expectedSunset:     "Mon, 19 Apr 2027 00:00:00 GMT",
+			expectedLink:       `</api/v1/books/42>; rel="successor-version"`,
+		},
+		{
+			name:               "Undated deprecation",
+			deprecation:        Deprecation{},
+			expectedDeprecated: "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/books/:bookID", tt.deprecation.Handler(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books/42", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedDeprecated, w.Header().Get("Deprecation"))
+			assert.Equal(t, tt.expectedSunset, w.Header().Get("Sunset"))
+			assert.Equal(t, tt.expectedLink, w.Header().Get("Link"))
+		})
+	}
+}
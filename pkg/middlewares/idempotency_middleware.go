@@ -0,0 +1,186 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	idempotencyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/idempotency_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyRetryAfterSecs = 1
+)
+
+// replayedHeaders are the response headers stored with a key. The others,
+// such as X-Request-ID and the rate limit headers, describe the current
+// request and are set afresh on a replay.
+var replayedHeaders = []string{"Content-Type", "Content-Language", "Location", "ETag", "Last-Modified", "Vary"}
+
+type IdempotencyMiddleware struct {
+	Store idempotencyservices.IdempotencyServicesInterface
+	// TTL is how long a completed response is replayed.
+	TTL time.Duration
+	// LockTimeout is how long a request may hold its key before a retry is
+	// allowed to take over.
+	LockTimeout time.Duration
+	// KeyFunc scopes the keys, by default per API key or, for requests
+	// without one, per client IP, so no client replays another's response.
+	KeyFunc KeyFunc
+}
+
+func NewIdempotencyMiddleware(store idempotencyservices.IdempotencyServicesInterface, ttl, lockTimeout time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		Store:       store,
+		TTL:         ttl,
+		LockTimeout: lockTimeout,
+		KeyFunc:     ClientKey,
+	}
+}
+
+// Handler makes POST requests that carry an Idempotency-Key safe to retry.
+// The first request with a key is processed and its response stored; a
+// repeat with the same method, path and body gets the stored response, with
+// Idempotent-Replayed: true. Reusing the key for another request is
+// rejected with 422, and a repeat that arrives while the first one is still
+// processed with 409. Server errors are not stored, so they can be retried
+// with the same key.
+func (m *IdempotencyMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeIdempotencyKey, "Idempotency-Key must be at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters."))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, "The request body could not be read."))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scope := m.KeyFunc(c)
+		fingerprint := requestFingerprint(c.Request, body)
+		record, claimed, err := m.Store.Claim(ctx, idempotencyservices.ClaimRequest{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			LockTimeout: m.LockTimeout,
+			TTL:         m.TTL,
+		})
+		if err != nil {
+			problem.Internal(c, err)
+			return
+		}
+		if !claimed {
+			m.reject(c, record, fingerprint)
+			return
+		}
+
+		// Store the outcome even if the client has gone away by then.
+		storeCtx := context.WithoutCancel(ctx)
+		w := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			if !completed {
+				// The handler panicked; let the key be retried.
+				if err := m.Store.Release(storeCtx, scope, key); err != nil {
+					logger.FromContext(ctx).Error("idempotency key release failed", "idempotency_key", key, "error", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		completed = true
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			if err := m.Store.Release(storeCtx, scope, key); err != nil {
+				logger.FromContext(ctx).Error("idempotency key release failed", "idempotency_key", key, "error", err)
+			}
+			return
+		}
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				header[name] = values
+			}
+		}
+		response := idempotencyservices.StoredResponse{StatusCode: status, Header: header, Body: w.body.Bytes()}
+		if err := m.Store.Complete(storeCtx, scope, key, response); err != nil {
+			// The response is already sent; a retry will be processed again
+			// once the lock times out.
+			logger.FromContext(ctx).Error("idempotency key completion failed", "idempotency_key", key, "error", err)
+			return
+		}
+		metrics.IdempotentRequestsTotal.WithLabelValues("processed").Inc()
+	}
+}
+
+func (m *IdempotencyMiddleware) reject(c *gin.Context, record idempotencyservices.Record, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		metrics.IdempotentRequestsTotal.WithLabelValues("mismatch").Inc()
+		problem.Abort(c, problem.New(http.StatusUnprocessableEntity, problem.CodeIdempotencyReuse, "Idempotency-Key was already used for a different request."))
+	case record.Response == nil:
+		metrics.IdempotentRequestsTotal.WithLabelValues("in_progress").Inc()
+		c.Header("Retry-After", strconv.Itoa(idempotencyRetryAfterSecs))
+		problem.Abort(c, problem.New(http.StatusConflict, problem.CodeIdempotencyBusy, "A request with this Idempotency-Key is still being processed."))
+	default:
+		metrics.IdempotentRequestsTotal.WithLabelValues("replayed").Inc()
+		for name, values := range record.Response.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Status(record.Response.StatusCode)
+		c.Writer.Write(record.Response.Body)
+		c.Abort()
+	}
+}
+
+// requestFingerprint identifies a request by its method, path, query, body
+// type and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{r.Method, r.URL.RequestURI(), r.Header.Get("Content-Type")} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// captureWriter keeps a copy of the response body.
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,183 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	idempotencyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/idempotency_services"
+)
+
+type MockIdempotencyService struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyService) Claim(ctx context.Context, claim idempotencyservices.ClaimRequest) (idempotencyservices.Record, bool, error) {
+	args := m.Called(ctx, claim)
+	return args.Get(0).(idempotencyservices.Record), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, scope, key string, response idempotencyservices.StoredResponse) error {
+	args := m.Called(ctx, scope, key, response)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, scope, key string) error {
+	args := m.Called(ctx, scope, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const body = `{"name":"Dune"}`
+	fingerprint := requestFingerprint(httptest.NewRequest(http.MethodPost, "/books/", nil), []byte(body))
+	claimFor := mock.MatchedBy(func(claim idempotencyservices.ClaimRequest) bool {
+		return claim.Scope == "ip:192.0.2.1" && claim.Key == "key-1" && claim.TTL == time.Hour && claim.LockTimeout == time.Minute
+	})
+	stored := idempotencyservices.StoredResponse{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:       []byte(`{"id":1}`),
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		key          string
+		handlerCode  int
+		mockFunc     func(m *MockIdempotencyService)
+		expectedCode int
+		expectedBody string
+		handled      bool
+		replayed     bool
+	}{
+		{
+			name:         "No key",
+			method:       http.MethodPost,
+			handlerCode:  http.StatusCreated,
+			expectedCode: http.StatusCreated,
+			handled:      true,
+		},
+		{
+			name:         "Not a POST",
+			method:       http.MethodPut,
+			key:          "key-1",
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusOK,
+			handled:      true,
+		},
+		{
+			name:         "Key too long",
+			method:       http.MethodPost,
+			key:          strings.Repeat("k", 256),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "idempotency_key_invalid",
+		},
+		{
+			name:        "First request",
+			method:      http.MethodPost,
+			key:         "key-1",
+			handlerCode: http.StatusCreated,
+			mockFunc: func(m *MockIdempotencyService) {
+				m.On("Claim", mock.Anything, claimFor).Return(idempotencyservices.Record{}, true, nil)
+				m.On("Complete", mock.Anything, "ip:192.0.2.1", "key-1", stored).Return(nil)
+			},
+			expectedCode: http.StatusCreated,
+			handled:      true,
+		},
+		{
+			name:   "Repeat",
+			method: http.MethodPost,
+			key:    "key-1",
+			mockFunc: func(m *MockIdempotencyService) {
+				m.On("Claim", mock.Anything, claimFor).Return(idempotencyservices.Record{Fingerprint: fingerprint, Response: &stored}, false, nil)
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":1}`,
+			replayed:     true,
+		},
+		{
+			name:   "Reused for another request",
+			method: http.MethodPost,
+			key:    "key-1",
+			mockFunc: func(m *MockIdempotencyService) {
+				m.On("Claim", mock.Anything, claimFor).Return(idempotencyservices.Record{Fingerprint: "other", Response: &stored}, false, nil)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: "idempotency_key_reused",
+		},
+		{
+			name:   "First request in progress",
+			method: http.MethodPost,
+			key:    "key-1",
+			mockFunc: func(m *MockIdempotencyService) {
+				m.On("Claim", mock.Anything, claimFor).Return(idempotencyservices.Record{Fingerprint: fingerprint}, false, nil)
+			},
+			expectedCode: http.StatusConflict,
+			expectedBody: "idempotency_key_in_progress",
+		},
+		{
+			name:        "Server error is not stored",
+			method:      http.MethodPost,
+			key:         "key-1",
+			handlerCode: http.StatusInternalServerError,
+			mockFunc: func(m *MockIdempotencyService) {
+				m.On("Claim", mock.Anything, claimFor).Return(idempotencyservices.Record{}, true, nil)
+				m.On("Release", mock.Anything, "ip:192.0.2.1", "key-1").Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError,
+			handled:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(MockIdempotencyService)
+			if tt.mockFunc != nil {
+				tt.mockFunc(store)
+			}
+			handled := false
+			router := gin.New()
+			router.Use(NewIdempotencyMiddleware(store, time.Hour, time.Minute).Handler())
+			handler := func(c *gin.Context) {
+				handled = true
+				data, _ := c.GetRawData()
+				assert.Equal(t, body, string(data))
+				c.Header("X-Request-ID", "req-1")
+				c.JSON(tt.handlerCode, gin.H{"id": 1})
+			}
+			router.POST("/books/", handler)
+			router.PUT("/books/", handler)
+
+			req := httptest.NewRequest(tt.method, "/books/", strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.handled, handled)
+			if tt.replayed {
+				assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			} else {
+				assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+			}
+			store.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/books/:bookID", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	found := metrics.HTTPRequestsTotal.WithLabelValues("GET", "/books/:bookID", "404")
+	unmatched := metrics.HTTPRequestsTotal.WithLabelValues("GET", "unmatched", "404")
+	beforeFound := testutil.ToFloat64(found)
+	beforeUnmatched := testutil.ToFloat64(unmatched)
+
+	for _, url := range []string{"/books/1", "/books/2", "/nope"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, beforeFound+2, testutil.ToFloat64(found))
+	assert.Equal(t, beforeUnmatched+1, testutil.ToFloat64(unmatched))
+}
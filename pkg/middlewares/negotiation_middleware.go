@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+// Negotiate picks the response format from ?format= or Accept and rejects
+// requests the handlers could not answer (406) or whose body they could not
+// read (415) before any work is done.
+func Negotiate() gin.HandlerFunc {
+	supported := make([]string, 0, len(negotiation.Formats))
+	for _, f := range negotiation.Formats {
+		supported = append(supported, f.ContentType)
+	}
+	list := strings.Join(supported, ", ")
+
+	return func(c *gin.Context) {
+		f, err := negotiation.ResponseFormat(c.Request)
+		if err != nil {
+			c.Header("Vary", "Accept")
+			problem.Abort(c, problem.New(http.StatusNotAcceptable, problem.CodeNotAcceptable, "Supported response types are "+list+"."))
+			return
+		}
+		negotiation.SetFormat(c, f)
+
+		if c.Request.ContentLength != 0 && c.Request.Method != http.MethodGet {
+			if _, err := negotiation.RequestFormat(c.Request); err != nil {
+				problem.Abort(c, problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMedia, "Supported request types are "+list+"."))
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/negotiation"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+func TestNegotiate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Negotiate())
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, negotiation.FormatOf(c).Name)
+	}
+	router.GET("/books", handler)
+	router.POST("/books", handler)
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		headers      map[string]string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "Default", method: "GET", url: "/books", expectedCode: http.StatusOK, expectedBody: "json"},
+		{name: "Accept", method: "GET", url: "/books", headers: map[string]string{"Accept": "text/csv"}, expectedCode: http.StatusOK, expectedBody: "csv"},
+		{name: "Query overrides Accept", method: "GET", url: "/books?format=xml", headers: map[string]string{"Accept": "text/csv"}, expectedCode: http.StatusOK, expectedBody: "xml"},
+		{name: "Not acceptable", method: "GET", url: "/books", headers: map[string]string{"Accept": "text/html"}, expectedCode: http.StatusNotAcceptable, expectedBody: `"code":"not_acceptable"`},
+		{name: "Unknown format", method: "GET", url: "/books?format=pdf", expectedCode: http.StatusNotAcceptable},
+		{name: "Supported body", method: "POST", url: "/books", headers: map[string]string{"Content-Type": "application/yaml"}, body: "name: x", expectedCode: http.StatusOK},
+		{name: "Unsupported body", method: "POST", url: "/books", headers: map[string]string{"Content-Type": "text/plain"}, body: "x", expectedCode: http.StatusUnsupportedMediaType, expectedBody: `"code":"unsupported_media_type"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+			if tt.expectedCode >= http.StatusBadRequest {
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
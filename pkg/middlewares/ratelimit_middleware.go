@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/auth"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+)
+
+type KeyFunc func(c *gin.Context) string
+
+type RateLimitMiddleware struct {
+	Store        ratelimit.Store
+	DefaultLimit ratelimit.Limit
+	// RouteLimits is keyed by RouteName, so every version prefix of a route
+	// shares one limit and one bucket per client.
+	RouteLimits map[string]ratelimit.Limit
+	KeyFunc     KeyFunc
+}
+
+func NewRateLimitMiddleware(store ratelimit.Store, defaultLimit ratelimit.Limit, routeLimits map[string]ratelimit.Limit) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		Store:        store,
+		DefaultLimit: defaultLimit,
+		RouteLimits:  routeLimits,
+		KeyFunc:      ClientKey,
+	}
+}
+
+// ClientKey identifies the caller by API key when one was authenticated,
+// falling back to the client IP. X-Forwarded-For only counts towards the IP
+// when the peer is one of the engine's trusted proxies.
+func ClientKey(c *gin.Context) string {
+	if apiKey, ok := GetAPIKey(c); ok {
+		return auth.ClientKey(&apiKey, "")
+	}
+	return auth.ClientKey(nil, c.ClientIP())
+}
+
+func (m *RateLimitMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := RouteName(c)
+		limit, ok := m.RouteLimits[route]
+		if !ok {
+			limit = m.DefaultLimit
+		}
+
+		result, err := m.Store.Take(route+"|"+m.KeyFunc(c), limit)
+		if err != nil {
+			// Fail open: an unavailable limiter store should not take the API down.
+			logger.FromContext(c.Request.Context()).Error("rate limit store error", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ratelimit.Seconds(result.Reset)))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ratelimit.Seconds(result.RetryAfter)))
+			problem.Abort(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, "Rate limit exceeded, retry after "+strconv.Itoa(ratelimit.Seconds(result.RetryAfter))+" seconds."))
+			return
+		}
+		c.Next()
+	}
+}
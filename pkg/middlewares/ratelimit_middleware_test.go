@@ -0,0 +1,140 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rateLimitMiddleware := NewRateLimitMiddleware(
+		ratelimit.NewMemoryStore(),
+		ratelimit.Limit{Requests: 5, Period: time.Minute},
+		map[string]ratelimit.Limit{"GET /books/": {Requests: 1, Period: time.Minute}},
+	)
+
+	router := gin.New()
+	router.Use(rateLimitMiddleware.Handler())
+	router.GET("/books/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/books/:bookID", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/books/", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header().Get("RateLimit-Remaining"))
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/books/", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+	assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), `"code":"rate_limited"`)
+
+	// Other routes fall back to the default limit and keep their own bucket.
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/books/1", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "5", resp.Header().Get("RateLimit-Limit"))
+
+	// A different client IP gets a fresh bucket.
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/books/", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRateLimitMiddlewareFailsOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewRateLimitMiddleware(failingStore{}, ratelimit.Limit{Requests: 1, Period: time.Minute}, nil).Handler())
+	router.GET("/books/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/books/", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRateLimitMiddlewareSharesVersionPrefixes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rateLimitMiddleware := NewRateLimitMiddleware(
+		ratelimit.NewMemoryStore(),
+		ratelimit.Limit{Requests: 5, Period: time.Minute},
+		map[string]ratelimit.Limit{"GET /books/": {Requests: 1, Period: time.Minute}},
+	)
+
+	router := gin.New()
+	for _, group := range []*gin.RouterGroup{router.Group("/api/v1", RoutePrefix("/api/v1")), router.Group("")} {
+		group.GET("/books/", rateLimitMiddleware.Handler(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	}
+
+	// The legacy alias draws from the bucket of the versioned route.
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/books/", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("RateLimit-Limit"))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest("GET", "/books/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestClientKeyTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		proxies []string
+		want    string
+	}{
+		{name: "No trusted proxies", proxies: nil, want: "ip:10.0.0.1"},
+		{name: "Trusted proxy", proxies: []string{"10.0.0.0/8"}, want: "ip:198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			assert.NoError(t, router.SetTrustedProxies(tt.proxies))
+			var key string
+			router.GET("/books/", func(c *gin.Context) {
+				key = ClientKey(c)
+			})
+
+			req := httptest.NewRequest("GET", "/books/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
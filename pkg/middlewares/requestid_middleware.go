@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+const (
+	RequestIDHeader     = "X-Request-ID"
+	RequestIDContextKey = "requestID"
+)
+
+// RequestID reuses the caller's X-Request-ID or generates one, echoes it on
+// the response and attaches a logger carrying it to the request context.
+func RequestID(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := logger.RequestID(c.GetHeader(RequestIDHeader))
+
+		c.Set(RequestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := logger.WithRequestID(c.Request.Context(), requestID)
+		ctx = logger.WithContext(ctx, base.With("request_id", requestID))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequestLogger writes one structured access log record per request.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+		logger.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDContextKey)
+}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	base, _ := logger.New(&buf, "info", "json")
+
+	router := gin.New()
+	router.Use(RequestID(base), RequestLogger())
+	router.GET("/books/", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("handler log")
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+	}{
+		{name: "Propagated", requestID: "client-id-1"},
+		{name: "Generated", requestID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req, _ := http.NewRequest("GET", "/books/", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			requestID := resp.Header().Get(RequestIDHeader)
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Len(t, lines, 2)
+			for _, line := range lines {
+				var record map[string]interface{}
+				assert.NoError(t, json.Unmarshal([]byte(line), &record))
+				assert.Equal(t, requestID, record["request_id"])
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RoutePrefixContextKey = "routePrefix"
+
+// RoutePrefix records the prefix a version of the API is mounted at, so that
+// RouteName names a route the same way under /api/v1 and its legacy alias.
+func RoutePrefix(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(RoutePrefixContextKey, prefix)
+		c.Next()
+	}
+}
+
+// RouteName is "<METHOD> <route>" without the version prefix, e.g.
+// "GET /books/" for both GET /api/v1/books/ and GET /books/.
+func RouteName(c *gin.Context) string {
+	route := c.FullPath()
+	if prefix := c.GetString(RoutePrefixContextKey); prefix != "" {
+		route = strings.TrimPrefix(route, prefix)
+	}
+	return c.Request.Method + " " + route
+}
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS books_notify_truncate ON books;
+DROP TRIGGER IF EXISTS books_notify_change ON books;
+DROP FUNCTION IF EXISTS notify_books_truncated();
+DROP FUNCTION IF EXISTS notify_book_change();
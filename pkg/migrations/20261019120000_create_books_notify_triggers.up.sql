@@ -0,0 +1,45 @@
+-- Every committed change to books is announced on the book_changes channel
+-- so other replicas can invalidate caches and feed live streams. The payload
+-- mirrors a book event; rows are small enough for the 8000 byte limit.
+CREATE OR REPLACE FUNCTION notify_book_change() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    book_id INTEGER;
+    book JSON;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        event_type := 'book.deleted';
+        book_id := OLD.id;
+    ELSE
+        event_type := CASE TG_OP WHEN 'INSERT' THEN 'book.created' ELSE 'book.updated' END;
+        book_id := NEW.id;
+        book := row_to_json(NEW);
+    END IF;
+    PERFORM pg_notify('book_changes', json_build_object(
+        'id', 'ntf_' || md5(random()::text || clock_timestamp()::text),
+        'type', event_type,
+        'book_id', book_id,
+        'book', book,
+        'occurred_at', now()
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- TRUNCATE has no rows to report; listeners resynchronise instead.
+CREATE OR REPLACE FUNCTION notify_books_truncated() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('book_changes', json_build_object('type', 'reset')::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS books_notify_change ON books;
+CREATE TRIGGER books_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON books
+    FOR EACH ROW EXECUTE FUNCTION notify_book_change();
+
+DROP TRIGGER IF EXISTS books_notify_truncate ON books;
+CREATE TRIGGER books_notify_truncate
+    AFTER TRUNCATE ON books
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_books_truncated();
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the embedded migrations to a Postgres database, tracking
+// them in the schema_migrations table that MigrationChecker reads.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// NewMigrator takes over db; Close closes it.
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{m: m}, nil
+}
+
+// Up applies every pending migration. It is a no-op when the schema is
+// current.
+func (mg *Migrator) Up() error {
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down reverts the last steps migrations.
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+	return mg.m.Steps(-steps)
+}
+
+// Version returns the applied version, 0 before the first migration.
+func (mg *Migrator) Version() (version uint64, dirty bool, err error) {
+	v, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return uint64(v), dirty, err
+}
+
+// Force records version as applied and clean without running anything, to
+// recover from a migration that failed half way and was fixed by hand.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
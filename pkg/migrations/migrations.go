@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// FS holds the versioned "<version>_<name>.up.sql" / ".down.sql" files.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the highest migration version shipped with the binary.
+func LatestVersion() (uint64, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0, err
+	}
+	var latest uint64
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestVersion(t *testing.T) {
+	version, err := LatestVersion()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, version, uint64(20241112230429))
+}
+
+func TestSourceReadsEveryMigration(t *testing.T) {
+	source, err := iofs.New(FS, ".")
+	assert.NoError(t, err)
+
+	version, err := source.First()
+	assert.NoError(t, err)
+	for {
+		_, _, err := source.ReadUp(version)
+		assert.NoError(t, err, "up migration of %d", version)
+		_, _, err = source.ReadDown(version)
+		assert.NoError(t, err, "down migration of %d", version)
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		assert.NoError(t, err)
+		version = next
+	}
+
+	latest, err := LatestVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, latest, uint64(version))
+}
@@ -0,0 +1,213 @@
+package negotiation
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+)
+
+// CSV columns are the exported fields of a struct, named by their json tags.
+// A single struct is written as a header and one row, a slice as a header
+// and one row per item.
+
+type column struct {
+	name  string
+	index int
+}
+
+func columns(t reflect.Type) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		cols = append(cols, column{name: name, index: i})
+	}
+	return cols
+}
+
+func EncodeCSV(w io.Writer, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	rows := []reflect.Value{rv}
+	elemType := rv.Type()
+	if rv.Kind() == reflect.Slice {
+		elemType = rv.Type().Elem()
+		rows = make([]reflect.Value, rv.Len())
+		for i := range rows {
+			rows[i] = rv.Index(i)
+		}
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot encode %s", elemType)
+	}
+
+	cols := columns(elemType)
+	writer := csv.NewWriter(w)
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(cols))
+		for i, col := range cols {
+			record[i] = formatValue(row.Field(col.index))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatValue(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(time.RFC3339Nano)
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// DecodeCSV reads a header and rows into v, a pointer to a struct (exactly
+// one row) or to a slice of structs. Unknown columns and unparsable values
+// are reported as *validation.Error.
+func DecodeCSV(r io.Reader, v interface{}) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return validation.ErrEmptyBody
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("csv: decode target must be a non-nil pointer")
+	}
+	rv = rv.Elem()
+	elemType := rv.Type()
+	if rv.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot decode into %s", elemType)
+	}
+
+	byName := map[string]column{}
+	for _, col := range columns(elemType) {
+		byName[col.name] = col
+	}
+	header := records[0]
+	var fieldErrors []problem.FieldError
+	for _, name := range header {
+		if _, ok := byName[name]; !ok {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: name, Rule: "unknown", Message: fmt.Sprintf("%s is not a known field", name)})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &validation.Error{Fields: fieldErrors}
+	}
+
+	rows := records[1:]
+	if rv.Kind() != reflect.Slice && len(rows) != 1 {
+		return fmt.Errorf("csv: expected a header and one row, got %d rows", len(rows))
+	}
+	if rv.Kind() == reflect.Slice {
+		rv.Set(reflect.MakeSlice(rv.Type(), len(rows), len(rows)))
+	}
+
+	for i, record := range rows {
+		target, prefix := rv, ""
+		if rv.Kind() == reflect.Slice {
+			target, prefix = rv.Index(i), fmt.Sprintf("[%d].", i)
+		}
+		for j, name := range header {
+			if j >= len(record) {
+				break
+			}
+			if err := parseValue(target.Field(byName[name].index), record[j]); err != nil {
+				fieldErrors = append(fieldErrors, problem.FieldError{Field: prefix + name, Rule: "type", Message: fmt.Sprintf("%s %s", prefix+name, err)})
+			}
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &validation.Error{Fields: fieldErrors}
+	}
+	return nil
+}
+
+func parseValue(v reflect.Value, s string) error {
+	if s == "" {
+		return nil
+	}
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(s)); err != nil {
+				return fmt.Errorf("must be a %s", v.Type())
+			}
+			return nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("must be a bool")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("cannot be set from CSV")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package negotiation
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// Decode reads a body in format f into v and validates it with
+// validation.Struct. JSON goes through validation.DecodeJSON so it stays
+// strict about unknown fields; YAML and CSV reject unknown fields too.
+func Decode(r io.Reader, f Format, v interface{}) error {
+	switch f.Name {
+	case JSON.Name:
+		return validation.DecodeJSON(r, v)
+	case XML.Name:
+		if err := xml.NewDecoder(r).Decode(v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return validation.ErrEmptyBody
+			}
+			return err
+		}
+	case YAML.Name:
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return validation.ErrEmptyBody
+			}
+			return yamlError(err)
+		}
+	case CSV.Name:
+		if err := DecodeCSV(r, v); err != nil {
+			return err
+		}
+	default:
+		return ErrUnsupportedMediaType
+	}
+	return validation.Struct(v)
+}
+
+// yamlError turns yaml.v3's "field x not found in type y" lines into field
+// errors matching the JSON decoder's.
+func yamlError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	var fields []problem.FieldError
+	for _, message := range typeErr.Errors {
+		_, rest, ok := strings.Cut(message, ": field ")
+		if !ok {
+			return err
+		}
+		name, _, ok := strings.Cut(rest, " not found in type")
+		if !ok {
+			return err
+		}
+		fields = append(fields, problem.FieldError{Field: name, Rule: "unknown", Message: fmt.Sprintf("%s is not a known field", name)})
+	}
+	return &validation.Error{Fields: fields}
+}
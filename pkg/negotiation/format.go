@@ -0,0 +1,167 @@
+// Package negotiation picks response and request body formats from the
+// Accept and Content-Type headers (or a ?format= override) and encodes and
+// decodes JSON, XML, CSV and YAML bodies.
+package negotiation
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FormatQueryParam overrides the Accept header, e.g. ?format=csv.
+const FormatQueryParam = "format"
+
+type Format struct {
+	Name        string
+	ContentType string
+	// aliases are other media types accepted for the same format.
+	aliases []string
+}
+
+var (
+	JSON = Format{Name: "json", ContentType: "application/json"}
+	XML  = Format{Name: "xml", ContentType: "application/xml", aliases: []string{"text/xml"}}
+	CSV  = Format{Name: "csv", ContentType: "text/csv"}
+	YAML = Format{Name: "yaml", ContentType: "application/yaml", aliases: []string{"application/x-yaml", "text/yaml", "text/x-yaml"}}
+)
+
+// Formats lists the supported formats in order of server preference.
+var Formats = []Format{JSON, XML, CSV, YAML}
+
+var (
+	ErrNotAcceptable        = errors.New("none of the requested media types is supported")
+	ErrUnsupportedMediaType = errors.New("unsupported request content type")
+)
+
+func (f Format) matches(mediaType string) bool {
+	if mediaType == f.ContentType {
+		return true
+	}
+	for _, alias := range f.aliases {
+		if mediaType == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func ByName(name string) (Format, bool) {
+	for _, f := range Formats {
+		if strings.EqualFold(name, f.Name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+func ByMediaType(mediaType string) (Format, bool) {
+	for _, f := range Formats {
+		if f.matches(mediaType) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// ResponseFormat selects the response format for r from the ?format= query
+// parameter, falling back to the Accept header. A missing Accept header
+// means JSON.
+func ResponseFormat(r *http.Request) (Format, error) {
+	if name := r.URL.Query().Get(FormatQueryParam); name != "" {
+		if f, ok := ByName(name); ok {
+			return f, nil
+		}
+		return Format{}, ErrNotAcceptable
+	}
+	return Select(r.Header.Get("Accept"))
+}
+
+// RequestFormat selects the request body format from Content-Type. A missing
+// Content-Type means JSON.
+func RequestFormat(r *http.Request) (Format, error) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return JSON, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Format{}, ErrUnsupportedMediaType
+	}
+	if f, ok := ByMediaType(mediaType); ok {
+		return f, nil
+	}
+	return Format{}, ErrUnsupportedMediaType
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+	order     int
+}
+
+// Select returns the supported format with the highest quality in an Accept
+// header, preferring more specific ranges and then earlier ones.
+func Select(accept string) (Format, error) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	var ranges []mediaRange
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, q: q, order: i})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+
+	for _, r := range ranges {
+		for _, f := range Formats {
+			if rangeMatches(r.mediaType, f) {
+				return f, nil
+			}
+		}
+	}
+	return Format{}, ErrNotAcceptable
+}
+
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func rangeMatches(mediaType string, f Format) bool {
+	if mediaType == "*/*" {
+		return true
+	}
+	// Wildcards match the canonical type only, so text/* means CSV rather
+	// than XML through its text/xml alias.
+	if prefix, ok := strings.CutSuffix(mediaType, "/*"); ok {
+		return strings.HasPrefix(f.ContentType, prefix+"/")
+	}
+	return f.matches(mediaType)
+}
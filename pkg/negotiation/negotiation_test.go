@@ -0,0 +1,160 @@
+package negotiation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/validation"
+)
+
+type item struct {
+	ID        uint      `json:"id" xml:"id" yaml:"id"`
+	Name      string    `json:"name" xml:"name" yaml:"name" mod:"trim" validate:"required"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at" yaml:"created_at"`
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected Format
+		err      error
+	}{
+		{accept: "", expected: JSON},
+		{accept: "*/*", expected: JSON},
+		{accept: "text/csv", expected: CSV},
+		{accept: "text/xml", expected: XML},
+		{accept: "application/x-yaml", expected: YAML},
+		{accept: "text/html, application/xml;q=0.9, */*;q=0.8", expected: XML},
+		{accept: "application/json;q=0.5, text/csv", expected: CSV},
+		{accept: "text/*", expected: CSV},
+		{accept: "application/yaml, application/json;q=0", expected: YAML},
+		{accept: "text/html", err: ErrNotAcceptable},
+		{accept: "application/json;q=0", err: ErrNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			f, err := Select(tt.accept)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+}
+
+func TestResponseFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/books?format=YAML", nil)
+	req.Header.Set("Accept", "application/xml")
+	f, err := ResponseFormat(req)
+	assert.NoError(t, err)
+	assert.Equal(t, YAML, f)
+
+	req = httptest.NewRequest(http.MethodGet, "/books?format=html", nil)
+	_, err = ResponseFormat(req)
+	assert.ErrorIs(t, err, ErrNotAcceptable)
+}
+
+func TestRequestFormat(t *testing.T) {
+	for contentType, expected := range map[string]Format{
+		"":                                JSON,
+		"application/json; charset=utf-8": JSON,
+		"text/xml":                        XML,
+		"text/csv":                        CSV,
+		"application/yaml":                YAML,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/books", nil)
+		req.Header.Set("Content-Type", contentType)
+		f, err := RequestFormat(req)
+		assert.NoError(t, err, contentType)
+		assert.Equal(t, expected, f, contentType)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/books", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err := RequestFormat(req)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+}
+
+func TestRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	created := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+	items := []item{{ID: 1, Name: "One", CreatedAt: created}, {ID: 2, Name: "Two, \"quoted\""}}
+
+	tests := []struct {
+		format      string
+		contentType string
+		expected    string
+	}{
+		{format: "json", contentType: "application/json; charset=utf-8", expected: `[{"id":1,"name":"One","created_at":"2026-10-19T08:30:00Z"}`},
+		{format: "xml", contentType: "application/xml; charset=utf-8", expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<items><item><id>1</id><name>One</name><created_at>2026-10-19T08:30:00Z</created_at></item><item><id>2</id>`},
+		{format: "csv", contentType: "text/csv; charset=utf-8", expected: "id,name,created_at\n1,One,2026-10-19T08:30:00Z\n2,\"Two, \"\"quoted\"\"\",\n"},
+		{format: "yaml", contentType: "application/yaml; charset=utf-8", expected: "- id: 1\n  name: One\n  created_at: 2026-10-19T08:30:00Z\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/items?format="+tt.format, nil)
+
+			Render(c, http.StatusOK, "item", items)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			assert.Contains(t, w.Body.String(), tt.expected)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		body     string
+		expected item
+	}{
+		{name: "json", format: JSON, body: `{"id":3,"name":" Three "}`, expected: item{ID: 3, Name: "Three"}},
+		{name: "xml", format: XML, body: `<item><id>3</id><name> Three </name></item>`, expected: item{ID: 3, Name: "Three"}},
+		{name: "yaml", format: YAML, body: "id: 3\nname: ' Three '\n", expected: item{ID: 3, Name: "Three"}},
+		{name: "csv", format: CSV, body: "id,name,created_at\n3, Three ,2026-10-19T08:30:00Z\n", expected: item{ID: 3, Name: "Three", CreatedAt: time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got item
+			assert.NoError(t, Decode(strings.NewReader(tt.body), tt.format, &got))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var validationErr *validation.Error
+
+	var got item
+	err := Decode(strings.NewReader("name: x\nisbn: 123\n"), YAML, &got)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "isbn", validationErr.Fields[0].Field)
+	assert.Equal(t, "unknown", validationErr.Fields[0].Rule)
+
+	err = Decode(strings.NewReader("name,isbn\nx,123\n"), CSV, &got)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "isbn", validationErr.Fields[0].Field)
+
+	err = Decode(strings.NewReader("id,name\nabc,x\n"), CSV, &got)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "type", validationErr.Fields[0].Rule)
+
+	err = Decode(strings.NewReader(`<item><name></name></item>`), XML, &got)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "required", validationErr.Fields[0].Rule)
+
+	var items []item
+	err = Decode(strings.NewReader("name\nOne\n\"\"\n"), CSV, &items)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "[1].name", validationErr.Fields[0].Field)
+
+	assert.ErrorIs(t, Decode(strings.NewReader(""), XML, &got), validation.ErrEmptyBody)
+}
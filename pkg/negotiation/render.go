@@ -0,0 +1,99 @@
+package negotiation
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+const formatKey = "negotiation.format"
+
+// SetFormat records the negotiated response format on the request.
+func SetFormat(c *gin.Context, f Format) {
+	c.Set(formatKey, f)
+}
+
+// FormatOf returns the format recorded by SetFormat. Without one it
+// negotiates from the request, falling back to JSON.
+func FormatOf(c *gin.Context) Format {
+	if value, ok := c.Get(formatKey); ok {
+		if f, ok := value.(Format); ok {
+			return f
+		}
+	}
+	if f, err := ResponseFormat(c.Request); err == nil {
+		return f
+	}
+	return JSON
+}
+
+// Render writes v in the negotiated format. name is the element name of a
+// single value: the XML root element, or the element of each item with a
+// root of name+"s" when v is a slice.
+func Render(c *gin.Context, status int, name string, v interface{}) {
+	c.Header("Vary", "Accept")
+	f := FormatOf(c)
+	if f.Name == JSON.Name {
+		c.JSON(status, v)
+		return
+	}
+	c.Status(status)
+	c.Header("Content-Type", f.ContentType+"; charset=utf-8")
+	if err := Encode(c.Writer, f, name, v); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// Encode writes v to w in format f. name is used for XML as in Render.
+func Encode(w io.Writer, f Format, name string, v interface{}) error {
+	switch f.Name {
+	case XML.Name:
+		return EncodeXML(w, name, v)
+	case YAML.Name:
+		encoder := yaml.NewEncoder(w)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case CSV.Name:
+		return EncodeCSV(w, v)
+	default:
+		return json.NewEncoder(w).Encode(v)
+	}
+}
+
+// EncodeXML writes v as an XML document rooted at name, or at name+"s" with
+// one name element per item when v is a slice.
+func EncodeXML(w io.Writer, name string, v interface{}) error {
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	item := xml.StartElement{Name: xml.Name{Local: name}}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		if err := encoder.EncodeElement(v, item); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: name + "s"}}
+	if err := encoder.EncodeToken(root); err != nil {
+		return err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := encoder.EncodeElement(rv.Index(i).Interface(), item); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
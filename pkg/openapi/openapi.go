@@ -0,0 +1,93 @@
+// Package openapi serves the OpenAPI 3 description of the HTTP API and an
+// interactive documentation page. The document is maintained by hand in
+// openapi.yaml; docs_routes_test.go in pkg/routes keeps it in sync with the router.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	spec     Document
+	specErr  error
+)
+
+// Document is the subset of an OpenAPI document the server inspects.
+type Document struct {
+	OpenAPI string                            `json:"openapi"`
+	Paths   map[string]map[string]interface{} `json:"paths"`
+}
+
+func load() {
+	var raw interface{}
+	if specErr = yaml.Unmarshal(specYAML, &raw); specErr != nil {
+		specErr = fmt.Errorf("parse openapi.yaml: %w", specErr)
+		return
+	}
+	if specJSON, specErr = json.Marshal(raw); specErr != nil {
+		specErr = fmt.Errorf("encode openapi.yaml as JSON: %w", specErr)
+		return
+	}
+	specErr = json.Unmarshal(specJSON, &spec)
+}
+
+// JSON returns the OpenAPI document encoded as JSON.
+func JSON() ([]byte, error) {
+	specOnce.Do(load)
+	return specJSON, specErr
+}
+
+// Spec returns the parsed OpenAPI document.
+func Spec() (Document, error) {
+	specOnce.Do(load)
+	return spec, specErr
+}
+
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := JSON()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}
+}
+
+// DocsHandler renders Swagger UI for the document at specURL.
+func DocsHandler(specURL string) gin.HandlerFunc {
+	page := fmt.Sprintf(docsPage, specURL)
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	}
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Bookstore API docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,50 @@
+// Package outbox implements the transactional outbox pattern.
+//
+// Services write a Message with Write inside the transaction that changes
+// their data, so an event is recorded if and only if the change commits. A
+// Relay then forwards the recorded messages to a Publisher. Delivery is at
+// least once: a message may be published again if the relay stops between
+// publishing it and marking it published, so publishers should de-duplicate
+// on EventID. Messages of one aggregate are published in the order they were
+// written.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Message is one recorded event.
+type Message struct {
+	ID            int64
+	EventID       string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       []byte
+	OccurredAt    time.Time
+	Attempts      int
+}
+
+// Publisher forwards messages, e.g. to webhooks or a message broker. An error
+// leaves the message, and every later message of the same aggregate, in the
+// outbox to be retried.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, msg Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// Write records msg in the outbox as part of tx, the transaction of the
+// change it describes.
+func Write(ctx context.Context, tx *sql.Tx, msg Message) error {
+	query := "INSERT INTO outbox (event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at, next_attempt_at) VALUES ($1, $2, $3, $4, $5, $6, $6)"
+	_, err := tx.ExecContext(ctx, query, msg.EventID, msg.AggregateType, msg.AggregateID, msg.EventType, string(msg.Payload), msg.OccurredAt)
+	return err
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/retry"
+)
+
+// claimQuery picks the oldest unpublished message of each aggregate. Rows
+// stay locked until the batch commits, and SKIP LOCKED lets other relays
+// move on to other aggregates; a later message of the same aggregate is not
+// eligible while an earlier one is unpublished, which keeps the order.
+const claimQuery = `SELECT o.id, o.event_id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload, o.occurred_at, o.attempts
+FROM outbox o
+WHERE o.published_at IS NULL AND o.next_attempt_at <= $1
+AND NOT EXISTS (
+	SELECT 1 FROM outbox p
+	WHERE p.published_at IS NULL AND p.aggregate_type = o.aggregate_type AND p.aggregate_id = o.aggregate_id AND p.id < o.id
+)
+ORDER BY o.id
+LIMIT $2
+FOR UPDATE SKIP LOCKED`
+
+// Options tune the relay. A failed message is retried after InitialBackoff,
+// doubling up to MaxBackoff. Published messages are deleted once older than
+// Retention.
+type Options struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Retention      time.Duration
+}
+
+// Relay forwards outbox messages to a Publisher.
+type Relay struct {
+	DB        *sql.DB
+	Publisher Publisher
+	Options   Options
+
+	now  func() time.Time
+	wake chan struct{}
+}
+
+func NewRelay(db *sql.DB, publisher Publisher, opts Options) *Relay {
+	return &Relay{
+		DB:        db,
+		Publisher: publisher,
+		Options:   opts,
+		now:       time.Now,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the relay before the next poll.
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the relay in the background. The returned function stops it
+// and waits for the batch in progress, or for ctx to end.
+func (r *Relay) Start() func(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	return func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	}
+}
+
+// Run relays messages every PollInterval, or sooner when notified, until
+// ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Options.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep going while messages are published: each one may unblock the
+		// next message of its aggregate.
+		published, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("outbox relay failed", "error", err)
+		}
+		if err == nil && published > 0 && ctx.Err() == nil {
+			continue
+		}
+		if err := r.prune(ctx); err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Warn("outbox prune failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// RunOnce publishes one batch and returns how many messages were published.
+// The batch is committed even when ctx is cancelled mid-way, so messages
+// that were published are not published again.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := r.now()
+	messages, err := claim(ctx, tx, now, r.Options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	published := 0
+	for _, msg := range messages {
+		log := logger.FromContext(ctx).With("outbox_id", msg.ID, "event_id", msg.EventID, "event", msg.EventType, "aggregate_id", msg.AggregateID)
+		if err := r.Publisher.Publish(ctx, msg); err != nil {
+			attempts := msg.Attempts + 1
+			backoff := retry.Backoff{Initial: r.Options.InitialBackoff, Max: r.Options.MaxBackoff}.Delay(attempts)
+			log.Warn("outbox publish failed", "attempt", attempts, "retry_in", backoff, "error", err)
+			metrics.OutboxMessagesTotal.WithLabelValues(msg.EventType, "failed").Inc()
+			_, err = tx.ExecContext(ctx, "UPDATE outbox SET attempts = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4", attempts, now.Add(backoff), err.Error(), msg.ID)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE outbox SET published_at = $1 WHERE id = $2", now, msg.ID); err != nil {
+			return 0, err
+		}
+		metrics.OutboxMessagesTotal.WithLabelValues(msg.EventType, "published").Inc()
+		log.Debug("outbox message published")
+		published++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+func claim(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]Message, error) {
+	rows, err := tx.QueryContext(ctx, claimQuery, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var payload []byte
+		if err := rows.Scan(&msg.ID, &msg.EventID, &msg.AggregateType, &msg.AggregateID, &msg.EventType, &payload, &msg.OccurredAt, &msg.Attempts); err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+func (r *Relay) prune(ctx context.Context) error {
+	if r.Options.Retention <= 0 {
+		return nil
+	}
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM outbox WHERE published_at < $1", r.now().Add(-r.Options.Retention))
+	return err
+}
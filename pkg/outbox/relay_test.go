@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var messageColumns = []string{"id", "event_id", "aggregate_type", "aggregate_id", "event_type", "payload", "occurred_at", "attempts"}
+
+func newTestRelay(t *testing.T, publisher Publisher) (*Relay, sqlmock.Sqlmock, time.Time) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	relay := NewRelay(db, publisher, Options{
+		PollInterval:   time.Second,
+		BatchSize:      10,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Retention:      time.Hour,
+	})
+	relay.now = func() time.Time { return now }
+	return relay, mock, now
+}
+
+func TestWrite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	occurredAt := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO outbox").
+		WithArgs("evt_1", "book", "1", "book.created", `{"id":1}`, occurredAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+	err = Write(context.Background(), tx, Message{
+		EventID:       "evt_1",
+		AggregateType: "book",
+		AggregateID:   "1",
+		EventType:     "book.created",
+		Payload:       []byte(`{"id":1}`),
+		OccurredAt:    occurredAt,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunOnce(t *testing.T) {
+	var published []string
+	relay, mock, now := newTestRelay(t, PublisherFunc(func(ctx context.Context, msg Message) error {
+		if msg.AggregateID == "2" {
+			return errors.New("broker unavailable")
+		}
+		published = append(published, msg.EventID)
+		return nil
+	}))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows(messageColumns).
+			AddRow(1, "evt_1", "book", "1", "book.created", []byte(`{"id":1}`), now, 0).
+			AddRow(2, "evt_2", "book", "2", "book.deleted", []byte(`{"id":2}`), now, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET published_at = $1 WHERE id = $2")).
+		WithArgs(now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The third attempt waits 4s: 1s doubled twice.
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET attempts = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4")).
+		WithArgs(3, now.Add(4*time.Second), "broker unavailable", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"evt_1"}, published)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunOnceRollsBackOnError(t *testing.T) {
+	relay, mock, now := newTestRelay(t, PublisherFunc(func(context.Context, Message) error { return nil }))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows(messageColumns).
+			AddRow(1, "evt_1", "book", "1", "book.created", []byte(`{}`), now, 0))
+	mock.ExpectExec("UPDATE outbox SET published_at").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err := relay.RunOnce(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPrune(t *testing.T) {
+	relay, mock, now := newTestRelay(t, nil)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM outbox WHERE published_at < $1")).
+		WithArgs(now.Add(-time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	assert.NoError(t, relay.prune(context.Background()))
+
+	// Retention 0 keeps published messages.
+	relay.Options.Retention = 0
+	assert.NoError(t, relay.prune(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStart(t *testing.T) {
+	relay, mock, now := newTestRelay(t, PublisherFunc(func(context.Context, Message) error { return nil }))
+	relay.Options.Retention = 0
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows(messageColumns))
+	mock.ExpectCommit()
+
+	stop := relay.Start()
+	assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, stop(ctx))
+}
@@ -0,0 +1,190 @@
+// Package pgnotify delivers Postgres NOTIFY messages to in-process handlers,
+// so every replica learns about changes written by the others.
+//
+// Notifications are best effort: those sent while the connection is down
+// are lost. After reconnecting, the listener calls Resync on every handler so
+// it can drop state that may be stale.
+package pgnotify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+// Handler receives the notifications of a channel.
+type Handler interface {
+	HandleNotification(ctx context.Context, payload string) error
+	// Resync is called when notifications may have been missed.
+	Resync(ctx context.Context) error
+}
+
+// HandlerFunc adapts a function to Handler; its Resync does nothing.
+type HandlerFunc func(ctx context.Context, payload string) error
+
+func (f HandlerFunc) HandleNotification(ctx context.Context, payload string) error {
+	return f(ctx, payload)
+}
+
+func (f HandlerFunc) Resync(context.Context) error {
+	return nil
+}
+
+// Options tune the connection. A lost connection is retried after
+// MinReconnect, doubling up to MaxReconnect; PingInterval detects
+// connections that died silently.
+type Options struct {
+	MinReconnect time.Duration
+	MaxReconnect time.Duration
+	PingInterval time.Duration
+}
+
+// conn is the part of *pq.Listener the listener uses.
+type conn interface {
+	Listen(channel string) error
+	Notifications() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+type pqConn struct {
+	*pq.Listener
+}
+
+func (c pqConn) Notifications() <-chan *pq.Notification {
+	return c.Notify
+}
+
+// Listener listens on one channel and fans its notifications out to the
+// subscribed handlers, in the order they subscribed.
+type Listener struct {
+	Channel string
+	Options Options
+
+	mu       sync.Mutex
+	handlers []Handler
+	connect  func(callback pq.EventCallbackType) conn
+}
+
+// NewListener listens on channel of the database at dsn, over a dedicated
+// connection outside the pool.
+func NewListener(dsn, channel string, opts Options) *Listener {
+	return &Listener{
+		Channel: channel,
+		Options: opts,
+		connect: func(callback pq.EventCallbackType) conn {
+			return pqConn{pq.NewListener(dsn, opts.MinReconnect, opts.MaxReconnect, callback)}
+		},
+	}
+}
+
+// Subscribe adds h to the handlers.
+func (l *Listener) Subscribe(h Handler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers = append(l.handlers, h)
+}
+
+// Start listens in the background. The returned function stops listening,
+// or gives up when ctx ends.
+func (l *Listener) Start() func(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := l.Run(ctx); err != nil {
+			logger.FromContext(ctx).Error("notification listener stopped", "channel", l.Channel, "error", err)
+		}
+	}()
+	return func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	}
+}
+
+// Run listens until ctx is cancelled. It waits for the database while it is
+// unreachable and only fails if Postgres refuses the LISTEN.
+func (l *Listener) Run(ctx context.Context) error {
+	log := logger.FromContext(ctx).With("channel", l.Channel)
+	c := l.connect(func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnected:
+			log.Info("notification listener connected")
+		case pq.ListenerEventDisconnected:
+			log.Warn("notification listener disconnected", "error", err)
+		case pq.ListenerEventReconnected:
+			metrics.NotificationListenerReconnectsTotal.WithLabelValues(l.Channel).Inc()
+			log.Info("notification listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Warn("notification listener cannot connect", "error", err)
+		}
+	})
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+	defer c.Close()
+
+	// Listen blocks until the first connection succeeds; Close unblocks it.
+	if err := c.Listen(l.Channel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	ping := time.NewTicker(l.Options.PingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-c.Notifications():
+			if !ok {
+				return nil
+			}
+			// pq sends nil after reconnecting.
+			if n == nil {
+				l.resync(ctx)
+				continue
+			}
+			l.dispatch(ctx, n.Extra)
+		case <-ping.C:
+			if err := c.Ping(); err != nil {
+				log.Debug("notification listener ping failed", "error", err)
+			}
+		}
+	}
+}
+
+func (l *Listener) subscribers() []Handler {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Handler(nil), l.handlers...)
+}
+
+func (l *Listener) dispatch(ctx context.Context, payload string) {
+	result := "handled"
+	for _, h := range l.subscribers() {
+		if err := h.HandleNotification(ctx, payload); err != nil {
+			result = "failed"
+			logger.FromContext(ctx).Warn("notification handler failed", "channel", l.Channel, "error", err)
+		}
+	}
+	metrics.NotificationsTotal.WithLabelValues(l.Channel, result).Inc()
+}
+
+func (l *Listener) resync(ctx context.Context) {
+	for _, h := range l.subscribers() {
+		if err := h.Resync(ctx); err != nil {
+			logger.FromContext(ctx).Warn("notification resync failed", "channel", l.Channel, "error", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package pgnotify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConn struct {
+	listenErr     error
+	notifications chan *pq.Notification
+
+	mu       sync.Mutex
+	listened []string
+	closed   bool
+}
+
+func (c *fakeConn) Listen(channel string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listened = append(c.listened, channel)
+	return c.listenErr
+}
+
+func (c *fakeConn) Notifications() <-chan *pq.Notification { return c.notifications }
+func (c *fakeConn) Ping() error                            { return nil }
+
+func (c *fakeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) state() ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.listened...), c.closed
+}
+
+type recordingHandler struct {
+	mu       sync.Mutex
+	payloads []string
+	resyncs  int
+	err      error
+}
+
+func (h *recordingHandler) HandleNotification(_ context.Context, payload string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.payloads = append(h.payloads, payload)
+	return h.err
+}
+
+func (h *recordingHandler) Resync(context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resyncs++
+	return nil
+}
+
+func (h *recordingHandler) snapshot() ([]string, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.payloads...), h.resyncs
+}
+
+func newTestListener(c *fakeConn) *Listener {
+	l := &Listener{Channel: "book_changes", Options: Options{PingInterval: time.Hour}}
+	l.connect = func(pq.EventCallbackType) conn { return c }
+	return l
+}
+
+func TestListenerFansOut(t *testing.T) {
+	c := &fakeConn{notifications: make(chan *pq.Notification, 4)}
+	l := newTestListener(c)
+	first := &recordingHandler{err: errors.New("cache unavailable")}
+	second := &recordingHandler{}
+	l.Subscribe(first)
+	l.Subscribe(second)
+
+	stop := l.Start()
+	c.notifications <- &pq.Notification{Channel: "book_changes", Extra: `{"type":"book.created"}`}
+	// pq sends nil after reconnecting.
+	c.notifications <- nil
+	c.notifications <- &pq.Notification{Channel: "book_changes", Extra: `{"type":"book.deleted"}`}
+
+	want := []string{`{"type":"book.created"}`, `{"type":"book.deleted"}`}
+	assert.Eventually(t, func() bool {
+		payloads, resyncs := second.snapshot()
+		return len(payloads) == 2 && resyncs == 1
+	}, time.Second, time.Millisecond)
+	// A failing handler does not keep the others from the notification.
+	payloads, resyncs := first.snapshot()
+	assert.Equal(t, want, payloads)
+	assert.Equal(t, 1, resyncs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, stop(ctx))
+	listened, closed := c.state()
+	assert.Equal(t, []string{"book_changes"}, listened)
+	assert.True(t, closed)
+}
+
+func TestListenerListenError(t *testing.T) {
+	c := &fakeConn{listenErr: errors.New("permission denied"), notifications: make(chan *pq.Notification)}
+	l := newTestListener(c)
+
+	err := l.Run(context.Background())
+	assert.EqualError(t, err, "permission denied")
+	_, closed := c.state()
+	assert.True(t, closed)
+}
+
+func TestHandlerFunc(t *testing.T) {
+	var got string
+	h := HandlerFunc(func(_ context.Context, payload string) error {
+		got = payload
+		return nil
+	})
+	assert.NoError(t, h.HandleNotification(context.Background(), "hello"))
+	assert.NoError(t, h.Resync(context.Background()))
+	assert.Equal(t, "hello", got)
+}
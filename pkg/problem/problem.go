@@ -0,0 +1,115 @@
+// Package problem writes RFC 7807 application/problem+json error responses.
+// Clients should branch on Code, which is stable, rather than on Detail.
+package problem
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+const ContentType = "application/problem+json"
+
+// TypePrefix is prepended to Code to form the problem type URI.
+const TypePrefix = "urn:bookstore:problem:"
+
+// Stable error codes.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeInvalidBody       = "invalid_body"
+	CodeValidationFailed  = "validation_failed"
+	CodeNotFound          = "not_found"
+	CodeMethodNotAllowed  = "method_not_allowed"
+	CodeNotAcceptable     = "not_acceptable"
+	CodeUnsupportedMedia  = "unsupported_media_type"
+	CodeBookNotFound      = "book_not_found"
+	CodeAPIKeyNotFound    = "api_key_not_found"
+	CodeWebhookNotFound   = "webhook_not_found"
+	CodeDeliveryNotFound  = "webhook_delivery_not_found"
+	CodeDeliveryNotFailed = "webhook_delivery_not_dead_lettered"
+	CodeJobNotFound       = "job_not_found"
+	CodeJobNotRetryable   = "job_not_retryable"
+	CodeJobNotCancellable = "job_not_cancellable"
+	CodeAPIKeyRequired    = "api_key_required"
+	CodeAPIKeyInvalid     = "api_key_invalid"
+	CodeAPIKeyExpired     = "api_key_expired"
+	CodeAPIKeyRevoked     = "api_key_revoked"
+	CodeInsufficientScope = "insufficient_scope"
+	CodeRateLimited       = "rate_limited"
+	CodeIdempotencyKey    = "idempotency_key_invalid"
+	CodeIdempotencyReuse  = "idempotency_key_reused"
+	CodeIdempotencyBusy   = "idempotency_key_in_progress"
+	CodeInternal          = "internal_error"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, code, detail string) Problem {
+	return Problem{
+		Type:   TypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func (p Problem) WithErrors(errs ...FieldError) Problem {
+	p.Errors = append(p.Errors, errs...)
+	return p
+}
+
+// Abort writes p and stops the handler chain. Instance and RequestID are
+// filled in from the request when not already set.
+func Abort(c *gin.Context, p Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = logger.RequestIDFromContext(c.Request.Context())
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Internal logs err and aborts with a 500 that does not leak its message.
+func Internal(c *gin.Context, err error) {
+	logger.FromContext(c.Request.Context()).Error("request failed", "error", err)
+	Abort(c, New(http.StatusInternalServerError, CodeInternal, "An unexpected error occurred."))
+}
+
+func NotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		Abort(c, New(http.StatusNotFound, CodeNotFound, "No route matches "+c.Request.Method+" "+c.Request.URL.Path+"."))
+	}
+}
+
+func MethodNotAllowed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		Abort(c, New(http.StatusMethodNotAllowed, CodeMethodNotAllowed, c.Request.Method+" is not allowed on "+c.Request.URL.Path+"."))
+	}
+}
+
+// Recovery turns panics into a 500 problem response.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		logger.FromContext(c.Request.Context()).Error("panic recovered", "panic", recovered)
+		Abort(c, New(http.StatusInternalServerError, CodeInternal, "An unexpected error occurred."))
+	})
+}
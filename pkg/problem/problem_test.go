@@ -0,0 +1,80 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+)
+
+func serve(router *gin.Engine, method, url string) (*httptest.ResponseRecorder, Problem) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, url, nil)
+	req = req.WithContext(logger.WithRequestID(req.Context(), "req-123"))
+	router.ServeHTTP(w, req)
+
+	var p Problem
+	json.Unmarshal(w.Body.Bytes(), &p)
+	return w, p
+}
+
+func TestAbort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/books/:bookID", func(c *gin.Context) {
+		Abort(c, New(http.StatusBadRequest, CodeValidationFailed, "The request body is invalid.").
+			WithErrors(FieldError{Field: "name", Rule: "required", Message: "name is required"}))
+	})
+
+	w, p := serve(router, http.MethodGet, "/books/7")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, Problem{
+		Type:      "urn:bookstore:problem:validation_failed",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    "The request body is invalid.",
+		Instance:  "/books/7",
+		Code:      CodeValidationFailed,
+		RequestID: "req-123",
+		Errors:    []FieldError{{Field: "name", Rule: "required", Message: "name is required"}},
+	}, p)
+}
+
+func TestRouterFallbacks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NotFound())
+	router.NoMethod(MethodNotAllowed())
+	router.Use(Recovery())
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Unknown route", method: http.MethodGet, url: "/nope", expectedStatus: http.StatusNotFound, expectedCode: CodeNotFound},
+		{name: "Wrong method", method: http.MethodPost, url: "/panic", expectedStatus: http.StatusMethodNotAllowed, expectedCode: CodeMethodNotAllowed},
+		{name: "Panic", method: http.MethodGet, url: "/panic", expectedStatus: http.StatusInternalServerError, expectedCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, p := serve(router, tt.method, tt.url)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedCode, p.Code)
+			assert.Equal(t, tt.expectedStatus, p.Status)
+		})
+	}
+}
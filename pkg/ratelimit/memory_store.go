@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket is back to capacity under its own limit.
+	full time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	Now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		Now:     time.Now,
+	}
+}
+
+func (ms *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.Now()
+	ms.sweep(now, limit.Period)
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+
+	b, ok := ms.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		ms.buckets[key] = b
+	}
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+		b.last = now
+	}
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((capacity - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again,
+// each by its own limit. period only paces how often the map is scanned.
+func (ms *MemoryStore) sweep(now time.Time, period time.Duration) {
+	if now.Sub(ms.lastSweep) < period {
+		return
+	}
+	for key, b := range ms.buckets {
+		if !now.Before(b.full) {
+			delete(ms.buckets, key)
+		}
+	}
+	ms.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
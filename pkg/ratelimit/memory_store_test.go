@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Date(2024, time.November, 14, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+
+	first, err := store.Take("client", limit)
+	assert.NoError(t, err)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+
+	second, _ := store.Take("client", limit)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+
+	third, _ := store.Take("client", limit)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, 5*time.Second, third.RetryAfter)
+	assert.Equal(t, 10*time.Second, third.Reset)
+
+	other, _ := store.Take("other", limit)
+	assert.True(t, other.Allowed)
+
+	now = now.Add(5 * time.Second)
+	refilled, _ := store.Take("client", limit)
+	assert.True(t, refilled.Allowed)
+}
+
+func TestMemoryStoreSweepMixedLimits(t *testing.T) {
+	now := time.Date(2024, time.November, 14, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	hourly := Limit{Requests: 1, Period: time.Hour}
+	perSecond := Limit{Requests: 1, Period: time.Second}
+
+	first, _ := store.Take("hourly", hourly)
+	assert.True(t, first.Allowed)
+	store.Take("fast", perSecond)
+
+	// A sweep paced by the short limit keeps the drained hourly bucket and
+	// drops only the refilled one.
+	now = now.Add(2 * time.Second)
+	store.Take("trigger", perSecond)
+	assert.Contains(t, store.buckets, "hourly")
+	assert.NotContains(t, store.buckets, "fast")
+
+	again, _ := store.Take("hourly", hourly)
+	assert.False(t, again.Allowed)
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{value: "100/1m", want: Limit{Requests: 100, Period: time.Minute}},
+		{value: " 5 / 1s ", want: Limit{Requests: 5, Period: time.Second}},
+		{value: "100", wantErr: true},
+		{value: "0/1m", wantErr: true},
+		{value: "10/forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseLimit(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRouteLimitsUnmarshalText(t *testing.T) {
+	var routes RouteLimits
+	err := routes.UnmarshalText([]byte("get /books/=300/1m, POST /books/=20/1m"))
+	assert.NoError(t, err)
+	assert.Equal(t, RouteLimits{
+		"GET /books/":  {Requests: 300, Period: time.Minute},
+		"POST /books/": {Requests: 20, Period: time.Minute},
+	}, routes)
+
+	assert.Error(t, routes.UnmarshalText([]byte("/books/=300/1m")))
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period, with bursts of up to Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RouteLimits is keyed by "<METHOD> <route>" without the version prefix,
+// e.g. "GET /books/" for /api/v1/books/, its legacy alias and the
+// GetAllBooks gRPC method.
+type RouteLimits map[string]Limit
+
+// Store keeps token buckets. The in-memory store is enough for a single
+// instance; a shared store can implement the same interface.
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
+
+// Seconds rounds d up to whole seconds, the unit of the RateLimit-Reset and
+// Retry-After headers.
+func Seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ParseLimit parses "<requests>/<period>", e.g. "100/1m".
+func ParseLimit(value string) (Limit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid request count in rate limit %q", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in rate limit %q", value)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+func (l *Limit) UnmarshalText(text []byte) error {
+	limit, err := ParseLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = limit
+	return nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// UnmarshalText parses "GET /books/=300/1m,POST /books/=20/1m".
+func (r *RouteLimits) UnmarshalText(text []byte) error {
+	routes := RouteLimits{}
+	for _, entry := range strings.Split(string(text), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, rawLimit, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid route limit %q, expected <METHOD> <route>=<requests>/<period>", entry)
+		}
+		method, path, found := strings.Cut(strings.TrimSpace(route), " ")
+		if !found {
+			return fmt.Errorf("invalid route %q, expected <METHOD> <route>", route)
+		}
+		limit, err := ParseLimit(rawLimit)
+		if err != nil {
+			return err
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = limit
+	}
+	*r = routes
+	return nil
+}
@@ -0,0 +1,34 @@
+// Package retry computes the waits between attempts of background work
+// such as job runs, webhook deliveries and outbox publishes.
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff waits Initial after the first failed attempt, doubling for every
+// further attempt up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	// Jitter picks each wait at random from [d/2, d] ("equal jitter"), so
+	// replicas that fail together don't retry in lockstep.
+	Jitter bool
+}
+
+// Delay is the wait after the given failed attempt (1-based).
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	half := d / 2
+	if !b.Jitter || half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int64N(int64(d-half)+1))
+}
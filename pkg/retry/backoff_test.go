@@ -0,0 +1,36 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 10 * time.Second},
+		{attempt: 2, want: 20 * time.Second},
+		{attempt: 4, want: 80 * time.Second},
+		{attempt: 10, want: 5 * time.Minute},
+		{attempt: 100, want: 5 * time.Minute},
+	}
+	backoff := Backoff{Initial: 10 * time.Second, Max: 5 * time.Minute}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, backoff.Delay(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func TestBackoffDelayWithJitter(t *testing.T) {
+	backoff := Backoff{Initial: 100 * time.Millisecond, Max: time.Second, Jitter: true}
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			d := backoff.Delay(attempt)
+			assert.GreaterOrEqual(t, d, max/2)
+			assert.LessOrEqual(t, d, max)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+const V1Prefix = "/api/v1"
+
+// LegacyDeprecatedSince is when the unprefixed routes were deprecated in
+// favour of /api/v1.
+var LegacyDeprecatedSince = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Version registers one version of the API on a router group. Each version
+// brings its own controllers, so a V2 with different response shapes can be
+// mounted at /api/v2 next to V1.
+type Version interface {
+	Register(router gin.IRouter)
+}
+
+// V1 is the first version of the API, also served at the legacy unprefixed
+// paths.
+type V1 struct {
+	BookController   *controllers.BookController
+	BookMiddlewares  []gin.HandlerFunc
+	APIKeyController *controllers.APIKeyController
+	APIKeyMiddleware *middlewares.APIKeyMiddleware
+	// AdminMiddlewares run on the /admin routes once the admin scope is
+	// checked.
+	AdminMiddlewares []gin.HandlerFunc
+	// WebhookController is nil when webhooks are disabled.
+	WebhookController *controllers.WebhookController
+	// StreamController is nil when the live feed is disabled.
+	StreamController  *controllers.StreamController
+	StreamMiddlewares []gin.HandlerFunc
+	// JobController is nil when the job runner is disabled.
+	JobController *controllers.JobController
+}
+
+func (v V1) Register(router gin.IRouter) {
+	RegisterBookRoutes(router, v.BookController, v.BookMiddlewares...)
+	RegisterAPIKeyRoutes(router, v.APIKeyController, v.APIKeyMiddleware, v.AdminMiddlewares...)
+	if v.WebhookController != nil {
+		RegisterWebhookRoutes(router, v.WebhookController, v.APIKeyMiddleware, v.AdminMiddlewares...)
+	}
+	if v.StreamController != nil {
+		RegisterStreamRoutes(router, v.StreamController, v.StreamMiddlewares...)
+	}
+	if v.JobController != nil {
+		RegisterJobRoutes(router, v.JobController, v.APIKeyMiddleware, v.AdminMiddlewares...)
+	}
+}
+
+func MountVersion(router *gin.Engine, prefix string, version Version) {
+	version.Register(router.Group(prefix, middlewares.RoutePrefix(prefix)))
+}
+
+// MountLegacy serves version at the unprefixed paths that predate versioning,
+// marking every response as deprecated.
+func MountLegacy(router *gin.Engine, version Version, deprecation middlewares.Deprecation) {
+	version.Register(router.Group("", deprecation.Handler()))
+}
@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+func TestVersionedAndLegacyRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockBookService := new(MockBookService)
+	mockBookService.On("GetAllBooks", mock.Anything).Return([]bookservices.BookResponse{}, nil)
+
+	v1 := V1{
+		BookController:   controllers.NewBookController(mockBookService),
+		APIKeyController: controllers.NewAPIKeyController(nil),
+		APIKeyMiddleware: middlewares.NewAPIKeyMiddleware(nil, false, ""),
+	}
+	router := gin.New()
+	MountVersion(router, V1Prefix, v1)
+	MountLegacy(router, v1, middlewares.Deprecation{
+		Since:           LegacyDeprecatedSince,
+		Sunset:          time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		SuccessorPrefix: V1Prefix,
+	})
+
+	tests := []struct {
+		name           string
+		url            string
+		wantDeprecated bool
+	}{
+		{name: "Versioned", url: "/api/v1/books/"},
+		{name: "Legacy alias", url: "/books/", wantDeprecated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			if tt.wantDeprecated {
+				assert.NotEmpty(t, w.Header().Get("Deprecation"))
+				assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+				assert.Equal(t, `</api/v1/books/>; rel="successor-version"`, w.Header().Get("Link"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+			}
+		})
+	}
+}
+
+func TestAdminMiddlewares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v1 := newV1()
+	v1.APIKeyMiddleware = middlewares.NewAPIKeyMiddleware(nil, false, "admin-secret")
+	v1.AdminMiddlewares = []gin.HandlerFunc{func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusTeapot)
+	}}
+	router := gin.New()
+	MountVersion(router, V1Prefix, v1)
+
+	for _, url := range []string{"/admin/api-keys/", "/admin/webhooks/", "/admin/webhook-deliveries/1/retry", "/admin/jobs/1/retry", "/admin/jobs/1/cancel"} {
+		// The admin scope is checked first.
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, V1Prefix+url, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, url)
+
+		w = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, V1Prefix+url, nil)
+		req.Header.Set("X-API-Key", "admin-secret")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusTeapot, w.Code, url)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+func RegisterAPIKeyRoutes(router gin.IRouter, apiKeyController *controllers.APIKeyController, apiKeyMiddleware *middlewares.APIKeyMiddleware, adminMiddlewares ...gin.HandlerFunc) {
+
+	apiKeyRoutes := router.Group("/admin/api-keys", apiKeyMiddleware.Authenticate(), apiKeyMiddleware.RequireScope(apikeyservices.ScopeAdmin))
+	apiKeyRoutes.Use(adminMiddlewares...)
+	{
+		apiKeyRoutes.GET("/", apiKeyController.GetAllAPIKeys)
+		apiKeyRoutes.POST("/", apiKeyController.IssueAPIKey)
+		apiKeyRoutes.DELETE("/:apiKeyID", apiKeyController.RevokeAPIKeyByID)
+	}
+
+}
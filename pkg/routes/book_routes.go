@@ -5,9 +5,9 @@ import (
 	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
 )
 
-func RegisterBookRoutes(router *gin.Engine, bookController *controllers.BookController) {
+func RegisterBookRoutes(router gin.IRouter, bookController *controllers.BookController, middlewares ...gin.HandlerFunc) {
 
-	bookRoutes := router.Group("/books")
+	bookRoutes := router.Group("/books", middlewares...)
 	{
 		bookRoutes.GET("/", bookController.GetAllBooks)
 		bookRoutes.GET("/:bookID", bookController.GetBookByID)
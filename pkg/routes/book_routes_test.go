@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -16,28 +17,33 @@ type MockBookService struct {
 	mock.Mock
 }
 
-func (m *MockBookService) CreateBook(book bookservices.BookRequest) (bookservices.BookResponse, error) {
-	args := m.Called(book)
+func (m *MockBookService) CreateBook(ctx context.Context, book bookservices.BookRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, book)
 	return args.Get(0).(bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) GetAllBooks() ([]bookservices.BookResponse, error) {
-	args := m.Called()
+func (m *MockBookService) GetAllBooks(ctx context.Context) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) GetBookByID(bookID string) (bookservices.BookResponse, error) {
-	args := m.Called(bookID)
+func (m *MockBookService) GetBookByID(ctx context.Context, bookID string) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID)
 	return args.Get(0).(bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) UpdateBookByID(bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
-	args := m.Called(bookID, book)
+func (m *MockBookService) GetBooksByIDs(ctx context.Context, bookIDs []string) ([]bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookIDs)
+	return args.Get(0).([]bookservices.BookResponse), args.Error(1)
+}
+
+func (m *MockBookService) UpdateBookByID(ctx context.Context, bookID string, book bookservices.BookUpdateRequest) (bookservices.BookResponse, error) {
+	args := m.Called(ctx, bookID, book)
 	return args.Get(0).(bookservices.BookResponse), args.Error(1)
 }
 
-func (m *MockBookService) DeleteBookByID(bookID string) error {
-	args := m.Called(bookID)
+func (m *MockBookService) DeleteBookByID(ctx context.Context, bookID string) error {
+	args := m.Called(ctx, bookID)
 	return args.Error(0)
 }
 
@@ -61,7 +67,7 @@ func TestBookRoutes(t *testing.T) {
 			method: "GET",
 			url:    "/books/",
 			mockFunc: func() {
-				mockBookService.On("GetAllBooks").Return([]bookservices.BookResponse{}, nil).Once()
+				mockBookService.On("GetAllBooks", mock.Anything).Return([]bookservices.BookResponse{}, nil).Once()
 			},
 			expectedCode: http.StatusOK,
 		},
@@ -69,7 +75,7 @@ func TestBookRoutes(t *testing.T) {
 			method: "GET",
 			url:    "/books/1",
 			mockFunc: func() {
-				mockBookService.On("GetBookByID", "1").Return(bookservices.BookResponse{}, nil).Once()
+				mockBookService.On("GetBookByID", mock.Anything, "1").Return(bookservices.BookResponse{}, nil).Once()
 			},
 			expectedCode: http.StatusOK,
 		},
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/openapi"
+)
+
+func RegisterDocsRoutes(router *gin.Engine) {
+	router.GET("/openapi.json", openapi.Handler())
+	router.GET("/docs", openapi.DocsHandler("/openapi.json"))
+}
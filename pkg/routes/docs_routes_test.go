@@ -0,0 +1,103 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	graphqlapi "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/graphql_api"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/health"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/openapi"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/stream"
+)
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+func newV1() V1 {
+	return V1{
+		BookController:    controllers.NewBookController(new(MockBookService)),
+		APIKeyController:  controllers.NewAPIKeyController(nil),
+		APIKeyMiddleware:  middlewares.NewAPIKeyMiddleware(nil, false, ""),
+		WebhookController: controllers.NewWebhookController(nil, nil),
+		StreamController:  controllers.NewStreamController(stream.NewBroker(stream.Options{}), time.Second, time.Second, nil),
+		JobController:     controllers.NewJobController(nil, nil),
+	}
+}
+
+// newAPIRouter registers every API route the way main does.
+func newAPIRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	MountVersion(router, V1Prefix, newV1())
+	MountLegacy(router, newV1(), middlewares.Deprecation{SuccessorPrefix: V1Prefix})
+	RegisterHealthRoutes(router, controllers.NewHealthController(health.New(0)))
+	RegisterMetricsRoutes(router)
+	RegisterDocsRoutes(router)
+	graphqlHandler, err := graphqlapi.NewHandler(graphqlapi.Services{Books: new(MockBookService)}, graphqlapi.Limits{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	RegisterGraphQLRoutes(router, graphqlHandler, true)
+	return router
+}
+
+func TestOpenAPICoversEveryRoute(t *testing.T) {
+	spec, err := openapi.Spec()
+	assert.NoError(t, err)
+
+	// Legacy aliases are documented through their /api/v1 successors.
+	legacy := map[string]bool{}
+	legacyRouter := gin.New()
+	MountLegacy(legacyRouter, newV1(), middlewares.Deprecation{})
+	for _, route := range legacyRouter.Routes() {
+		legacy[route.Method+" "+route.Path] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range newAPIRouter().Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		if legacy[route.Method+" "+route.Path] {
+			path = V1Prefix + path
+		}
+		registered[method+" "+path] = true
+
+		_, ok := spec.Paths[path][method]
+		assert.True(t, ok, "route %s %s is missing from openapi.yaml", route.Method, path)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, registered[method+" "+path], "openapi.yaml documents %s %s, which is not registered", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestDocsRoutes(t *testing.T) {
+	router := newAPIRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"/openapi.json"`)
+}
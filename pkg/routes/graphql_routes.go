@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	graphqlapi "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/graphql_api"
+)
+
+func RegisterGraphQLRoutes(router gin.IRouter, handler *graphqlapi.Handler, graphiQL bool, middlewares ...gin.HandlerFunc) {
+	graphqlRoutes := router.Group("", middlewares...)
+	{
+		graphqlRoutes.GET("/graphql", handler.Serve)
+		graphqlRoutes.POST("/graphql", handler.Serve)
+	}
+	if graphiQL {
+		router.GET("/graphiql", graphqlapi.GraphiQLHandler("/graphql"))
+	}
+}
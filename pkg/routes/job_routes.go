@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+func RegisterJobRoutes(router gin.IRouter, jobController *controllers.JobController, apiKeyMiddleware *middlewares.APIKeyMiddleware, adminMiddlewares ...gin.HandlerFunc) {
+
+	jobRoutes := router.Group("/admin/jobs", apiKeyMiddleware.Authenticate(), apiKeyMiddleware.RequireScope(apikeyservices.ScopeAdmin))
+	jobRoutes.Use(adminMiddlewares...)
+	{
+		jobRoutes.GET("/", jobController.GetJobs)
+		jobRoutes.GET("/:jobID", jobController.GetJobByID)
+		jobRoutes.POST("/:jobID/retry", jobController.RetryJobByID)
+		jobRoutes.POST("/:jobID/cancel", jobController.CancelJobByID)
+	}
+
+}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+)
+
+func RegisterMetricsRoutes(router *gin.Engine) {
+	router.GET("/metrics", metrics.Handler())
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+)
+
+// RegisterStreamRoutes registers the live feed next to the book routes. The
+// feed has its own middlewares because content negotiation would reject
+// text/event-stream.
+func RegisterStreamRoutes(router gin.IRouter, streamController *controllers.StreamController, middlewares ...gin.HandlerFunc) {
+
+	streamRoutes := router.Group("/books", middlewares...)
+	{
+		streamRoutes.GET("/stream", streamController.StreamBooks)
+		streamRoutes.GET("/ws", streamController.StreamBooksWebSocket)
+	}
+
+}
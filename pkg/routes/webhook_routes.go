@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/controllers"
+	apikeyservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/apikey_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/middlewares"
+)
+
+func RegisterWebhookRoutes(router gin.IRouter, webhookController *controllers.WebhookController, apiKeyMiddleware *middlewares.APIKeyMiddleware, adminMiddlewares ...gin.HandlerFunc) {
+
+	webhookRoutes := router.Group("/admin/webhooks", apiKeyMiddleware.Authenticate(), apiKeyMiddleware.RequireScope(apikeyservices.ScopeAdmin))
+	webhookRoutes.Use(adminMiddlewares...)
+	{
+		webhookRoutes.GET("/", webhookController.GetAllSubscriptions)
+		webhookRoutes.POST("/", webhookController.CreateSubscription)
+		webhookRoutes.GET("/:webhookID", webhookController.GetSubscriptionByID)
+		webhookRoutes.PUT("/:webhookID", webhookController.UpdateSubscriptionByID)
+		webhookRoutes.DELETE("/:webhookID", webhookController.DeleteSubscriptionByID)
+	}
+
+	deliveryRoutes := router.Group("/admin/webhook-deliveries", apiKeyMiddleware.Authenticate(), apiKeyMiddleware.RequireScope(apikeyservices.ScopeAdmin))
+	deliveryRoutes.Use(adminMiddlewares...)
+	{
+		deliveryRoutes.GET("/", webhookController.GetDeliveries)
+		deliveryRoutes.POST("/:deliveryID/retry", webhookController.RetryDeliveryByID)
+	}
+
+}
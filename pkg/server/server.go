@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// DrainDelay keeps serving after readiness flips to failing so load
+	// balancers stop routing new traffic before connections are closed.
+	DrainDelay      time.Duration
+	ShutdownTimeout time.Duration
+}
+
+func New(cfg Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// Run listens on srv.Addr and serves until ctx is cancelled, then shuts down
+// gracefully. See Serve.
+func Run(ctx context.Context, srv *http.Server, cfg Config, onDrain func(), cleanups ...func(context.Context) error) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, srv, ln, cfg, onDrain, cleanups...)
+}
+
+// Serve serves on ln until ctx is cancelled. It then calls onDrain, waits
+// DrainDelay, lets in-flight requests finish within ShutdownTimeout and
+// finally runs cleanups (closing the database, flushing exporters) in order.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, cfg Config, onDrain func(), cleanups ...func(context.Context) error) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("http server listening", "addr", ln.Addr().String())
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutdown signal received, draining")
+	if onDrain != nil {
+		onDrain()
+	}
+	time.Sleep(cfg.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	for _, cleanup := range cleanups {
+		if err := cleanup(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("http server stopped")
+	return nil
+}
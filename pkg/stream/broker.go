@@ -0,0 +1,264 @@
+// Package stream fans book events out to live subscribers, the Server-Sent
+// Events and WebSocket feeds.
+//
+// Every event gets a stream ID of the form "<epoch>-<seq>": epoch identifies
+// the broker, seq counts its events. A subscriber that reconnects with the
+// last ID it saw is sent the events it missed from the broker's history. If
+// the ID is from another broker (another replica or a restart) or older
+// than the history, it is sent a reset event instead and should reload the
+// books.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+)
+
+// EventReset tells a subscriber that it missed events, either because they
+// are no longer in the history or because the broker's source dropped
+// them.
+const EventReset = "reset"
+
+var (
+	// ErrSlowConsumer ends a subscription that fell more than
+	// SubscriberBuffer events behind. The subscriber can resume from the
+	// last event it received.
+	ErrSlowConsumer = errors.New("stream: subscriber fell behind")
+	// ErrClosed ends every subscription when the broker shuts down.
+	ErrClosed = errors.New("stream: broker closed")
+)
+
+// Event is one message of the feed. Data is the JSON envelope
+// {"id", "type", "occurred_at", "data"} that webhooks deliver.
+type Event struct {
+	ID   string
+	Type string
+	Data []byte
+
+	seq      uint64
+	sourceID string
+}
+
+// Envelope is the JSON body of book events, the same as webhook deliveries.
+type Envelope struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Options size the broker. History is how many events are kept for resuming
+// subscribers; SubscriberBuffer is how many events a subscriber may lag
+// before it is disconnected.
+type Options struct {
+	History          int
+	SubscriberBuffer int
+}
+
+// Broker keeps the recent history and the live subscriptions.
+type Broker struct {
+	Options Options
+
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	history []Event
+	seen    map[string]struct{}
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+func NewBroker(opts Options) *Broker {
+	return &Broker{
+		Options: opts,
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		seen:    map[string]struct{}{},
+		subs:    map[*Subscription]struct{}{},
+	}
+}
+
+// PublishBookEvent implements bookservices.EventPublisher. It never fails:
+// subscribers that cannot keep up are disconnected instead of slowing the
+// publisher down.
+func (b *Broker) PublishBookEvent(ctx context.Context, event bookservices.BookEvent) error {
+	var data interface{} = map[string]uint{"id": event.BookID}
+	if event.Book != nil {
+		data = event.Book
+	}
+	payload, err := json.Marshal(Envelope{ID: event.ID, Type: event.Type, OccurredAt: event.OccurredAt, Data: data})
+	if err != nil {
+		return err
+	}
+	b.Publish(event.ID, event.Type, payload)
+	return nil
+}
+
+// Publish sends an event to every subscriber. sourceID identifies the event
+// upstream; an event whose sourceID is still in the history is a redelivery
+// and is dropped.
+func (b *Broker) Publish(sourceID, eventType string, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	if sourceID != "" {
+		if _, ok := b.seen[sourceID]; ok {
+			return
+		}
+	}
+
+	b.seq++
+	event := Event{ID: b.id(b.seq), Type: eventType, Data: data, seq: b.seq, sourceID: sourceID}
+	b.remember(event)
+	for sub := range b.subs {
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub, ErrSlowConsumer)
+		}
+	}
+}
+
+// Subscribe starts a subscription. lastEventID is the stream ID of the last
+// event the subscriber received, or empty for a new subscriber; the events
+// it missed are queued first. The subscription ends immediately with
+// ErrClosed if the broker is shut down.
+func (b *Broker) Subscribe(lastEventID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	backlog := b.replay(lastEventID)
+	buffer := b.Options.SubscriberBuffer
+	if buffer < len(backlog) {
+		buffer = len(backlog)
+	}
+	sub := &Subscription{broker: b, events: make(chan Event, buffer), done: make(chan struct{})}
+	for _, event := range backlog {
+		sub.events <- event
+	}
+	if b.closed {
+		sub.err = ErrClosed
+		close(sub.done)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Resync sends a reset event to every subscriber, e.g. after the broker's
+// source may have dropped events. It implements bookservices.Resyncer.
+func (b *Broker) Resync(ctx context.Context) error {
+	b.Publish("", EventReset, []byte("{}"))
+	return nil
+}
+
+// Subscribers returns the number of live subscriptions.
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription with ErrClosed, so streaming handlers return
+// and the HTTP server can shut down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub, ErrClosed)
+	}
+}
+
+func (b *Broker) id(seq uint64) string {
+	return b.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+func (b *Broker) remember(event Event) {
+	if b.Options.History <= 0 {
+		return
+	}
+	if len(b.history) == b.Options.History {
+		delete(b.seen, b.history[0].sourceID)
+		b.history = b.history[1:]
+	}
+	b.history = append(b.history, event)
+	if event.sourceID != "" {
+		b.seen[event.sourceID] = struct{}{}
+	}
+}
+
+// replay returns the events after lastEventID, or a reset event when they
+// are not all in the history.
+func (b *Broker) replay(lastEventID string) []Event {
+	if lastEventID == "" {
+		return nil
+	}
+	reset := []Event{{ID: b.id(b.seq), Type: EventReset, Data: []byte("{}"), seq: b.seq}}
+
+	epoch, seqText, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != b.epoch {
+		return reset
+	}
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if err != nil || seq > b.seq {
+		return reset
+	}
+	// The history holds events b.seq-len(history)+1 through b.seq.
+	missed := b.seq - seq
+	if missed > uint64(len(b.history)) {
+		return reset
+	}
+	return append([]Event(nil), b.history[uint64(len(b.history))-missed:]...)
+}
+
+func (b *Broker) drop(sub *Subscription, err error) {
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.done)
+}
+
+// Subscription receives events until it is closed, falls behind or the
+// broker shuts down.
+type Subscription struct {
+	broker *Broker
+	events chan Event
+	done   chan struct{}
+	err    error
+}
+
+// Events delivers the backlog, then live events.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done is closed when the broker ends the subscription; Err says why.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err is ErrSlowConsumer or ErrClosed once Done is closed.
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes. It is safe to call after the broker ended the
+// subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	if _, ok := s.broker.subs[s]; ok {
+		delete(s.broker.subs, s)
+		close(s.done)
+	}
+}
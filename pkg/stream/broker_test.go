@@ -0,0 +1,155 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestPublishBookEvent(t *testing.T) {
+	b := NewBroker(Options{History: 10, SubscriberBuffer: 10})
+	sub := b.Subscribe("")
+	defer sub.Close()
+
+	occurredAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, b.PublishBookEvent(context.Background(), bookservices.BookEvent{
+		ID: "evt_1", Type: bookservices.EventBookCreated, BookID: 1, Book: &bookservices.BookResponse{ID: 1, Name: "Dune"}, OccurredAt: occurredAt,
+	}))
+	assert.NoError(t, b.PublishBookEvent(context.Background(), bookservices.BookEvent{
+		ID: "evt_2", Type: bookservices.EventBookDeleted, BookID: 1, OccurredAt: occurredAt,
+	}))
+	// A redelivery from the outbox is dropped.
+	assert.NoError(t, b.PublishBookEvent(context.Background(), bookservices.BookEvent{
+		ID: "evt_2", Type: bookservices.EventBookDeleted, BookID: 1, OccurredAt: occurredAt,
+	}))
+
+	created := receive(t, sub)
+	assert.Equal(t, bookservices.EventBookCreated, created.Type)
+	var envelope map[string]interface{}
+	assert.NoError(t, json.Unmarshal(created.Data, &envelope))
+	assert.Equal(t, "evt_1", envelope["id"])
+	assert.Equal(t, "Dune", envelope["data"].(map[string]interface{})["name"])
+
+	deleted := receive(t, sub)
+	assert.JSONEq(t, `{"id":"evt_2","type":"book.deleted","occurred_at":"2026-10-19T12:00:00Z","data":{"id":1}}`, string(deleted.Data))
+	assert.NotEqual(t, created.ID, deleted.ID)
+	assert.Empty(t, sub.Events())
+}
+
+func TestSubscribeResumes(t *testing.T) {
+	b := NewBroker(Options{History: 3, SubscriberBuffer: 10})
+	var ids []string
+	first := b.Subscribe("")
+	for i := 1; i <= 5; i++ {
+		b.Publish("evt_"+strconv.Itoa(i), "book.updated", []byte("{}"))
+		ids = append(ids, receive(t, first).ID)
+	}
+	first.Close()
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		want        []string
+	}{
+		{name: "New subscriber", lastEventID: ""},
+		{name: "Up to date", lastEventID: ids[4]},
+		{name: "Missed events in history", lastEventID: ids[2], want: ids[3:]},
+		{name: "Oldest resumable", lastEventID: ids[1], want: ids[2:]},
+		{name: "Missed events pruned", lastEventID: ids[0], want: []string{EventReset}},
+		{name: "Another broker", lastEventID: "other-3", want: []string{EventReset}},
+		{name: "Malformed", lastEventID: "nonsense", want: []string{EventReset}},
+		{name: "From the future", lastEventID: b.id(99), want: []string{EventReset}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := b.Subscribe(tt.lastEventID)
+			defer sub.Close()
+
+			var got []string
+			for len(sub.Events()) > 0 {
+				event := <-sub.Events()
+				if event.Type == EventReset {
+					// Resuming from a reset continues with live events.
+					assert.Equal(t, ids[4], event.ID)
+					got = append(got, EventReset)
+					continue
+				}
+				got = append(got, event.ID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSlowConsumerIsDisconnected(t *testing.T) {
+	b := NewBroker(Options{History: 10, SubscriberBuffer: 2})
+	slow := b.Subscribe("")
+	fast := b.Subscribe("")
+	defer fast.Close()
+
+	for i := 1; i <= 3; i++ {
+		b.Publish("evt_"+strconv.Itoa(i), "book.updated", []byte("{}"))
+		receive(t, fast)
+	}
+
+	select {
+	case <-slow.Done():
+	case <-time.After(time.Second):
+		t.Fatal("slow subscriber was not disconnected")
+	}
+	assert.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+	assert.Equal(t, 1, b.Subscribers())
+	slow.Close()
+
+	// It can resume after the last event it received, evt_2.
+	receive(t, slow)
+	last := receive(t, slow)
+	resumed := b.Subscribe(last.ID)
+	defer resumed.Close()
+	assert.Equal(t, "evt_3", receive(t, resumed).sourceID)
+}
+
+func TestClose(t *testing.T) {
+	b := NewBroker(Options{History: 10, SubscriberBuffer: 10})
+	sub := b.Subscribe("")
+
+	b.Close()
+	<-sub.Done()
+	assert.ErrorIs(t, sub.Err(), ErrClosed)
+	assert.Equal(t, 0, b.Subscribers())
+	sub.Close()
+
+	late := b.Subscribe("")
+	<-late.Done()
+	assert.ErrorIs(t, late.Err(), ErrClosed)
+}
+
+func TestResync(t *testing.T) {
+	b := NewBroker(Options{History: 10, SubscriberBuffer: 10})
+	sub := b.Subscribe("")
+	defer sub.Close()
+
+	assert.NoError(t, b.Resync(context.Background()))
+	event := receive(t, sub)
+	assert.Equal(t, EventReset, event.Type)
+
+	// A client resuming from before the reset gets it too.
+	assert.Equal(t, EventReset, receive(t, b.Subscribe(b.id(0))).Type)
+}
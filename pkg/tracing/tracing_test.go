@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	originalProvider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(originalProvider)
+
+	shutdown, err := Setup(context.Background(), ExporterNone, "bookstore")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	shutdown, err = Setup(context.Background(), ExporterStdout, "bookstore")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), "jaeger", "bookstore")
+	assert.Error(t, err)
+}
+
+func TestStartDBSpan(t *testing.T) {
+	originalProvider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(originalProvider)
+
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(NewTracerProvider(sdktrace.NewSimpleSpanProcessor(exporter), "bookstore"))
+
+	_, span := StartDBSpan(context.Background(), "BookServicesPostgres.GetAllBooks", "SELECT 1")
+	RecordError(span, errors.New("select error"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "BookServicesPostgres.GetAllBooks", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
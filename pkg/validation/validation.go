@@ -0,0 +1,176 @@
+// Package validation decodes request bodies strictly and checks them against
+// declarative struct tags:
+//
+//	Name string `json:"name" mod:"trim" validate:"required,max=255"`
+//
+// "mod:trim" strips surrounding whitespace before validation, "validate"
+// takes go-playground/validator rules. Unknown JSON fields are rejected.
+// Slices of structs (bulk payloads) are validated element by element.
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+// Error lists every invalid field of a decoded body.
+type Error struct {
+	Fields []problem.FieldError
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ErrEmptyBody is returned when the request has no body.
+var ErrEmptyBody = errors.New("request body is empty")
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonName)
+	return v
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// DecodeJSON decodes r into v, rejecting unknown fields and trailing data,
+// then normalises and validates v. Field problems are reported as *Error,
+// malformed JSON as a plain error.
+func DecodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("request body must contain a single JSON value")
+	}
+	return Struct(v)
+}
+
+// DecodeJSONBytes is DecodeJSON for an in-memory body.
+func DecodeJSONBytes(body []byte, v interface{}) error {
+	return DecodeJSON(bytes.NewReader(body), v)
+}
+
+func decodeError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return ErrEmptyBody
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name := strings.Trim(field, `"`)
+		return &Error{Fields: []problem.FieldError{{Field: name, Rule: "unknown", Message: fmt.Sprintf("%s is not a known field", name)}}}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &Error{Fields: []problem.FieldError{{Field: typeErr.Field, Rule: "type", Message: fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type.Kind())}}}
+	}
+	return err
+}
+
+// Struct normalises and validates v, a pointer to a struct or to a slice of
+// structs.
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	trim(rv)
+
+	var fields []problem.FieldError
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			fields = append(fields, validateStruct(rv.Index(i).Addr().Interface(), fmt.Sprintf("[%d].", i))...)
+		}
+	} else {
+		fields = validateStruct(rv.Addr().Interface(), "")
+	}
+	if len(fields) > 0 {
+		return &Error{Fields: fields}
+	}
+	return nil
+}
+
+func validateStruct(v interface{}, prefix string) []problem.FieldError {
+	err := validate.Struct(v)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	fields := make([]problem.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		// Namespace is "BookRequest.name"; drop the type name.
+		_, path, _ := strings.Cut(fe.Namespace(), ".")
+		fields = append(fields, problem.FieldError{
+			Field:   prefix + path,
+			Rule:    fe.Tag(),
+			Message: message(prefix+path, fe),
+		})
+	}
+	return fields
+}
+
+func message(field string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters", field, fe.Param())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s characters", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, fe.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
+
+// trim applies `mod:"trim"` to string fields, recursing into nested
+// structs and slices.
+func trim(rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if !rv.IsNil() {
+			trim(rv.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			trim(rv.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if field.Kind() == reflect.String && rv.Type().Field(i).Tag.Get("mod") == "trim" {
+				field.SetString(strings.TrimSpace(field.String()))
+				continue
+			}
+			trim(field)
+		}
+	}
+}
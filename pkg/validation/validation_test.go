@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/problem"
+)
+
+type item struct {
+	Name  string `json:"name" mod:"trim" validate:"required,max=5"`
+	Notes string `json:"notes"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var v item
+	err := DecodeJSON(strings.NewReader(`{"name":"  abc  ","notes":"  kept  "}`), &v)
+	assert.NoError(t, err)
+	assert.Equal(t, item{Name: "abc", Notes: "  kept  "}, v)
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		target         interface{}
+		expectedFields []problem.FieldError
+		expectedErr    error
+	}{
+		{
+			name:   "Required after trimming",
+			body:   `{"name":"   "}`,
+			target: &item{},
+			expectedFields: []problem.FieldError{
+				{Field: "name", Rule: "required", Message: "name is required"},
+			},
+		},
+		{
+			name:   "Too long",
+			body:   `{"name":"abcdef"}`,
+			target: &item{},
+			expectedFields: []problem.FieldError{
+				{Field: "name", Rule: "max", Message: "name must be at most 5 characters"},
+			},
+		},
+		{
+			name:   "Unknown field",
+			body:   `{"name":"abc","colour":"red"}`,
+			target: &item{},
+			expectedFields: []problem.FieldError{
+				{Field: "colour", Rule: "unknown", Message: "colour is not a known field"},
+			},
+		},
+		{
+			name:   "Wrong type",
+			body:   `{"name":5}`,
+			target: &item{},
+			expectedFields: []problem.FieldError{
+				{Field: "name", Rule: "type", Message: "name must be a string"},
+			},
+		},
+		{
+			name:   "Bulk payload",
+			body:   `[{"name":"ok"},{"name":""},{"name":"toolong"}]`,
+			target: &[]item{},
+			expectedFields: []problem.FieldError{
+				{Field: "[1].name", Rule: "required", Message: "[1].name is required"},
+				{Field: "[2].name", Rule: "max", Message: "[2].name must be at most 5 characters"},
+			},
+		},
+		{
+			name:        "Empty body",
+			body:        ``,
+			target:      &item{},
+			expectedErr: ErrEmptyBody,
+		},
+		{
+			name:   "Trailing data",
+			body:   `{"name":"abc"} {}`,
+			target: &item{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeJSON(strings.NewReader(tt.body), tt.target)
+			assert.Error(t, err)
+
+			var validationErr *Error
+			if tt.expectedFields != nil {
+				assert.True(t, errors.As(err, &validationErr))
+				assert.Equal(t, tt.expectedFields, validationErr.Fields)
+				return
+			}
+			assert.False(t, errors.As(err, &validationErr))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
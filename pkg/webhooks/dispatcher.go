@@ -0,0 +1,280 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	webhookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/webhook_services"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/logger"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/metrics"
+	"github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/retry"
+)
+
+const userAgent = "bookstore-webhooks/1"
+
+// Options tune delivery. A delivery is dead-lettered after MaxAttempts
+// failed attempts; between attempts it waits InitialBackoff, doubling up to
+// MaxBackoff. Workers attempt deliveries concurrently; a claim takes at most
+// BatchSize deliveries and never more than there are free workers.
+type Options struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // per attempt
+	PollInterval   time.Duration
+	BatchSize      int
+	Workers        int
+}
+
+// Envelope is the JSON body of every delivery.
+type Envelope struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Dispatcher queues events in the store and delivers them in the
+// background. It is safe to run on several replicas: deliveries are claimed
+// with row locks, so each attempt is made by one dispatcher.
+type Dispatcher struct {
+	Store   webhookservices.WebhookServicesInterface
+	Client  *http.Client
+	Options Options
+
+	now  func() time.Time
+	wake chan struct{}
+}
+
+// NewDispatcher builds a dispatcher. A nil client gets one that does not
+// follow redirects, so a redirect counts as a failed attempt.
+func NewDispatcher(store webhookservices.WebhookServicesInterface, client *http.Client, opts Options) *Dispatcher {
+	if client == nil {
+		client = &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	return &Dispatcher{
+		Store:   store,
+		Client:  client,
+		Options: opts,
+		now:     time.Now,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// PublishBookEvent implements bookservices.EventPublisher.
+func (d *Dispatcher) PublishBookEvent(ctx context.Context, event bookservices.BookEvent) error {
+	var data interface{} = map[string]uint{"id": event.BookID}
+	if event.Book != nil {
+		data = event.Book
+	}
+	payload, err := json.Marshal(Envelope{ID: event.ID, Type: event.Type, OccurredAt: event.OccurredAt, Data: data})
+	if err != nil {
+		return err
+	}
+	return d.Publish(ctx, webhookservices.Event{ID: event.ID, Type: event.Type, Payload: payload, OccurredAt: event.OccurredAt})
+}
+
+// Publish queues event for its subscribers and wakes the delivery loop.
+func (d *Dispatcher) Publish(ctx context.Context, event webhookservices.Event) error {
+	enqueued, err := d.Store.EnqueueEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	if enqueued > 0 {
+		d.Notify()
+	}
+	return nil
+}
+
+// Notify wakes the delivery loop before the next poll, e.g. after a
+// dead-lettered delivery is retried.
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the delivery loop in the background. The returned function
+// stops it and waits for in-flight attempts, or for ctx to end.
+func (d *Dispatcher) Start() func(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(ctx)
+	}()
+	return func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	}
+}
+
+// Run delivers due deliveries every PollInterval, or sooner when notified,
+// until ctx is cancelled, then waits for the attempts in flight. Deliveries
+// are only claimed for free workers, so each one is sent straight away and
+// well within its lease.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	workers := d.workers()
+	slots := make(chan struct{}, workers)
+	ticker := time.NewTicker(d.Options.PollInterval)
+	defer ticker.Stop()
+	for {
+		if free := workers - len(slots); free > 0 {
+			deliveries, err := d.claim(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				logger.FromContext(ctx).Error("webhook dispatch failed", "error", err)
+			}
+			for _, delivery := range deliveries {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(delivery webhookservices.DueDelivery) {
+					defer func() {
+						<-slots
+						wg.Done()
+						// A worker is free; more deliveries may be due.
+						d.Notify()
+					}()
+					// Attempts already started finish even when ctx is
+					// cancelled for shutdown, so they are not recorded as
+					// failures.
+					d.attempt(context.WithoutCancel(ctx), delivery)
+				}(delivery)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// RunOnce claims due deliveries for every worker, attempts them and waits
+// for the attempts, returning how many were made.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx, d.workers())
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery webhookservices.DueDelivery) {
+			defer wg.Done()
+			d.attempt(context.WithoutCancel(ctx), delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) workers() int {
+	return max(d.Options.Workers, 1)
+}
+
+// claim claims up to free due deliveries, and never more than BatchSize.
+func (d *Dispatcher) claim(ctx context.Context, free int) ([]webhookservices.DueDelivery, error) {
+	if d.Options.BatchSize > 0 {
+		free = min(free, d.Options.BatchSize)
+	}
+	// A claim outlives the attempt, so a crashed worker's deliveries are
+	// picked up again once the lease runs out.
+	lease := 2 * d.Options.Timeout
+	return d.Store.ClaimDueDeliveries(ctx, d.now(), lease, free)
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery webhookservices.DueDelivery) {
+	log := logger.FromContext(ctx).With("delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID, "event_id", delivery.EventID, "event", delivery.EventType)
+
+	sentAt := d.now()
+	statusCode, retryAfter, err := d.send(ctx, delivery, sentAt)
+	result := webhookservices.DeliveryAttempt{
+		DeliveryID:  delivery.ID,
+		LeasedUntil: *delivery.NextAttemptAt,
+		Attempts:    delivery.Attempts + 1,
+		AttemptedAt: sentAt,
+	}
+	if statusCode != 0 {
+		result.StatusCode = &statusCode
+	}
+
+	switch {
+	case err == nil:
+		result.Status = webhookservices.DeliverySucceeded
+		result.NextAttemptAt = sentAt
+		log.Info("webhook delivered", "attempt", result.Attempts, "status", statusCode)
+	case result.Attempts >= d.Options.MaxAttempts:
+		result.Status = webhookservices.DeliveryDeadLettered
+		result.NextAttemptAt = sentAt
+		result.Error = err.Error()
+		log.Error("webhook dead-lettered", "attempt", result.Attempts, "status", statusCode, "error", err)
+	default:
+		backoff := retry.Backoff{Initial: d.Options.InitialBackoff, Max: d.Options.MaxBackoff}.Delay(result.Attempts)
+		if retryAfter > backoff {
+			backoff = retryAfter
+		}
+		result.Status = webhookservices.DeliveryPending
+		result.NextAttemptAt = sentAt.Add(backoff)
+		result.Error = err.Error()
+		log.Warn("webhook delivery failed", "attempt", result.Attempts, "status", statusCode, "retry_in", backoff, "error", err)
+	}
+	metrics.WebhookDeliveriesTotal.WithLabelValues(delivery.EventType, result.Status).Inc()
+
+	if err := d.Store.RecordAttempt(ctx, result); err != nil {
+		log.Error("webhook attempt could not be recorded", "error", err)
+	}
+}
+
+// send POSTs the delivery and returns the response status, any Retry-After
+// the receiver asked for, and an error unless it answered 2xx.
+func (d *Dispatcher) send(ctx context.Context, delivery webhookservices.DueDelivery, sentAt time.Time) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.Options.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(sentAt.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, sentAt, delivery.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, 0, nil
+	}
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return resp.StatusCode, retryAfter, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+}
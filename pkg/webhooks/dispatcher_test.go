@@ -0,0 +1,417 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/book_services"
+	webhookservices "github.com/yantology/gin-go-PostgresSQL-Bookstore-Management-Api/pkg/database/webhook_services"
+)
+
+// memoryStore keeps subscriptions and deliveries in memory, mirroring the
+// Postgres queries closely enough to drive the dispatcher.
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions []webhookservices.Subscription
+	deliveries    []webhookservices.DueDelivery
+}
+
+func (s *memoryStore) CreateSubscription(ctx context.Context, subscription webhookservices.SubscriptionRequest) (webhookservices.CreatedSubscriptionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := uint(len(s.subscriptions) + 1)
+	s.subscriptions = append(s.subscriptions, webhookservices.Subscription{ID: id, URL: subscription.URL, Secret: subscription.Secret, Events: subscription.Events})
+	return webhookservices.CreatedSubscriptionResponse{SubscriptionResponse: webhookservices.SubscriptionResponse{ID: id}, Secret: subscription.Secret}, nil
+}
+
+func (s *memoryStore) GetAllSubscriptions(ctx context.Context) ([]webhookservices.SubscriptionResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *memoryStore) GetSubscriptionByID(ctx context.Context, subscriptionID string) (webhookservices.SubscriptionResponse, error) {
+	return webhookservices.SubscriptionResponse{}, errors.New("not implemented")
+}
+
+func (s *memoryStore) UpdateSubscriptionByID(ctx context.Context, subscriptionID string, subscription webhookservices.SubscriptionRequest) (webhookservices.SubscriptionResponse, error) {
+	return webhookservices.SubscriptionResponse{}, errors.New("not implemented")
+}
+
+func (s *memoryStore) DeleteSubscriptionByID(ctx context.Context, subscriptionID string) error {
+	return errors.New("not implemented")
+}
+
+func (s *memoryStore) EnqueueEvent(ctx context.Context, event webhookservices.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enqueued := 0
+	for _, subscription := range s.subscriptions {
+		for _, eventType := range subscription.Events {
+			if eventType != event.Type {
+				continue
+			}
+			s.deliveries = append(s.deliveries, webhookservices.DueDelivery{
+				Delivery: webhookservices.Delivery{
+					ID:             uint(len(s.deliveries) + 1),
+					SubscriptionID: subscription.ID,
+					EventID:        event.ID,
+					EventType:      event.Type,
+					Payload:        event.Payload,
+					Status:         webhookservices.DeliveryPending,
+					NextAttemptAt:  &time.Time{},
+				},
+				URL:    subscription.URL,
+				Secret: subscription.Secret,
+			})
+			enqueued++
+		}
+	}
+	return enqueued, nil
+}
+
+func (s *memoryStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhookservices.DueDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []webhookservices.DueDelivery
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.Status != webhookservices.DeliveryPending || d.NextAttemptAt.After(now) || len(due) == limit {
+			continue
+		}
+		next := now.Add(lease)
+		d.NextAttemptAt = &next
+		due = append(due, *d)
+	}
+	return due, nil
+}
+
+func (s *memoryStore) RecordAttempt(ctx context.Context, attempt webhookservices.DeliveryAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := &s.deliveries[attempt.DeliveryID-1]
+	if d.Status != webhookservices.DeliveryPending || !d.NextAttemptAt.Equal(attempt.LeasedUntil) {
+		return nil
+	}
+	d.Status = attempt.Status
+	d.Attempts = attempt.Attempts
+	d.NextAttemptAt = &attempt.NextAttemptAt
+	d.LastAttemptAt = &attempt.AttemptedAt
+	d.LastStatusCode = attempt.StatusCode
+	if attempt.Error != "" {
+		d.LastError = &attempt.Error
+	}
+	return nil
+}
+
+func (s *memoryStore) GetDeliveries(ctx context.Context, filter webhookservices.DeliveryFilter) ([]webhookservices.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deliveries := make([]webhookservices.Delivery, 0, len(s.deliveries))
+	for _, d := range s.deliveries {
+		deliveries = append(deliveries, d.Delivery)
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) RetryDeliveryByID(ctx context.Context, deliveryID string) (webhookservices.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := strconv.Atoi(deliveryID)
+	d := &s.deliveries[id-1]
+	if d.Status != webhookservices.DeliveryDeadLettered {
+		return webhookservices.Delivery{}, webhookservices.ErrDeliveryNotDeadLettered
+	}
+	d.Status, d.Attempts, d.NextAttemptAt = webhookservices.DeliveryPending, 0, &time.Time{}
+	return d.Delivery, nil
+}
+
+func (s *memoryStore) delivery(id uint) webhookservices.Delivery {
+	deliveries, _ := s.GetDeliveries(context.Background(), webhookservices.DeliveryFilter{})
+	return deliveries[id-1]
+}
+
+// receiver is an httptest endpoint that checks signatures and answers with
+// the queued status codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	received []*http.Request
+	bodies   [][]byte
+	server   *httptest.Server
+}
+
+func newReceiver(t *testing.T, secret string, statuses ...int) *receiver {
+	r := &receiver{secret: secret, statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if err := Verify(r.secret, req.Header, body, 5*time.Minute, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, req)
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		if status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "30")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.received)
+}
+
+func testOptions() Options {
+	return Options{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        time.Second,
+		PollInterval:   time.Hour,
+		BatchSize:      10,
+		Workers:        2,
+	}
+}
+
+// clock is a manually advanced time source for the dispatcher.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestDispatcher(store *memoryStore) (*Dispatcher, *clock) {
+	c := &clock{now: time.Now()}
+	d := NewDispatcher(store, nil, testOptions())
+	d.now = c.Now
+	return d, c
+}
+
+func TestDispatcherDeliversSignedEvents(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	hook := newReceiver(t, "whsec_created_only")
+	other := newReceiver(t, "whsec_deleted_only")
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: hook.server.URL, Secret: hook.secret, Events: []string{bookservices.EventBookCreated}})
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: other.server.URL, Secret: other.secret, Events: []string{bookservices.EventBookDeleted}})
+	dispatcher, _ := newTestDispatcher(store)
+
+	book := bookservices.BookResponse{ID: 7, Name: "Dune"}
+	err := dispatcher.PublishBookEvent(ctx, bookservices.BookEvent{ID: "evt_1", Type: bookservices.EventBookCreated, BookID: 7, Book: &book, OccurredAt: time.Now()})
+	assert.NoError(t, err)
+
+	attempted, err := dispatcher.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, 1, hook.count())
+	assert.Equal(t, 0, other.count())
+
+	req := hook.received[0]
+	assert.Equal(t, "evt_1", req.Header.Get(HeaderID))
+	assert.Equal(t, bookservices.EventBookCreated, req.Header.Get(HeaderEvent))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	var envelope struct {
+		ID   string                    `json:"id"`
+		Type string                    `json:"type"`
+		Data bookservices.BookResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(hook.bodies[0], &envelope))
+	assert.Equal(t, "evt_1", envelope.ID)
+	assert.Equal(t, "Dune", envelope.Data.Name)
+
+	delivery := store.delivery(1)
+	assert.Equal(t, webhookservices.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, *delivery.LastStatusCode)
+
+	// Deleted events only carry the book ID.
+	err = dispatcher.PublishBookEvent(ctx, bookservices.BookEvent{ID: "evt_2", Type: bookservices.EventBookDeleted, BookID: 7, OccurredAt: time.Now()})
+	assert.NoError(t, err)
+	_, err = dispatcher.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, other.count())
+	assert.JSONEq(t, `{"id":7}`, string(mustField(t, other.bodies[0], "data")))
+}
+
+func mustField(t *testing.T, body []byte, field string) json.RawMessage {
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(body, &fields))
+	return fields[field]
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	hook := newReceiver(t, "whsec_flaky", http.StatusInternalServerError, http.StatusBadGateway)
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: hook.server.URL, Secret: hook.secret, Events: []string{bookservices.EventBookUpdated}})
+	dispatcher, clock := newTestDispatcher(store)
+
+	assert.NoError(t, dispatcher.Publish(ctx, webhookservices.Event{ID: "evt_1", Type: bookservices.EventBookUpdated, Payload: []byte(`{}`)}))
+
+	_, err := dispatcher.RunOnce(ctx)
+	assert.NoError(t, err)
+	delivery := store.delivery(1)
+	assert.Equal(t, webhookservices.DeliveryPending, delivery.Status)
+	assert.Equal(t, "receiver responded with status 500", *delivery.LastError)
+	assert.Equal(t, clock.Now().Add(time.Second), *delivery.NextAttemptAt)
+
+	// Not due yet.
+	attempted, _ := dispatcher.RunOnce(ctx)
+	assert.Equal(t, 0, attempted)
+
+	clock.Advance(time.Second)
+	_, _ = dispatcher.RunOnce(ctx)
+	delivery = store.delivery(1)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, clock.Now().Add(2*time.Second), *delivery.NextAttemptAt)
+
+	clock.Advance(2 * time.Second)
+	_, _ = dispatcher.RunOnce(ctx)
+	delivery = store.delivery(1)
+	assert.Equal(t, webhookservices.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, 3, hook.count())
+	// Every attempt carries the same event ID so receivers can de-duplicate.
+	for _, req := range hook.received {
+		assert.Equal(t, "evt_1", req.Header.Get(HeaderID))
+	}
+}
+
+func TestDispatcherDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	hook := newReceiver(t, "whsec_down", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: hook.server.URL, Secret: hook.secret, Events: []string{bookservices.EventBookCreated}})
+	dispatcher, clock := newTestDispatcher(store)
+
+	assert.NoError(t, dispatcher.Publish(ctx, webhookservices.Event{ID: "evt_1", Type: bookservices.EventBookCreated, Payload: []byte(`{}`)}))
+
+	_, _ = dispatcher.RunOnce(ctx)
+	// Retry-After wins over the shorter backoff.
+	assert.Equal(t, clock.Now().Add(30*time.Second), *store.delivery(1).NextAttemptAt)
+	for i := 0; i < 2; i++ {
+		clock.Advance(time.Minute)
+		_, _ = dispatcher.RunOnce(ctx)
+	}
+	delivery := store.delivery(1)
+	assert.Equal(t, webhookservices.DeliveryDeadLettered, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, *delivery.LastStatusCode)
+
+	// Dead letters are never picked up again on their own.
+	clock.Advance(time.Minute)
+	attempted, _ := dispatcher.RunOnce(ctx)
+	assert.Equal(t, 0, attempted)
+
+	// A retried dead letter is attempted again from scratch.
+	_, err := store.RetryDeliveryByID(ctx, "1")
+	assert.NoError(t, err)
+	_, _ = dispatcher.RunOnce(ctx)
+	delivery = store.delivery(1)
+	assert.Equal(t, webhookservices.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestDispatcherClaimsOnlyForFreeWorkers(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	hook := newReceiver(t, "whsec_busy")
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: hook.server.URL, Secret: hook.secret, Events: []string{bookservices.EventBookCreated}})
+	dispatcher, _ := newTestDispatcher(store)
+
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, dispatcher.Publish(ctx, webhookservices.Event{ID: "evt_" + strconv.Itoa(i), Type: bookservices.EventBookCreated, Payload: []byte(`{}`)}))
+	}
+	// Two workers: the rest stay unclaimed rather than waiting out their
+	// lease behind the first two.
+	for _, want := range []int{2, 2, 1, 0} {
+		attempted, err := dispatcher.RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, want, attempted)
+	}
+	assert.Equal(t, 5, hook.count())
+}
+
+func TestDispatcherDropsAttemptsThatLostTheirLease(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	hook := newReceiver(t, "whsec_slow", http.StatusOK, http.StatusInternalServerError)
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: hook.server.URL, Secret: hook.secret, Events: []string{bookservices.EventBookCreated}})
+	dispatcher, clock := newTestDispatcher(store)
+
+	assert.NoError(t, dispatcher.Publish(ctx, webhookservices.Event{ID: "evt_1", Type: bookservices.EventBookCreated, Payload: []byte(`{}`)}))
+	stale, err := store.ClaimDueDeliveries(ctx, clock.Now(), time.Second, 1)
+	assert.NoError(t, err)
+
+	// The lease runs out and the delivery is claimed and delivered again.
+	clock.Advance(2 * time.Second)
+	attempted, _ := dispatcher.RunOnce(ctx)
+	assert.Equal(t, 1, attempted)
+
+	// The first claim's attempt finishes late and must not overwrite it.
+	dispatcher.attempt(ctx, stale[0])
+	delivery := store.delivery(1)
+	assert.Equal(t, webhookservices.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestDispatcherStart(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	hook := newReceiver(t, "whsec_background")
+	_, _ = store.CreateSubscription(ctx, webhookservices.SubscriptionRequest{URL: hook.server.URL, Secret: hook.secret, Events: []string{bookservices.EventBookCreated}})
+	dispatcher := NewDispatcher(store, nil, testOptions())
+
+	stop := dispatcher.Start()
+	// Publishing wakes the loop long before the hourly poll.
+	assert.NoError(t, dispatcher.Publish(ctx, webhookservices.Event{ID: "evt_1", Type: bookservices.EventBookCreated, Payload: []byte(`{}`)}))
+	assert.Eventually(t, func() bool { return hook.count() == 1 }, time.Second, 10*time.Millisecond)
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	assert.NoError(t, stop(shutdownCtx))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":"evt_1"}`)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, Sign("whsec_test", now, body))
+
+	assert.NoError(t, Verify("whsec_test", header, body, time.Minute, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("whsec_other", header, body, time.Minute, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("whsec_test", header, []byte(`{"id":"evt_2"}`), time.Minute, now))
+	assert.Equal(t, ErrStaleTimestamp, Verify("whsec_test", header, body, time.Minute, now.Add(time.Hour)))
+	assert.Equal(t, ErrMissingSignature, Verify("whsec_test", http.Header{}, body, time.Minute, now))
+}
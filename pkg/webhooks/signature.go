@@ -0,0 +1,64 @@
+// Package webhooks delivers book events to subscribed HTTP endpoints.
+//
+// Every delivery is a POST of a JSON envelope signed with the subscription
+// secret. Receivers should check the signature with Verify (or its
+// equivalent) and de-duplicate on the Webhook-Id header, since a delivery
+// may be sent more than once.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	// HeaderSignature holds "v1=<hex HMAC-SHA256 of "<timestamp>.<body>">".
+	HeaderSignature = "Webhook-Signature"
+
+	signatureVersion = "v1="
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook signature headers are missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Sign returns the Webhook-Signature value for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a delivery against body. Deliveries
+// whose timestamp is further than tolerance from now are rejected to limit
+// replays; a zero tolerance skips that check.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	signature, rawTimestamp := header.Get(HeaderSignature), header.Get(HeaderTimestamp)
+	if signature == "" || rawTimestamp == "" {
+		return ErrMissingSignature
+	}
+	unix, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	timestamp := time.Unix(unix, 0)
+	if tolerance > 0 && (now.Sub(timestamp) > tolerance || timestamp.Sub(now) > tolerance) {
+		return ErrStaleTimestamp
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}